@@ -0,0 +1,75 @@
+package commoncache
+
+import (
+	"sync"
+	"time"
+)
+
+// TTL keeps values for a while, at most ttl since they are set.
+// State is kept in memory, so each instance of the app caches on its own.
+type TTL[V any] struct {
+	mu      sync.Mutex
+	ttl     time.Duration
+	entries map[string]entry[V]
+	swept   time.Time
+	now     func() time.Time
+}
+
+type entry[V any] struct {
+	v       V
+	expires time.Time
+}
+
+func New[V any](ttl time.Duration) *TTL[V] {
+	return &TTL[V]{
+		ttl:     ttl,
+		entries: map[string]entry[V]{},
+		now:     time.Now,
+	}
+}
+
+// Get returns value of key, it reports false when key is not set or expired.
+func (c *TTL[V]) Get(key string) (V, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e, ok := c.entries[key]
+	if !ok || !c.now().Before(e.expires) {
+		var zero V
+		return zero, false
+	}
+
+	return e.v, true
+}
+
+// Set keeps v of key until ttl passes or until, whichever is earlier.
+// Zero until means only ttl applies.
+func (c *TTL[V]) Set(key string, v V, until time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	c.sweep(now)
+
+	expires := now.Add(c.ttl)
+	if !until.IsZero() && until.Before(expires) {
+		expires = until
+	}
+
+	c.entries[key] = entry[V]{v: v, expires: expires}
+}
+
+// sweep drops expired entries once per ttl, so memory is bounded by keys set within one ttl.
+func (c *TTL[V]) sweep(now time.Time) {
+	if now.Sub(c.swept) < c.ttl {
+		return
+	}
+
+	for k, e := range c.entries {
+		if !now.Before(e.expires) {
+			delete(c.entries, k)
+		}
+	}
+
+	c.swept = now
+}
@@ -0,0 +1,91 @@
+package commoncache_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	commoncache "waizlytest/common/cache"
+)
+
+func TestTTL(t *testing.T) {
+	scenarios := []struct {
+		name  string
+		ttl   time.Duration
+		until func() time.Time
+		wait  time.Duration
+
+		expected bool
+	}{
+		{name: "[OK] Within ttl", ttl: time.Hour, until: func() time.Time { return time.Time{} }, expected: true},
+		{name: "[OK] Within ttl & until", ttl: time.Hour, until: func() time.Time { return time.Now().Add(time.Hour) }, expected: true},
+		{name: "[OK] Until later than ttl", ttl: 20 * time.Millisecond, until: func() time.Time { return time.Now().Add(time.Hour) }, wait: 60 * time.Millisecond},
+		{name: "[Failed] Ttl passed", ttl: 20 * time.Millisecond, until: func() time.Time { return time.Time{} }, wait: 60 * time.Millisecond},
+		{name: "[Failed] Until passed before ttl", ttl: time.Hour, until: func() time.Time { return time.Now().Add(20 * time.Millisecond) }, wait: 60 * time.Millisecond},
+		{name: "[Failed] Until already passed", ttl: time.Hour, until: func() time.Time { return time.Now().Add(-time.Second) }},
+	}
+
+	for _, scn := range scenarios {
+		t.Run(scn.name, func(t *testing.T) {
+			c := commoncache.New[string](scn.ttl)
+			c.Set("key", "value", scn.until())
+
+			time.Sleep(scn.wait)
+
+			got, ok := c.Get("key")
+			if ok != scn.expected {
+				t.Fatalf("found mismatch: want %v, got %v", scn.expected, ok)
+			}
+
+			if ok && got != "value" {
+				t.Errorf("value mismatch: want value, got %q", got)
+			}
+
+			if _, ok := c.Get("other"); ok {
+				t.Errorf("expected other key not found")
+			}
+		})
+	}
+}
+
+func TestTTL_Sweep(t *testing.T) {
+	c := commoncache.New[int](20 * time.Millisecond)
+	c.Set("old", 1, time.Time{})
+
+	time.Sleep(60 * time.Millisecond)
+
+	// setting after ttl sweeps the expired entry without touching the fresh one
+	c.Set("new", 2, time.Time{})
+
+	if _, ok := c.Get("old"); ok {
+		t.Errorf("expected old key expired")
+	}
+
+	if got, ok := c.Get("new"); !ok || got != 2 {
+		t.Errorf("expected new key kept, got %v, %v", got, ok)
+	}
+}
+
+func TestTTL_Concurrent(t *testing.T) {
+	c := commoncache.New[int](time.Minute)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			for j := 0; j < 100; j++ {
+				key := fmt.Sprintf("%d-%d", i, j)
+				c.Set(key, j, time.Time{})
+
+				if got, ok := c.Get(key); !ok || got != j {
+					t.Errorf("expected %s to be %d, got %v, %v", key, j, got, ok)
+				}
+			}
+		}(i)
+	}
+
+	wg.Wait()
+}
@@ -4,5 +4,10 @@ package contextkey
 type contextKey string
 
 const (
-	UserIDContextKey contextKey = "UserID"
+	PrimaryDBContextKey   contextKey = "PrimaryDB"
+	LogFieldsContextKey   contextKey = "LogFields"
+	ErrorFormatContextKey contextKey = "ErrorFormat"
+	LanguageContextKey    contextKey = "Language"
+	// PrincipalContextKey is accessed by WithPrincipal & PrincipalFrom
+	PrincipalContextKey contextKey = "Principal"
 )
@@ -0,0 +1,142 @@
+package commondb
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	commonretry "waizlytest/common/retry"
+)
+
+// State of the DB connection
+type State int32
+
+const (
+	StateConnecting State = iota
+	StateReady
+	StateReconnecting
+	StateUnavailable
+)
+
+func (s State) String() string {
+	switch s {
+	case StateConnecting:
+		return "connecting"
+	case StateReady:
+		return "ready"
+	case StateReconnecting:
+		return "reconnecting"
+	default:
+		return "unavailable"
+	}
+}
+
+const (
+	pingTimeout          = 5 * time.Second
+	defaultWatchInterval = 10 * time.Second
+)
+
+// Monitor keeps track whether DB can be reached.
+// It retries with backoff on startup (Connect) and whenever connection is lost (Watch).
+type Monitor struct {
+	ping    func(ctx context.Context) error
+	backoff commonretry.Backoff
+
+	state atomic.Int32
+}
+
+func NewMonitor(ping func(ctx context.Context) error, backoff commonretry.Backoff) *Monitor {
+	m := &Monitor{
+		ping:    ping,
+		backoff: backoff,
+	}
+
+	m.state.Store(int32(StateConnecting))
+	return m
+}
+
+func (m *Monitor) State() State {
+	return State(m.state.Load())
+}
+
+func (m *Monitor) Ready() bool {
+	return m.State() == StateReady
+}
+
+func (m *Monitor) pingOnce(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, pingTimeout)
+	defer cancel()
+
+	return m.ping(ctx)
+}
+
+func (m *Monitor) logRetry(attempt int, delay time.Duration, err error) {
+	slog.Warn("DB connection attempt failed",
+		slog.String("state", m.State().String()),
+		slog.Int("attempt", attempt),
+		slog.Duration("retry_in", delay.Round(time.Millisecond)),
+		slog.Any("error", err),
+	)
+}
+
+func (m *Monitor) setState(s State) {
+	prev := State(m.state.Swap(int32(s)))
+	if prev != s {
+		slog.Info("DB state changed", slog.String("from", prev.String()), slog.String("to", s.String()))
+	}
+}
+
+// Connect blocks until DB answers the ping or the backoff attempts are exhausted.
+func (m *Monitor) Connect(ctx context.Context) error {
+	m.setState(StateConnecting)
+
+	err := commonretry.Do(ctx, m.backoff, m.pingOnce, m.logRetry)
+	if err != nil {
+		m.setState(StateUnavailable)
+		return err
+	}
+
+	m.setState(StateReady)
+	return nil
+}
+
+// Watch pings DB on every interval, once it fails the state becomes reconnecting
+// and it keeps retrying (without attempts bound) until DB is back. It blocks until ctx is done.
+func (m *Monitor) Watch(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = defaultWatchInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	backoff := m.backoff
+	backoff.MaxAttempts = 0
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		err := m.pingOnce(ctx)
+		if err == nil {
+			m.setState(StateReady)
+			continue
+		}
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		slog.Warn("DB connection lost", slog.Any("error", err))
+		m.setState(StateReconnecting)
+
+		err = commonretry.Do(ctx, backoff, m.pingOnce, m.logRetry)
+		if err == nil {
+			m.setState(StateReady)
+		}
+	}
+}
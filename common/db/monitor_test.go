@@ -0,0 +1,221 @@
+package commondb_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	commondb "waizlytest/common/db"
+	commonretry "waizlytest/common/retry"
+)
+
+// fakeDB fails the next `fails` pings and records the monitor state seen by every ping
+type fakeDB struct {
+	mu     sync.Mutex
+	fails  int
+	states []commondb.State
+
+	monitor *commondb.Monitor
+}
+
+func (db *fakeDB) ping(ctx context.Context) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	db.states = append(db.states, db.monitor.State())
+	if db.fails > 0 {
+		db.fails--
+		return errors.New("connection refused")
+	}
+
+	return nil
+}
+
+func (db *fakeDB) fail(n int) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	db.fails = n
+}
+
+func (db *fakeDB) seen() []commondb.State {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	return append([]commondb.State(nil), db.states...)
+}
+
+func newMonitor(fails, maxAttempts int) (*commondb.Monitor, *fakeDB) {
+	db := &fakeDB{fails: fails}
+	db.monitor = commondb.NewMonitor(db.ping, commonretry.Backoff{
+		Initial:     time.Millisecond,
+		Max:         2 * time.Millisecond,
+		MaxAttempts: maxAttempts,
+	})
+
+	return db.monitor, db
+}
+
+func TestMonitor_Connect(t *testing.T) {
+	cancelled, cancel := context.WithCancel(context.TODO())
+	cancel()
+
+	scenarios := []struct {
+		name        string
+		ctx         context.Context
+		fails       int
+		maxAttempts int
+		// pings is the state seen by every ping, so its length is number of attempts
+		pings    []commondb.State
+		state    commondb.State
+		expected bool
+	}{
+		{
+			name:        "[OK] First attempt",
+			ctx:         context.TODO(),
+			maxAttempts: 3,
+			pings:       []commondb.State{commondb.StateConnecting},
+			state:       commondb.StateReady,
+			expected:    true,
+		},
+		{
+			name:        "[OK] Retried",
+			ctx:         context.TODO(),
+			fails:       2,
+			maxAttempts: 3,
+			pings:       []commondb.State{commondb.StateConnecting, commondb.StateConnecting, commondb.StateConnecting},
+			state:       commondb.StateReady,
+			expected:    true,
+		},
+		{
+			name:        "[Failed] Attempts exhausted",
+			ctx:         context.TODO(),
+			fails:       10,
+			maxAttempts: 3,
+			pings:       []commondb.State{commondb.StateConnecting, commondb.StateConnecting, commondb.StateConnecting},
+			state:       commondb.StateUnavailable,
+		},
+		{
+			name:  "[Failed] Context done stops unbounded retries",
+			ctx:   cancelled,
+			fails: 10,
+			pings: []commondb.State{commondb.StateConnecting},
+			state: commondb.StateUnavailable,
+		},
+	}
+
+	for _, scn := range scenarios {
+		t.Run(scn.name, func(t *testing.T) {
+			m, db := newMonitor(scn.fails, scn.maxAttempts)
+
+			err := m.Connect(scn.ctx)
+			if (err == nil) != scn.expected {
+				t.Errorf("expected connected %v, got error %v", scn.expected, err)
+			}
+
+			if m.State() != scn.state || m.Ready() != (scn.state == commondb.StateReady) {
+				t.Errorf("expected state %s, got %s", scn.state, m.State())
+			}
+
+			diff := cmp.Diff(scn.pings, db.seen())
+			if diff != "" {
+				t.Errorf("pings mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+// eventually polls cond, so the watch has time to tick
+func eventually(t *testing.T, cond func() bool, msg string) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal(msg)
+		}
+
+		time.Sleep(time.Millisecond)
+	}
+}
+
+func TestMonitor_Watch(t *testing.T) {
+	// watch retries beyond MaxAttempts of Connect until DB is back
+	m, db := newMonitor(0, 2)
+
+	err := m.Connect(context.TODO())
+	if err != nil {
+		t.Fatalf("failed connect: %v", err)
+	}
+
+	db.fail(3)
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+
+	go m.Watch(ctx, 5*time.Millisecond)
+
+	eventually(t, func() bool { return len(db.seen()) >= 6 }, "expected watch kept pinging")
+
+	expected := []commondb.State{
+		commondb.StateConnecting,
+		commondb.StateReady,
+		commondb.StateReconnecting,
+		commondb.StateReconnecting,
+		commondb.StateReconnecting,
+		commondb.StateReady,
+	}
+
+	diff := cmp.Diff(expected, db.seen()[:len(expected)])
+	if diff != "" {
+		t.Errorf("pings mismatch (-want +got):\n%s", diff)
+	}
+
+	if !m.Ready() {
+		t.Errorf("expected ready once DB is back, got %s", m.State())
+	}
+}
+
+func TestMonitor_Watch_Stop(t *testing.T) {
+	scenarios := []struct {
+		name  string
+		fails int
+		state commondb.State
+	}{
+		{name: "[OK] Stops while ready", state: commondb.StateReady},
+		{name: "[OK] Stops while reconnecting", fails: 1 << 30, state: commondb.StateReconnecting},
+	}
+
+	for _, scn := range scenarios {
+		t.Run(scn.name, func(t *testing.T) {
+			m, db := newMonitor(0, 1)
+
+			err := m.Connect(context.TODO())
+			if err != nil {
+				t.Fatalf("failed connect: %v", err)
+			}
+
+			db.fail(scn.fails)
+
+			ctx, cancel := context.WithCancel(context.TODO())
+			done := make(chan struct{})
+			go func() {
+				m.Watch(ctx, time.Millisecond)
+				close(done)
+			}()
+
+			eventually(t, func() bool { return len(db.seen()) >= 3 && m.State() == scn.state }, "expected watch running")
+
+			cancel()
+			select {
+			case <-done:
+			case <-time.After(time.Second):
+				t.Fatal("expected watch stopped once ctx is done")
+			}
+		})
+	}
+}
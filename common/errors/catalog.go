@@ -0,0 +1,145 @@
+package commonerr
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Definition describes an error the app may return.
+// Every package declares its own definitions at init through Define,
+// so the whole catalog can be documented for clients, see Catalog.
+type Definition struct {
+	// Domain groups definitions on the docs, e.g `auth`
+	Domain string `json:"domain"`
+	Type   Type   `json:"type"`
+	Code   Code   `json:"code"`
+	// Key of the translation bundles, Message is used when it is empty
+	Key         string `json:"key,omitempty"`
+	Message     string `json:"message"`
+	Description string `json:"description,omitempty"`
+
+	// variant shares Type & Code of another definition, so it is told apart by Key, see Variant
+	variant bool
+}
+
+// New creates fresh Error of the definition, safe to add fields to.
+func (d *Definition) New() *Error {
+	e := New(d.Type, d.Code, d.Message)
+	e.Key = d.Key
+	return e
+}
+
+// Is reports whether err is created from the definition.
+func (d *Definition) Is(err error) bool {
+	e, ok := err.(*Error)
+	return ok && e.Type == d.Type && e.Code == d.Code && (!d.variant || e.Key == d.Key)
+}
+
+type definitionKey struct {
+	t Type
+	c Code
+}
+
+var catalog = struct {
+	mu       sync.Mutex
+	defs     map[definitionKey]*Definition
+	variants []*Definition
+}{
+	defs: map[definitionKey]*Definition{},
+}
+
+// Define registers d to the catalog, it panics when Type & Code pair is already taken
+// so clashing codes are caught on startup.
+func Define(d Definition) *Definition {
+	catalog.mu.Lock()
+	defer catalog.mu.Unlock()
+
+	k := definitionKey{t: d.Type, c: d.Code}
+	if existing, ok := catalog.defs[k]; ok {
+		panic(fmt.Sprintf("commonerr: %s/%d of domain %s is already defined by domain %s", d.Type, d.Code, d.Domain, existing.Domain))
+	}
+
+	catalog.defs[k] = &d
+	return &d
+}
+
+// Variant registers another message sharing Type & Code of d, for errors which clients
+// only tell apart by message since before the catalog existed. New errors take their own code through Define.
+func (d *Definition) Variant(v Definition) *Definition {
+	catalog.mu.Lock()
+	defer catalog.mu.Unlock()
+
+	v.Type, v.Code, v.variant = d.Type, d.Code, true
+	catalog.variants = append(catalog.variants, &v)
+	return &v
+}
+
+// Catalog returns every registered definition & variant, ordered by domain, type, code and key.
+func Catalog() []Definition {
+	catalog.mu.Lock()
+	defer catalog.mu.Unlock()
+
+	defs := make([]Definition, 0, len(catalog.defs)+len(catalog.variants))
+	for _, d := range catalog.defs {
+		defs = append(defs, *d)
+	}
+
+	for _, d := range catalog.variants {
+		defs = append(defs, *d)
+	}
+
+	sort.Slice(defs, func(i, j int) bool {
+		if defs[i].Domain != defs[j].Domain {
+			return defs[i].Domain < defs[j].Domain
+		}
+
+		if defs[i].Type != defs[j].Type {
+			return defs[i].Type < defs[j].Type
+		}
+
+		if defs[i].Code != defs[j].Code {
+			return defs[i].Code < defs[j].Code
+		}
+
+		return defs[i].Key < defs[j].Key
+	})
+
+	return defs
+}
+
+// WriteJSON writes defs as JSON array.
+func WriteJSON(w io.Writer, defs []Definition) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(defs)
+}
+
+// WriteMarkdown writes defs as Markdown, one table per domain.
+func WriteMarkdown(w io.Writer, defs []Definition) error {
+	var b strings.Builder
+
+	b.WriteString("# Error catalog\n")
+
+	domain := ""
+	for i, d := range defs {
+		if i == 0 || d.Domain != domain {
+			domain = d.Domain
+			fmt.Fprintf(&b, "\n## %s\n\n", domain)
+			b.WriteString("| Type | Code | Message | Description |\n")
+			b.WriteString("|------|------|---------|-------------|\n")
+		}
+
+		fmt.Fprintf(&b, "| %s | %d | %s | %s |\n", d.Type, d.Code, escape(d.Message), escape(d.Description))
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func escape(s string) string {
+	return strings.ReplaceAll(s, "|", `\|`)
+}
@@ -0,0 +1,82 @@
+package commonerr_test
+
+import (
+	"errors"
+	"testing"
+
+	commonerr "waizlytest/common/errors"
+)
+
+func TestDefinition_Is(t *testing.T) {
+	scenarios := []struct {
+		name     string
+		def      *commonerr.Definition
+		err      error
+		expected bool
+	}{
+		{name: "[OK] Created from definition", def: commonerr.DefBadRequest, err: commonerr.DefBadRequest.New(), expected: true},
+		{name: "[OK] Custom message", def: commonerr.DefBadRequest, err: commonerr.BadRequest("test error"), expected: true},
+		{name: "[OK] Variant is of its definition", def: commonerr.DefBadRequest, err: commonerr.DefEmptyRequest.New(), expected: true},
+		{name: "[OK] Created from variant", def: commonerr.DefEmptyRequest, err: commonerr.DefEmptyRequest.New(), expected: true},
+		{name: "[Failed] Variant of other message", def: commonerr.DefEmptyRequest, err: commonerr.BadRequest("")},
+		{name: "[Failed] Other code", def: commonerr.DefMalformedRequest, err: commonerr.BadRequest("")},
+		{name: "[Failed] Other type", def: commonerr.DefConflicted, err: commonerr.BadRequest("")},
+		{name: "[Failed] Not commonerr", def: commonerr.DefBadRequest, err: errors.New("Bad request")},
+	}
+
+	for _, scn := range scenarios {
+		t.Run(scn.name, func(t *testing.T) {
+			if got := scn.def.Is(scn.err); got != scn.expected {
+				t.Errorf("expected %v, got %v", scn.expected, got)
+			}
+		})
+	}
+}
+
+func TestDefine_Duplicate(t *testing.T) {
+	before := len(commonerr.Catalog())
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected panic on duplicate type & code")
+		}
+
+		if after := len(commonerr.Catalog()); after != before {
+			t.Errorf("expected duplicate not registered, catalog grew from %d to %d", before, after)
+		}
+	}()
+
+	commonerr.Define(commonerr.Definition{
+		Domain: "test",
+		Type:   commonerr.TypeBadRequestError,
+		Code:   commonerr.Code101,
+	})
+}
+
+func TestCatalog(t *testing.T) {
+	defs := commonerr.Catalog()
+
+	found := false
+	for i, d := range defs {
+		if d.Key == commonerr.DefEmptyRequest.Key {
+			found = true
+			if d.Type != commonerr.TypeBadRequestError || d.Code != commonerr.Code101 {
+				t.Errorf("expected variant to keep %s/%d, got %s/%d", commonerr.TypeBadRequestError, commonerr.Code101, d.Type, d.Code)
+			}
+		}
+
+		if i == 0 {
+			continue
+		}
+
+		prev := defs[i-1]
+		if prev.Domain > d.Domain || (prev.Domain == d.Domain && prev.Type > d.Type) ||
+			(prev.Domain == d.Domain && prev.Type == d.Type && prev.Code > d.Code) {
+			t.Errorf("expected catalog ordered, got %v before %v", prev, d)
+		}
+	}
+
+	if !found {
+		t.Errorf("expected variant listed in catalog")
+	}
+}
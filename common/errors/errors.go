@@ -24,10 +24,25 @@ type Error struct {
 	Code    Code   `json:"code"`
 	Message string `json:"message"`
 	Fields  Fields `json:"fields"`
+
+	// TraceID is filled by the encoder, so the client can report which request went wrong
+	TraceID string `json:"trace_id,omitempty"`
+
+	// Key & Args let the encoder translate Message, which stays as the English fallback.
+	Key  string `json:"-"`
+	Args []any  `json:"-"`
+	// FieldKeys translate Fields the same way
+	FieldKeys map[string]Translatable `json:"-"`
 }
 
 type Fields map[string]string
 
+// Translatable is message key of the translation bundles with its `fmt` arguments
+type Translatable struct {
+	Key  string
+	Args []any
+}
+
 func (e *Error) AddField(k, v string) {
 	if len(e.Fields) == 0 {
 		e.Fields = map[string]string{}
@@ -36,6 +51,17 @@ func (e *Error) AddField(k, v string) {
 	e.Fields[k] = v
 }
 
+// AddTranslatableField adds field of English value v, translated by the encoder from key & args.
+func (e *Error) AddTranslatableField(k, v, key string, args ...any) {
+	e.AddField(k, v)
+
+	if len(e.FieldKeys) == 0 {
+		e.FieldKeys = map[string]Translatable{}
+	}
+
+	e.FieldKeys[k] = Translatable{Key: key, Args: args}
+}
+
 // Error satisfying interface Error, just return the message
 func (e *Error) Error() string {
 	return e.Message
@@ -54,6 +80,8 @@ func New(t Type, c Code, msg string) *Error {
 const (
 	// 1xx
 	Code101 Code = 101
+	Code104 Code = 104
+	Code106 Code = 106
 
 	Code119 Code = 119
 
@@ -80,34 +108,130 @@ const (
 	TypeConflictedError       Type = "ConflictedError"
 )
 
+// Reserved definitions
+var (
+	DefAuthentication = Define(Definition{
+		Domain:      "common",
+		Type:        TypeAuthenticationError,
+		Code:        Code805,
+		Key:         "common.authentication",
+		Message:     "Authentication failed",
+		Description: "Credential or access token is missing or not valid.",
+	})
+	DefNotFound = Define(Definition{
+		Domain:      "common",
+		Type:        TypeNotFoundError,
+		Code:        Code822,
+		Key:         "common.not_found",
+		Message:     "Resource not found",
+		Description: "Requested resource does not exist.",
+	})
+	DefForbidden = Define(Definition{
+		Domain:      "common",
+		Type:        TypeForbiddenError,
+		Code:        Code825,
+		Key:         "common.forbidden",
+		Message:     "Permission denied",
+		Description: "Caller is not allowed to access the resource.",
+	})
+	DefInternalServer = Define(Definition{
+		Domain:      "common",
+		Type:        TypeInternalServerError,
+		Code:        Code901,
+		Key:         "common.internal_server",
+		Message:     "Oops, something went wrong",
+		Description: "Unexpected error, report the trace_id of the response.",
+	})
+	DefMaintenance = Define(Definition{
+		Domain:      "common",
+		Type:        TypeMaintenanceError,
+		Code:        Code910,
+		Key:         "common.maintenance",
+		Message:     "Sorry, app is under maintenance",
+		Description: "Service is temporarily unavailable.",
+	})
+	DefApplicationLimit = Define(Definition{
+		Domain:      "common",
+		Type:        TypeApplicationLimitError,
+		Code:        Code831,
+		Key:         "common.application_limit",
+		Message:     "Application limit is exceeded",
+		Description: "Too many requests, retry later.",
+	})
+	DefBadRequest = Define(Definition{
+		Domain:      "common",
+		Type:        TypeBadRequestError,
+		Code:        Code101,
+		Key:         "common.bad_request",
+		Message:     "Bad request",
+		Description: "Request is malformed, message tells what is wrong.",
+	})
+	DefEmptyRequest = DefBadRequest.Variant(Definition{
+		Domain:      "common",
+		Key:         "common.empty_request",
+		Message:     "request can not be empty",
+		Description: "Request body is required.",
+	})
+	DefMalformedRequest = Define(Definition{
+		Domain:      "common",
+		Type:        TypeBadRequestError,
+		Code:        Code104,
+		Key:         "common.malformed_request",
+		Message:     "request is malformed",
+		Description: "Request body is not valid JSON, or a field has wrong type, see fields.",
+	})
+	DefInvalidRequest = Define(Definition{
+		Domain:      "common",
+		Type:        TypeBadRequestError,
+		Code:        Code106,
+		Key:         "common.invalid_request",
+		Message:     "request is not valid",
+		Description: "Request failed validation, fields tell every violation.",
+	})
+	DefConflicted = Define(Definition{
+		Domain:      "common",
+		Type:        TypeConflictedError,
+		Code:        Code119,
+		Key:         "common.conflicted",
+		Message:     "Conflicted request",
+		Description: "Request conflicts with current state of the resource, fields tell which one.",
+	})
+)
+
 // Reserved errors
-// any other business should be added on its own error dictionary
+// any other business should be added on its own error definitions, see Define
 var (
-	ErrorAuthentication   = New(TypeAuthenticationError, Code805, "Authentication failed")
-	ErrorNotFound         = New(TypeNotFoundError, Code822, "Resource not found")
-	ErrorForbidden        = New(TypeForbiddenError, Code825, "Permission denied")
-	ErrorInternalServer   = New(TypeInternalServerError, Code901, "Oops, something went wrong")
-	ErrorMaintenance      = New(TypeMaintenanceError, Code910, "Sorry, app is under maintenance")
-	ErrorApplicationLimit = New(TypeApplicationLimitError, Code831, "Application limit is exceeded")
-	ErrorBadRequest       = New(TypeBadRequestError, Code101, "Bad request")
+	ErrorAuthentication   = DefAuthentication.New()
+	ErrorNotFound         = DefNotFound.New()
+	ErrorForbidden        = DefForbidden.New()
+	ErrorInternalServer   = DefInternalServer.New()
+	ErrorMaintenance      = DefMaintenance.New()
+	ErrorApplicationLimit = DefApplicationLimit.New()
+	ErrorBadRequest       = DefBadRequest.New()
 )
 
 func NotFound() *Error {
-	return New(TypeNotFoundError, Code822, "Resource not found")
+	return DefNotFound.New()
 }
 
 func BadRequest(msg string) *Error {
-	if msg == "" {
-		msg = "Bad request"
+	e := DefBadRequest.New()
+	if msg != "" {
+		// custom message has no translation
+		e.Message = msg
+		e.Key = ""
 	}
 
-	return New(TypeBadRequestError, Code101, msg)
+	return e
 }
 
 func Conflicted(msg string) *Error {
-	if msg == "" {
-		msg = "Conflicted request"
+	e := DefConflicted.New()
+	if msg != "" {
+		// custom message has no translation
+		e.Message = msg
+		e.Key = ""
 	}
 
-	return New(TypeConflictedError, Code119, msg)
+	return e
 }
@@ -0,0 +1,32 @@
+package commonhealth
+
+import (
+	"net/http"
+
+	commonhttpenc "waizlytest/common/http/encoder"
+	commonhttpresp "waizlytest/common/http/response"
+)
+
+// LivenessHandler tells the process is up, it never touches dependencies.
+func LivenessHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		report := Report{Status: StatusOK, Checks: map[string]CheckResult{}}
+		commonhttpenc.JSONResponseEncoder(r.Context(), w, http.StatusOK, commonhttpresp.NewResponse(report, nil))
+	}
+}
+
+// ReadinessHandler runs the registered checks, any failing check results HTTP 503.
+func (reg *Registry) ReadinessHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		report := reg.Check(ctx)
+
+		httpStatus := http.StatusOK
+		if report.Status != StatusOK {
+			httpStatus = http.StatusServiceUnavailable
+		}
+
+		commonhttpenc.JSONResponseEncoder(ctx, w, httpStatus, commonhttpresp.NewResponse(report, nil))
+	}
+}
@@ -0,0 +1,129 @@
+package commonhealth
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Checker reports whether a dependency is usable, nil error means healthy.
+type Checker func(ctx context.Context) error
+
+const (
+	StatusOK   = "ok"
+	StatusFail = "fail"
+)
+
+// Registry holds the readiness checks, any subsystem may register its own.
+type Registry struct {
+	timeout time.Duration
+
+	mu     sync.RWMutex
+	checks []check
+}
+
+type check struct {
+	name string
+	fn   Checker
+}
+
+// Report is result of running every check of the Registry
+type Report struct {
+	Status string                 `json:"status"`
+	Checks map[string]CheckResult `json:"checks"`
+}
+
+// CheckResult is served to anonymous callers, so the error of a failed check is only logged,
+// its text may carry DB host, user or SQL.
+type CheckResult struct {
+	Status    string  `json:"status"`
+	LatencyMs float64 `json:"latency_ms"`
+}
+
+// NewRegistry creates Registry, timeout bounds each check.
+func NewRegistry(timeout time.Duration) *Registry {
+	return &Registry{
+		timeout: timeout,
+	}
+}
+
+func (r *Registry) Register(name string, fn Checker) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.checks = append(r.checks, check{name: name, fn: fn})
+}
+
+// Check runs every registered check concurrently.
+func (r *Registry) Check(ctx context.Context) Report {
+	r.mu.RLock()
+	checks := make([]check, len(r.checks))
+	copy(checks, r.checks)
+	r.mu.RUnlock()
+
+	report := Report{
+		Status: StatusOK,
+		Checks: make(map[string]CheckResult, len(checks)),
+	}
+
+	var (
+		wg sync.WaitGroup
+		mu sync.Mutex
+	)
+
+	for _, c := range checks {
+		wg.Add(1)
+		go func(c check) {
+			defer wg.Done()
+
+			res := r.run(ctx, c)
+
+			mu.Lock()
+			defer mu.Unlock()
+
+			report.Checks[c.name] = res
+			if res.Status != StatusOK {
+				report.Status = StatusFail
+			}
+		}(c)
+	}
+
+	wg.Wait()
+	return report
+}
+
+func (r *Registry) run(ctx context.Context, c check) CheckResult {
+	if r.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, r.timeout)
+		defer cancel()
+	}
+
+	start := time.Now()
+
+	// a check ignoring ctx must not hold the probe beyond timeout
+	done := make(chan error, 1)
+	go func() {
+		done <- c.fn(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-done:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	res := CheckResult{
+		Status:    StatusOK,
+		LatencyMs: float64(time.Since(start).Microseconds()) / 1000,
+	}
+
+	if err != nil {
+		res.Status = StatusFail
+		slog.WarnContext(ctx, "readiness check failed", slog.String("check", c.name), slog.Any("error", err))
+	}
+
+	return res
+}
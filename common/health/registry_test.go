@@ -0,0 +1,179 @@
+package commonhealth_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	commonhealth "waizlytest/common/health"
+)
+
+func ok(ctx context.Context) error {
+	return nil
+}
+
+func TestRegistry_Check(t *testing.T) {
+	scenarios := []struct {
+		name     string
+		checks   map[string]commonhealth.Checker
+		expected map[string]string
+		status   string
+	}{
+		{
+			name:     "[OK] No checks",
+			checks:   map[string]commonhealth.Checker{},
+			expected: map[string]string{},
+			status:   commonhealth.StatusOK,
+		},
+		{
+			name:     "[OK] Every check passes",
+			checks:   map[string]commonhealth.Checker{"db": ok, "signing_key": ok},
+			expected: map[string]string{"db": commonhealth.StatusOK, "signing_key": commonhealth.StatusOK},
+			status:   commonhealth.StatusOK,
+		},
+		{
+			name: "[Failed] One check fails",
+			checks: map[string]commonhealth.Checker{
+				"db":        ok,
+				"migration": func(ctx context.Context) error { return errors.New("pending migrations") },
+			},
+			expected: map[string]string{"db": commonhealth.StatusOK, "migration": commonhealth.StatusFail},
+			status:   commonhealth.StatusFail,
+		},
+		{
+			name: "[Failed] Check respecting timeout",
+			checks: map[string]commonhealth.Checker{
+				"db": func(ctx context.Context) error {
+					<-ctx.Done()
+					return ctx.Err()
+				},
+			},
+			expected: map[string]string{"db": commonhealth.StatusFail},
+			status:   commonhealth.StatusFail,
+		},
+		{
+			name: "[Failed] Check ignoring timeout",
+			checks: map[string]commonhealth.Checker{
+				"db": func(ctx context.Context) error {
+					time.Sleep(time.Second)
+					return nil
+				},
+			},
+			expected: map[string]string{"db": commonhealth.StatusFail},
+			status:   commonhealth.StatusFail,
+		},
+	}
+
+	for _, scn := range scenarios {
+		t.Run(scn.name, func(t *testing.T) {
+			reg := commonhealth.NewRegistry(50 * time.Millisecond)
+			for name, fn := range scn.checks {
+				reg.Register(name, fn)
+			}
+
+			start := time.Now()
+			report := reg.Check(context.TODO())
+			if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+				t.Errorf("expected check bounded by timeout, took %v", elapsed)
+			}
+
+			if report.Status != scn.status {
+				t.Errorf("status mismatch: want %s, got %s", scn.status, report.Status)
+			}
+
+			got := map[string]string{}
+			for name, res := range report.Checks {
+				got[name] = res.Status
+			}
+
+			diff := cmp.Diff(scn.expected, got)
+			if diff != "" {
+				t.Errorf("checks mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestRegistry_Check_Concurrent(t *testing.T) {
+	reg := commonhealth.NewRegistry(time.Second)
+
+	// every check waits for all of them to start, which only passes when they run concurrently
+	const n = 5
+	var started atomic.Int32
+	for i := 0; i < n; i++ {
+		reg.Register(string(rune('a'+i)), func(ctx context.Context) error {
+			started.Add(1)
+			for started.Load() < n {
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case <-time.After(time.Millisecond):
+				}
+			}
+
+			return nil
+		})
+	}
+
+	report := reg.Check(context.TODO())
+	if report.Status != commonhealth.StatusOK {
+		t.Errorf("expected checks run concurrently, got %+v", report)
+	}
+}
+
+func TestRegistry_ReadinessHandler(t *testing.T) {
+	scenarios := []struct {
+		name     string
+		check    commonhealth.Checker
+		expected int
+	}{
+		{name: "[OK] Ready", check: ok, expected: http.StatusOK},
+		{name: "[Failed] Not ready", check: func(ctx context.Context) error { return errors.New("DB is connecting") }, expected: http.StatusServiceUnavailable},
+		{
+			name: "[Failed] Error is not exposed",
+			check: func(ctx context.Context) error {
+				return errors.New(`failed to connect to user=waizly host=10.0.0.5: password authentication failed`)
+			},
+			expected: http.StatusServiceUnavailable,
+		},
+	}
+
+	for _, scn := range scenarios {
+		t.Run(scn.name, func(t *testing.T) {
+			reg := commonhealth.NewRegistry(time.Second)
+			reg.Register("db_state", scn.check)
+
+			w := httptest.NewRecorder()
+			reg.ReadinessHandler()(w, httptest.NewRequest(http.MethodGet, "/readyz", nil))
+
+			if w.Code != scn.expected {
+				t.Errorf("status code mismatch: want %d, got %d", scn.expected, w.Code)
+			}
+
+			if strings.Contains(w.Body.String(), "waizly") || strings.Contains(w.Body.String(), "DB is connecting") {
+				t.Errorf("expected check error kept off the response, got %s", w.Body.String())
+			}
+
+			var body struct {
+				Data commonhealth.Report `json:"data"`
+			}
+
+			err := json.NewDecoder(w.Body).Decode(&body)
+			if err != nil {
+				t.Fatalf("failed decode body: %v", err)
+			}
+
+			if _, found := body.Data.Checks["db_state"]; !found {
+				t.Errorf("expected db_state on report, got %+v", body.Data)
+			}
+		})
+	}
+}
@@ -0,0 +1,121 @@
+package commonhttpcookie
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// CSRFHeader carries the value of CSRF cookie on unsafe requests, the double-submit
+const CSRFHeader = "X-CSRF-Token"
+
+type Config struct {
+	// Name is of the HttpOnly cookie holding the login token
+	Name string
+	// CSRFName is of the cookie readable by the web app, to be echoed in CSRFHeader
+	CSRFName string
+	Domain   string
+	Path     string
+	SameSite http.SameSite
+}
+
+// Jar sets & reads the login token cookie of browser clients along with its CSRF cookie
+type Jar struct {
+	cfg Config
+}
+
+func New(cfg Config) *Jar {
+	if cfg.Name == "" {
+		cfg.Name = "waizly_session"
+	}
+
+	if cfg.CSRFName == "" {
+		cfg.CSRFName = "waizly_csrf"
+	}
+
+	if cfg.Path == "" {
+		cfg.Path = "/"
+	}
+
+	if cfg.SameSite == 0 {
+		cfg.SameSite = http.SameSiteLaxMode
+	}
+
+	return &Jar{cfg: cfg}
+}
+
+// ParseSameSite reads SameSite of the config, empty is Lax
+func ParseSameSite(s string) (http.SameSite, error) {
+	switch strings.ToLower(s) {
+	case "", "lax":
+		return http.SameSiteLaxMode, nil
+	case "strict":
+		return http.SameSiteStrictMode, nil
+	case "none":
+		return http.SameSiteNoneMode, nil
+	default:
+		return 0, fmt.Errorf("unsupported cookie SameSite: %s", s)
+	}
+}
+
+// SetSession sets the token cookie and a fresh CSRF cookie, both expire along with the token.
+// It must be called before the response is written.
+func (j *Jar) SetSession(w http.ResponseWriter, token string, expiresAt time.Time) error {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return err
+	}
+
+	http.SetCookie(w, j.cookie(j.cfg.Name, token, expiresAt, true))
+	http.SetCookie(w, j.cookie(j.cfg.CSRFName, hex.EncodeToString(b), expiresAt, false))
+	return nil
+}
+
+// Session returns the token of the cookie, if any
+func (j *Jar) Session(r *http.Request) (string, bool) {
+	c, err := r.Cookie(j.cfg.Name)
+	if err != nil || c.Value == "" {
+		return "", false
+	}
+
+	return c.Value, true
+}
+
+// CheckCSRF tells whether CSRFHeader of the request matches its CSRF cookie
+func (j *Jar) CheckCSRF(r *http.Request) bool {
+	c, err := r.Cookie(j.cfg.CSRFName)
+	if err != nil || c.Value == "" {
+		return false
+	}
+
+	header := r.Header.Get(CSRFHeader)
+	return subtle.ConstantTimeCompare([]byte(header), []byte(c.Value)) == 1
+}
+
+func (j *Jar) cookie(name, value string, expiresAt time.Time, httpOnly bool) *http.Cookie {
+	return &http.Cookie{
+		Name:     name,
+		Value:    value,
+		Domain:   j.cfg.Domain,
+		Path:     j.cfg.Path,
+		Expires:  expiresAt,
+		MaxAge:   int(time.Until(expiresAt).Seconds()),
+		Secure:   true,
+		HttpOnly: httpOnly,
+		SameSite: j.cfg.SameSite,
+	}
+}
+
+// IsSafeMethod tells whether the method does not change state, so it needs no CSRF token
+func IsSafeMethod(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodTrace:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,72 @@
+package commonhttpcookie_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	commonhttpcookie "waizlytest/common/http/cookie"
+)
+
+func TestJar_SetSession(t *testing.T) {
+	jar := commonhttpcookie.New(commonhttpcookie.Config{Domain: "waizly.test", SameSite: http.SameSiteStrictMode})
+
+	w := httptest.NewRecorder()
+	err := jar.SetSession(w, "the-token", time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	cookies := map[string]*http.Cookie{}
+	for _, c := range w.Result().Cookies() {
+		cookies[c.Name] = c
+	}
+
+	session, csrf := cookies["waizly_session"], cookies["waizly_csrf"]
+	if session == nil || csrf == nil {
+		t.Fatalf("expected session & CSRF cookies, got %v", cookies)
+	}
+
+	if session.Value != "the-token" || !session.HttpOnly || !session.Secure || session.SameSite != http.SameSiteStrictMode || session.Domain != "waizly.test" || session.Path != "/" {
+		t.Errorf("unexpected session cookie: %v", session)
+	}
+
+	if len(csrf.Value) != 64 || csrf.HttpOnly || !csrf.Secure {
+		t.Errorf("unexpected CSRF cookie: %v", csrf)
+	}
+}
+
+func TestJar_CheckCSRF(t *testing.T) {
+	jar := commonhttpcookie.New(commonhttpcookie.Config{})
+
+	scenarios := []struct {
+		name     string
+		cookie   string
+		header   string
+		expected bool
+	}{
+		{name: "[OK] Matching", cookie: "abc", header: "abc", expected: true},
+		{name: "[Failed] No header", cookie: "abc"},
+		{name: "[Failed] No cookie", header: "abc"},
+		{name: "[Failed] Both empty"},
+		{name: "[Failed] Different", cookie: "abc", header: "abd"},
+	}
+
+	for _, scn := range scenarios {
+		t.Run(scn.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/v1/me", nil)
+			if scn.cookie != "" {
+				r.AddCookie(&http.Cookie{Name: "waizly_csrf", Value: scn.cookie})
+			}
+
+			if scn.header != "" {
+				r.Header.Set(commonhttpcookie.CSRFHeader, scn.header)
+			}
+
+			if got := jar.CheckCSRF(r); got != scn.expected {
+				t.Errorf("expected %v, got %v", scn.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,51 @@
+package commonhttpdec
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	commonerr "waizlytest/common/errors"
+	commonvalidation "waizlytest/common/validation"
+)
+
+// DecodeJSON decodes body of r into T, then validates it by `commonvalidation.Validate`.
+// Errors are business errors ready for the encoder:
+//   - empty or `null` body is `commonerr.DefEmptyRequest`
+//   - malformed JSON or wrong type of field is `commonerr.DefMalformedRequest`
+//   - failed validation is `commonerr.DefInvalidRequest` with per-field Fields
+func DecodeJSON[T any](r *http.Request) (T, error) {
+	var zero T
+
+	raw, err := io.ReadAll(r.Body)
+	if err != nil {
+		return zero, err
+	}
+
+	raw = bytes.TrimSpace(raw)
+	if len(raw) == 0 || bytes.Equal(raw, []byte("null")) {
+		return zero, commonerr.DefEmptyRequest.New()
+	}
+
+	var v T
+	err = json.Unmarshal(raw, &v)
+	if err != nil {
+		e := commonerr.DefMalformedRequest.New()
+
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) && typeErr.Field != "" {
+			e.AddTranslatableField(typeErr.Field, "must be "+typeErr.Type.String(), "validation.type", typeErr.Type.String())
+		}
+
+		return zero, e
+	}
+
+	err = commonvalidation.Validate(&v)
+	if err != nil {
+		return zero, err
+	}
+
+	return v, nil
+}
@@ -0,0 +1,89 @@
+package commonhttpdec_test
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	commonerr "waizlytest/common/errors"
+	commonhttpdec "waizlytest/common/http/decoder"
+)
+
+type request struct {
+	FullName string  `json:"fullname" validate:"required,max=5"`
+	Phone    string  `json:"phone" validate:"required,phone"`
+	Nickname *string `json:"nickname" validate:"min=2"`
+}
+
+func TestDecodeJSON(t *testing.T) {
+	scenarios := []struct {
+		name     string
+		body     string
+		expected *commonerr.Error
+	}{
+		{name: "[OK] Valid", body: `{"fullname": "Teest", "phone": "+62812345678"}`},
+		{name: "[Failed] Empty body", body: ``, expected: commonerr.DefEmptyRequest.New()},
+		{name: "[Failed] Null body", body: ` null `, expected: commonerr.DefEmptyRequest.New()},
+		{name: "[Failed] Malformed", body: `{"fullname": `, expected: commonerr.DefMalformedRequest.New()},
+		{
+			name: "[Failed] Wrong type",
+			body: `{"fullname": 1}`,
+			expected: func() *commonerr.Error {
+				e := commonerr.DefMalformedRequest.New()
+				e.AddField("fullname", "must be string")
+				return e
+			}(),
+		},
+		{
+			name: "[Failed] Every violation",
+			body: `{"fullname": "  ", "phone": "08abc", "nickname": "x"}`,
+			expected: func() *commonerr.Error {
+				e := commonerr.DefInvalidRequest.New()
+				e.AddField("fullname", "is required")
+				e.AddField("phone", "must be a valid phone number")
+				e.AddField("nickname", "must be at least 2 characters")
+				return e
+			}(),
+		},
+		{
+			name: "[Failed] Too long",
+			body: `{"fullname": "Teeeest", "phone": "0812345678"}`,
+			expected: func() *commonerr.Error {
+				e := commonerr.DefInvalidRequest.New()
+				e.AddField("fullname", "must be at most 5 characters")
+				return e
+			}(),
+		},
+	}
+
+	for _, scn := range scenarios {
+		t.Run(scn.name, func(t *testing.T) {
+			r := httptest.NewRequest("POST", "/", strings.NewReader(scn.body))
+
+			_, err := commonhttpdec.DecodeJSON[request](r)
+			if scn.expected == nil {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+
+				return
+			}
+
+			got, ok := err.(*commonerr.Error)
+			if !ok {
+				t.Fatalf("expected business error, got %v", err)
+			}
+
+			diff := cmp.Diff(scn.expected, got, cmp.FilterPath(func(p cmp.Path) bool {
+				// translation keys are covered by the encoder
+				name := p.Last().String()
+				return name == ".Key" || name == ".Args" || name == ".FieldKeys"
+			}, cmp.Ignore()))
+			if diff != "" {
+				t.Errorf("error mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
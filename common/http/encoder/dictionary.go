@@ -8,22 +8,33 @@ import (
 
 // Dictionary is used to store a mapping from `error.Type` to http status.
 // This is to make it easy for user when err happens to its http status as business rule.
+// Dictionary is never modified once given to ErrorEncoder, use Merge to derive a new one.
 type Dictionary map[commonerr.Type]int
 
-var DefaultDictionary Dictionary = Dictionary{
-	commonerr.TypeAuthenticationError:   http.StatusUnauthorized,
-	commonerr.TypeNotFoundError:         http.StatusNotFound,
-	commonerr.TypeForbiddenError:        http.StatusForbidden,
-	commonerr.TypeApplicationLimitError: http.StatusTooManyRequests,
-	commonerr.TypeInternalServerError:   http.StatusInternalServerError,
-	commonerr.TypeMaintenanceError:      http.StatusServiceUnavailable,
-	commonerr.TypeBadRequestError:       http.StatusBadRequest,
+// DefaultDictionary returns new copy of mapping for the reserved types.
+func DefaultDictionary() Dictionary {
+	return Dictionary{
+		commonerr.TypeAuthenticationError:   http.StatusUnauthorized,
+		commonerr.TypeNotFoundError:         http.StatusNotFound,
+		commonerr.TypeForbiddenError:        http.StatusForbidden,
+		commonerr.TypeApplicationLimitError: http.StatusTooManyRequests,
+		commonerr.TypeInternalServerError:   http.StatusInternalServerError,
+		commonerr.TypeMaintenanceError:      http.StatusServiceUnavailable,
+		commonerr.TypeBadRequestError:       http.StatusBadRequest,
+		commonerr.TypeConflictedError:       http.StatusConflict,
+	}
 }
 
-var dictionary = DefaultDictionary
+// Merge returns new dictionary of d overridden by other, neither of them is modified.
+func (d Dictionary) Merge(other Dictionary) Dictionary {
+	merged := make(Dictionary, len(d)+len(other))
+	for k, v := range d {
+		merged[k] = v
+	}
 
-// CreateDictionary is function to create new dictionary.
-// This is make user still have flexibiilty to use their own dictionary
-func CreateDictionary(d Dictionary) {
-	dictionary = d
+	for k, v := range other {
+		merged[k] = v
+	}
+
+	return merged
 }
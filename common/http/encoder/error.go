@@ -0,0 +1,138 @@
+package commonhttpenc
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+
+	commonerr "waizlytest/common/errors"
+	commoni18n "waizlytest/common/i18n"
+)
+
+// ErrorEncoder writes errors to client, it owns its Dictionary so each router
+// (e.g each API version) may map errors differently.
+// Messages are translated to the language stored by `commoni18n.WithAcceptLanguage`.
+// It is immutable, therefore safe for concurrent use.
+type ErrorEncoder struct {
+	dictionary     Dictionary
+	fallbackStatus int
+	problemBaseURI string
+	bundle         *commoni18n.Bundle
+}
+
+// NewErrorEncoder creates ErrorEncoder, fallbackStatus is used for error type missing from d.
+func NewErrorEncoder(d Dictionary, fallbackStatus int) *ErrorEncoder {
+	return &ErrorEncoder{
+		dictionary:     Dictionary{}.Merge(d),
+		fallbackStatus: fallbackStatus,
+		problemBaseURI: DefaultProblemTypeBaseURI,
+		bundle:         commoni18n.Default(),
+	}
+}
+
+// With returns new ErrorEncoder with d merged on top of current dictionary.
+func (enc *ErrorEncoder) With(d Dictionary) *ErrorEncoder {
+	derived := *enc
+	derived.dictionary = enc.dictionary.Merge(d)
+	return &derived
+}
+
+// WithProblemTypeBaseURI returns new ErrorEncoder prefixing problem `type` with uri.
+func (enc *ErrorEncoder) WithProblemTypeBaseURI(uri string) *ErrorEncoder {
+	derived := *enc
+	derived.problemBaseURI = uri
+	return &derived
+}
+
+// WithBundle returns new ErrorEncoder translating messages with b.
+func (enc *ErrorEncoder) WithBundle(b *commoni18n.Bundle) *ErrorEncoder {
+	derived := *enc
+	derived.bundle = b
+	return &derived
+}
+
+// Encode writes err in format negotiated earlier, see WithErrorFormat.
+func (enc *ErrorEncoder) Encode(ctx context.Context, w http.ResponseWriter, err error) {
+	if errorFormatFrom(ctx) == ErrorFormatProblem {
+		enc.EncodeProblem(ctx, w, err)
+		return
+	}
+
+	enc.EncodeJSON(ctx, w, err)
+}
+
+func (enc *ErrorEncoder) status(t commonerr.Type) int {
+	status, ok := enc.dictionary[t]
+	if !ok {
+		return enc.fallbackStatus
+	}
+
+	return status
+}
+
+// resolve turns err into business error and its HTTP status, translated to the negotiated language.
+// The returned error is a copy, so it is safe to modify.
+func (enc *ErrorEncoder) resolve(ctx context.Context, w http.ResponseWriter, err error) (*commonerr.Error, int) {
+	if err == nil {
+		return nil, http.StatusOK
+	}
+
+	span := trace.SpanFromContext(ctx)
+
+	var ce commonerr.Error
+	switch e := err.(type) {
+	case *commonerr.Error:
+		// copy, as e could be one of the shared reserved errors
+		ce = *e
+	default:
+
+		slog.ErrorContext(ctx, "internal server error", slog.Any("error", err))
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+
+		// mask the real error
+		// client(s) doesn't need to know what it is
+		ce = *commonerr.ErrorInternalServer
+	}
+
+	if sc := span.SpanContext(); sc.HasTraceID() {
+		ce.TraceID = sc.TraceID().String()
+	}
+
+	enc.translate(ctx, w, &ce)
+
+	return &ce, enc.status(ce.Type)
+}
+
+// translate replaces message & fields having key, untranslated ones are kept in English.
+func (enc *ErrorEncoder) translate(ctx context.Context, w http.ResponseWriter, e *commonerr.Error) {
+	tag := enc.bundle.Match(commoni18n.Preferences(ctx)...)
+	w.Header().Set("Content-Language", tag.String())
+
+	if e.Key != "" {
+		if msg, ok := enc.bundle.Message(tag, e.Key, e.Args...); ok {
+			e.Message = msg
+		}
+	}
+
+	if len(e.FieldKeys) == 0 {
+		return
+	}
+
+	// copy, Fields is shared with the original error
+	fields := make(commonerr.Fields, len(e.Fields))
+	for name, v := range e.Fields {
+		if t, ok := e.FieldKeys[name]; ok {
+			if msg, ok := enc.bundle.Message(tag, t.Key, t.Args...); ok {
+				v = msg
+			}
+		}
+
+		fields[name] = v
+	}
+
+	e.Fields = fields
+}
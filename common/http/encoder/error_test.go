@@ -0,0 +1,47 @@
+package commonhttpenc_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	commonerr "waizlytest/common/errors"
+	commonhttpenc "waizlytest/common/http/encoder"
+)
+
+func TestErrorEncoder_Status(t *testing.T) {
+	const typeGoneError commonerr.Type = "GoneError"
+
+	base := commonhttpenc.NewErrorEncoder(commonhttpenc.DefaultDictionary(), http.StatusInternalServerError)
+	derived := base.With(commonhttpenc.Dictionary{
+		typeGoneError:                 http.StatusGone,
+		commonerr.TypeBadRequestError: http.StatusUnprocessableEntity,
+	})
+
+	scenarios := []struct {
+		name     string
+		enc      *commonhttpenc.ErrorEncoder
+		err      error
+		expected int
+	}{
+		{name: "[OK] Conflicted", enc: base, err: commonerr.Conflicted(""), expected: http.StatusConflict},
+		{name: "[OK] Unknown type falls back", enc: base, err: commonerr.New(typeGoneError, 1, "gone"), expected: http.StatusInternalServerError},
+		{name: "[OK] Non business error", enc: base, err: errors.New("boom"), expected: http.StatusInternalServerError},
+		{name: "[OK] Derived adds type", enc: derived, err: commonerr.New(typeGoneError, 1, "gone"), expected: http.StatusGone},
+		{name: "[OK] Derived overrides type", enc: derived, err: commonerr.BadRequest(""), expected: http.StatusUnprocessableEntity},
+		{name: "[OK] Base is untouched", enc: base, err: commonerr.BadRequest(""), expected: http.StatusBadRequest},
+	}
+
+	for _, scn := range scenarios {
+		t.Run(scn.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			scn.enc.EncodeJSON(context.TODO(), w, scn.err)
+
+			if w.Code != scn.expected {
+				t.Errorf("status mismatch: want %d, got %d", scn.expected, w.Code)
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package commonhttpenc_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"testing/fstest"
+
+	"github.com/google/go-cmp/cmp"
+
+	commonerr "waizlytest/common/errors"
+	commonhttpenc "waizlytest/common/http/encoder"
+	commoni18n "waizlytest/common/i18n"
+)
+
+func TestErrorEncoder_Translate(t *testing.T) {
+	bundle := commoni18n.MustLoad(fstest.MapFS{
+		"locales/en.json": {Data: []byte(`{"test.taken": "Taken", "test.field": "[%s] is taken", "test.english_only": "English only"}`)},
+		"locales/id.json": {Data: []byte(`{"test.taken": "Sudah dipakai", "test.field": "[%s] sudah dipakai"}`)},
+	}, "locales")
+	enc := commonhttpenc.NewErrorEncoder(commonhttpenc.DefaultDictionary(), http.StatusInternalServerError).WithBundle(bundle)
+
+	newErr := func(key string) *commonerr.Error {
+		e := commonerr.New(commonerr.TypeConflictedError, 1, "Taken")
+		e.Key = key
+		e.AddTranslatableField("phone", "[0812] is taken", "test.field", "0812")
+		return e
+	}
+
+	scenarios := []struct {
+		name           string
+		acceptLanguage string
+		err            *commonerr.Error
+		expected       commonerr.Error
+	}{
+		{
+			name:           "[OK] Indonesian",
+			acceptLanguage: "id-ID,id;q=0.9,en;q=0.8",
+			err:            newErr("test.taken"),
+			expected:       commonerr.Error{Message: "Sudah dipakai", Fields: commonerr.Fields{"phone": "[0812] sudah dipakai"}},
+		},
+		{
+			name:           "[OK] Unsupported language falls back to English",
+			acceptLanguage: "ja",
+			err:            newErr("test.taken"),
+			expected:       commonerr.Error{Message: "Taken", Fields: commonerr.Fields{"phone": "[0812] is taken"}},
+		},
+		{
+			name:           "[OK] Untranslated key falls back to English",
+			acceptLanguage: "id",
+			err:            newErr("test.english_only"),
+			expected:       commonerr.Error{Message: "English only", Fields: commonerr.Fields{"phone": "[0812] sudah dipakai"}},
+		},
+		{
+			name:           "[OK] Unknown key keeps message",
+			acceptLanguage: "id",
+			err:            newErr("test.unknown"),
+			expected:       commonerr.Error{Message: "Taken", Fields: commonerr.Fields{"phone": "[0812] sudah dipakai"}},
+		},
+	}
+
+	for _, scn := range scenarios {
+		t.Run(scn.name, func(t *testing.T) {
+			ctx := commoni18n.WithAcceptLanguage(context.TODO(), scn.acceptLanguage)
+
+			w := httptest.NewRecorder()
+			enc.EncodeJSON(ctx, w, scn.err)
+
+			var resp struct {
+				Error commonerr.Error `json:"error"`
+			}
+			err := json.NewDecoder(w.Body).Decode(&resp)
+			if err != nil {
+				t.Fatalf("failed decode response: %v", err)
+			}
+
+			got := commonerr.Error{Message: resp.Error.Message, Fields: resp.Error.Fields}
+			diff := cmp.Diff(scn.expected, got)
+			if diff != "" {
+				t.Errorf("error mismatch (-want +got):\n%s", diff)
+			}
+
+			if scn.err.Fields["phone"] != "[0812] is taken" {
+				t.Errorf("original error must stay untouched, got %q", scn.err.Fields["phone"])
+			}
+		})
+	}
+}
@@ -14,3 +14,11 @@ func JSONResponseEncoder(ctx context.Context, w http.ResponseWriter, httpStatus
 	w.WriteHeader(httpStatus)
 	json.NewEncoder(w).Encode(res)
 }
+
+// JSONEncoder encodes v as is, for responses shaped by a standard instead of `commonhttpresp.Response`,
+// e.g token introspection of RFC 7662.
+func JSONEncoder(ctx context.Context, w http.ResponseWriter, httpStatus int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(httpStatus)
+	json.NewEncoder(w).Encode(v)
+}
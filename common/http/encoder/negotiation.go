@@ -0,0 +1,75 @@
+package commonhttpenc
+
+import (
+	"context"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"waizlytest/common/contextkey"
+)
+
+// ErrorFormat is representation of error response
+type ErrorFormat string
+
+const (
+	// ErrorFormatEnvelope is the `{error, data}` envelope, the default
+	ErrorFormatEnvelope ErrorFormat = "envelope"
+	// ErrorFormatProblem is RFC 7807 `application/problem+json`
+	ErrorFormatProblem ErrorFormat = "problem"
+)
+
+const (
+	mediaTypeJSON    = "application/json"
+	mediaTypeProblem = "application/problem+json"
+)
+
+// NegotiateErrorFormat picks error format from `Accept` header.
+// Problem details are only sent when client asks for them explicitly,
+// with at least the same quality as `application/json`, so existing clients keep the envelope.
+func NegotiateErrorFormat(r *http.Request) ErrorFormat {
+	var qProblem, qJSON float64 = -1, -1
+
+	for _, part := range strings.Split(r.Header.Get("Accept"), ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			q, err = strconv.ParseFloat(v, 64)
+			if err != nil {
+				continue
+			}
+		}
+
+		switch mediaType {
+		case mediaTypeProblem:
+			qProblem = max(qProblem, q)
+		case mediaTypeJSON:
+			qJSON = max(qJSON, q)
+		}
+	}
+
+	if qProblem > 0 && qProblem >= qJSON {
+		return ErrorFormatProblem
+	}
+
+	return ErrorFormatEnvelope
+}
+
+// WithErrorFormat stores negotiated format for encoders down the chain.
+func WithErrorFormat(ctx context.Context, f ErrorFormat) context.Context {
+	return context.WithValue(ctx, contextkey.ErrorFormatContextKey, f)
+}
+
+func errorFormatFrom(ctx context.Context) ErrorFormat {
+	f, ok := ctx.Value(contextkey.ErrorFormatContextKey).(ErrorFormat)
+	if !ok {
+		return ErrorFormatEnvelope
+	}
+
+	return f
+}
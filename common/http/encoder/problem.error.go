@@ -0,0 +1,87 @@
+package commonhttpenc
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	commonerr "waizlytest/common/errors"
+)
+
+// DefaultProblemTypeBaseURI prefixes `type` member of problem details,
+// see ErrorEncoder.WithProblemTypeBaseURI
+const DefaultProblemTypeBaseURI = "https://waizly.com/problems"
+
+// Problem is RFC 7807 problem details, extended with our error code, trace ID and invalid params.
+type Problem struct {
+	Type          string         `json:"type"`
+	Title         string         `json:"title"`
+	Status        int            `json:"status"`
+	Detail        string         `json:"detail,omitempty"`
+	Code          commonerr.Code `json:"code"`
+	InvalidParams []InvalidParam `json:"invalid-params,omitempty"`
+	TraceID       string         `json:"trace_id,omitempty"`
+}
+
+type InvalidParam struct {
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+// EncodeProblem encodes the passed err to client as `application/problem+json`.
+// Using Dictionary to help directing err to each own HTTP status, same as EncodeJSON.
+func (enc *ErrorEncoder) EncodeProblem(ctx context.Context, w http.ResponseWriter, err error) {
+	e, httpStatus := enc.resolve(ctx, w, err)
+	if e == nil {
+		w.WriteHeader(httpStatus)
+		return
+	}
+
+	p := Problem{
+		Type:    problemType(enc.problemBaseURI, e),
+		Title:   http.StatusText(httpStatus),
+		Status:  httpStatus,
+		Detail:  e.Message,
+		Code:    e.Code,
+		TraceID: e.TraceID,
+	}
+
+	for name, reason := range e.Fields {
+		p.InvalidParams = append(p.InvalidParams, InvalidParam{Name: name, Reason: reason})
+	}
+
+	// map has no order, keep the response stable
+	sort.Slice(p.InvalidParams, func(i, j int) bool {
+		return p.InvalidParams[i].Name < p.InvalidParams[j].Name
+	})
+
+	w.Header().Set("Content-Type", mediaTypeProblem)
+	w.WriteHeader(httpStatus)
+	json.NewEncoder(w).Encode(p)
+}
+
+// problemType builds type URI, e.g `https://waizly.com/problems/bad-request-error/101`
+func problemType(baseURI string, e *commonerr.Error) string {
+	return baseURI + "/" + kebab(string(e.Type)) + "/" + strconv.Itoa(int(e.Code))
+}
+
+func kebab(s string) string {
+	var b strings.Builder
+	for i, r := range s {
+		if unicode.IsUpper(r) {
+			if i > 0 {
+				b.WriteByte('-')
+			}
+
+			r = unicode.ToLower(r)
+		}
+
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
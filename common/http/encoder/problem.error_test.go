@@ -0,0 +1,64 @@
+package commonhttpenc_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	commonerr "waizlytest/common/errors"
+	commonhttpenc "waizlytest/common/http/encoder"
+)
+
+func TestNegotiateErrorFormat(t *testing.T) {
+	scenarios := []struct {
+		accept   string
+		expected commonhttpenc.ErrorFormat
+	}{
+		{accept: "", expected: commonhttpenc.ErrorFormatEnvelope},
+		{accept: "*/*", expected: commonhttpenc.ErrorFormatEnvelope},
+		{accept: "application/json", expected: commonhttpenc.ErrorFormatEnvelope},
+		{accept: "application/problem+json", expected: commonhttpenc.ErrorFormatProblem},
+		{accept: "application/json, application/problem+json", expected: commonhttpenc.ErrorFormatProblem},
+		{accept: "application/json, application/problem+json;q=0.5", expected: commonhttpenc.ErrorFormatEnvelope},
+		{accept: "application/problem+json;q=0", expected: commonhttpenc.ErrorFormatEnvelope},
+	}
+
+	for _, scn := range scenarios {
+		t.Run(scn.accept, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.Header.Set("Accept", scn.accept)
+
+			got := commonhttpenc.NegotiateErrorFormat(r)
+			if got != scn.expected {
+				t.Errorf("format mismatch: want %s, got %s", scn.expected, got)
+			}
+		})
+	}
+}
+
+func TestErrorEncoder_EncodeProblem(t *testing.T) {
+	e := commonerr.BadRequest("")
+	e.AddField("phone", "[0812] is not a phone number")
+
+	enc := commonhttpenc.NewErrorEncoder(commonhttpenc.DefaultDictionary(), http.StatusInternalServerError)
+
+	w := httptest.NewRecorder()
+	enc.EncodeProblem(context.TODO(), w, e)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("status mismatch: want %d, got %d", http.StatusBadRequest, w.Code)
+	}
+
+	if ct := w.Header().Get("Content-Type"); ct != "application/problem+json" {
+		t.Errorf("content type mismatch: got %s", ct)
+	}
+
+	expected := `{"type":"https://waizly.com/problems/bad-request-error/101","title":"Bad Request","status":400,"detail":"Bad request","code":101,"invalid-params":[{"name":"phone","reason":"[0812] is not a phone number"}]}` + "\n"
+	diff := cmp.Diff(expected, w.Body.String())
+	if diff != "" {
+		t.Errorf("body mismatch (-want +got):\n%s", diff)
+	}
+}
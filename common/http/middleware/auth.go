@@ -1,52 +1,161 @@
 package commonhttpmiddleware
 
 import (
-	"context"
 	"net/http"
 	"strings"
+	"time"
 
-	"waizlytest/common/contextkey"
 	commonerr "waizlytest/common/errors"
+	commonhttpcookie "waizlytest/common/http/cookie"
 	commonhttpenc "waizlytest/common/http/encoder"
+	commonlog "waizlytest/common/log"
 	authservice "waizlytest/services/auth"
 )
 
 type AuthMiddleware struct {
-	authz authservice.Authz
+	authz   authservice.Authz
+	apiKeys authservice.APIKeyAuthz
+	// cookies is nil when cookie mode is disabled
+	cookies *commonhttpcookie.Jar
+
+	enc *commonhttpenc.ErrorEncoder
 }
 
-func NewAuthMiddleware(authz authservice.Authz) *AuthMiddleware {
+func NewAuthMiddleware(authz authservice.Authz, apiKeys authservice.APIKeyAuthz, cookies *commonhttpcookie.Jar, enc *commonhttpenc.ErrorEncoder) *AuthMiddleware {
 	return &AuthMiddleware{
-		authz: authz,
+		authz:   authz,
+		apiKeys: apiKeys,
+		cookies: cookies,
+		enc:     enc,
 	}
 }
 
+// Auth accepts either `Authorization: Bearer <token>` of a login or `Authorization: ApiKey <key>`.
+// Without Authorization header, the login token cookie is accepted when cookie mode is enabled,
+// along with X-CSRF-Token header matching the CSRF cookie on unsafe methods.
 func (md *AuthMiddleware) Auth(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
 
 		authHeader := r.Header.Get("Authorization")
 		if authHeader == "" {
-			e := commonerr.ErrorForbidden
-			commonhttpenc.JSONErrorEncoder(ctx, w, e)
+			md.cookieAuth(next, w, r)
 			return
 		}
 
 		values := strings.Fields(authHeader)
-		if len(values) != 2 || values[0] != "Bearer" {
+		if len(values) != 2 {
 			e := commonerr.ErrorForbidden
-			commonhttpenc.JSONErrorEncoder(ctx, w, e)
+			md.enc.Encode(ctx, w, e)
 			return
 		}
 
-		token := values[1]
-		id, err := md.authz.ValidateToken(token)
+		var p authservice.Principal
+		var err error
+		switch values[0] {
+		case "Bearer":
+			p, err = md.authz.ValidateToken(ctx, values[1])
+		case "ApiKey":
+			p, err = md.apiKeys.ValidateAPIKey(ctx, values[1])
+		default:
+			err = commonerr.ErrorForbidden
+		}
+
 		if err != nil {
-			commonhttpenc.JSONErrorEncoder(ctx, w, err)
+			md.enc.Encode(ctx, w, err)
 			return
 		}
 
-		ctx = context.WithValue(ctx, contextkey.UserIDContextKey, id)
-		next.ServeHTTP(w, r.WithContext(ctx))
+		md.serve(next, w, r, p)
 	})
 }
+
+func (md *AuthMiddleware) cookieAuth(next http.Handler, w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+
+	var token string
+	ok := false
+	if md.cookies != nil {
+		token, ok = md.cookies.Session(r)
+	}
+
+	if !ok {
+		e := commonerr.ErrorForbidden
+		md.enc.Encode(ctx, w, e)
+		return
+	}
+
+	// browser sends the cookie by itself, even on requests forged by other sites
+	if !commonhttpcookie.IsSafeMethod(r.Method) && !md.cookies.CheckCSRF(r) {
+		e := authservice.ErrCSRFInvalid.New()
+		md.enc.Encode(ctx, w, e)
+		return
+	}
+
+	p, err := md.authz.ValidateToken(ctx, token)
+	if err != nil {
+		md.enc.Encode(ctx, w, err)
+		return
+	}
+
+	md.serve(next, w, r, p)
+}
+
+func (md *AuthMiddleware) serve(next http.Handler, w http.ResponseWriter, r *http.Request, p authservice.Principal) {
+	ctx := r.Context()
+
+	commonlog.SetUserID(ctx, p.UserID)
+
+	ctx = authservice.WithPrincipal(ctx, p)
+	next.ServeHTTP(w, r.WithContext(ctx))
+}
+
+// RequireScope lets API key through only when it has one of scopes, so no scopes means
+// the route is for login token only. Login token is not limited, it must be after Auth.
+func (md *AuthMiddleware) RequireScope(scopes ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+
+			p, ok := authservice.PrincipalFrom(ctx)
+			if !ok {
+				e := commonerr.ErrorForbidden
+				md.enc.Encode(ctx, w, e)
+				return
+			}
+
+			if !p.Allows(scopes...) {
+				e := authservice.ErrScopeMissing.New()
+				md.enc.Encode(ctx, w, e)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireRecentAuth guards sensitive routes by a login no longer than maxAge ago, by one of methods
+// (`amr` values) when given. Otherwise the client should prompt for credentials, it must be after Auth.
+func (md *AuthMiddleware) RequireRecentAuth(maxAge time.Duration, methods ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := r.Context()
+
+			p, ok := authservice.PrincipalFrom(ctx)
+			if !ok {
+				e := commonerr.ErrorForbidden
+				md.enc.Encode(ctx, w, e)
+				return
+			}
+
+			if !p.AuthenticatedWithin(time.Now(), maxAge, methods...) {
+				e := authservice.ErrReauthRequired.New()
+				md.enc.Encode(ctx, w, e)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,477 @@
+package commonhttpmiddleware_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/mock"
+
+	commonerr "waizlytest/common/errors"
+	commonhttpcookie "waizlytest/common/http/cookie"
+	commonhttpenc "waizlytest/common/http/encoder"
+	commonhttpmiddleware "waizlytest/common/http/middleware"
+	authservice "waizlytest/services/auth"
+)
+
+// errorCode is code of the error response, zero when there is no error
+func errorCode(t *testing.T, w *httptest.ResponseRecorder) commonerr.Code {
+	t.Helper()
+
+	var body struct {
+		Error *struct {
+			Code commonerr.Code `json:"code"`
+		} `json:"error"`
+	}
+
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil {
+		t.Fatalf("failed decode response %q: %v", w.Body.String(), err)
+	}
+
+	if body.Error == nil {
+		return 0
+	}
+
+	return body.Error.Code
+}
+
+type mockAuthz struct {
+	mock.Mock
+}
+
+func (m *mockAuthz) ValidateToken(ctx context.Context, token string) (authservice.Principal, error) {
+	args := m.Called(ctx, token)
+	return args.Get(0).(authservice.Principal), args.Error(1)
+}
+
+type mockAPIKeyAuthz struct {
+	mock.Mock
+}
+
+func (m *mockAPIKeyAuthz) ValidateAPIKey(ctx context.Context, key string) (authservice.Principal, error) {
+	args := m.Called(ctx, key)
+	return args.Get(0).(authservice.Principal), args.Error(1)
+}
+
+var (
+	userPrincipal = authservice.Principal{
+		SubjectType: authservice.SubjectUser,
+		UserID:      1,
+		Roles:       []string{authservice.RoleUser},
+		SessionID:   "session",
+	}
+	apiKeyPrincipal = authservice.Principal{
+		SubjectType: authservice.SubjectAPIKey,
+		UserID:      1,
+		Roles:       []string{authservice.RoleUser},
+		Scopes:      []string{authservice.ScopeProfileRead},
+	}
+)
+
+func newAuthz() (*mockAuthz, *mockAPIKeyAuthz) {
+	authz := &mockAuthz{}
+	authz.On("ValidateToken", mock.Anything, "good-token").Return(userPrincipal, nil)
+	authz.On("ValidateToken", mock.Anything, mock.Anything).Return(authservice.Principal{}, authservice.ErrTokenInvalid.New())
+
+	apiKeys := &mockAPIKeyAuthz{}
+	apiKeys.On("ValidateAPIKey", mock.Anything, "good-key").Return(apiKeyPrincipal, nil)
+	apiKeys.On("ValidateAPIKey", mock.Anything, mock.Anything).Return(authservice.Principal{}, authservice.ErrAPIKeyInvalid.New())
+
+	return authz, apiKeys
+}
+
+// capture is the next handler, keeping principal it is reached with
+func capture(got **authservice.Principal) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if p, ok := authservice.PrincipalFrom(r.Context()); ok {
+			*got = &p
+		}
+
+		ok().ServeHTTP(w, r)
+	})
+}
+
+func ok() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"error":null,"data":null}`))
+	})
+}
+
+func TestAuthMiddleware_RequireRecentAuth(t *testing.T) {
+	enc := commonhttpenc.NewErrorEncoder(commonhttpenc.DefaultDictionary(), http.StatusInternalServerError)
+	md := commonhttpmiddleware.NewAuthMiddleware(nil, nil, nil, enc)
+
+	reauthRequired := authservice.ErrReauthRequired.New()
+
+	scenarios := []struct {
+		name      string
+		principal *authservice.Principal
+		methods   []string
+
+		expectedStatus int
+		expectedCode   commonerr.Code
+	}{
+		{
+			name:           "[OK] Recent login",
+			principal:      &authservice.Principal{SubjectType: authservice.SubjectUser, UserID: 1, AuthTime: time.Now().Add(-time.Minute), AuthMethods: []string{authservice.AMRPassword}},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "[OK] Recent login by required method",
+			principal:      &authservice.Principal{SubjectType: authservice.SubjectUser, UserID: 1, AuthTime: time.Now().Add(-time.Minute), AuthMethods: []string{authservice.AMRHardware, authservice.AMRMultiFactor}},
+			methods:        []string{authservice.AMRMultiFactor},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "[Failed] Old login",
+			principal:      &authservice.Principal{SubjectType: authservice.SubjectUser, UserID: 1, AuthTime: time.Now().Add(-time.Hour), AuthMethods: []string{authservice.AMRPassword}},
+			expectedStatus: http.StatusUnauthorized,
+			expectedCode:   reauthRequired.Code,
+		},
+		{
+			name:           "[Failed] Recent login by other method",
+			principal:      &authservice.Principal{SubjectType: authservice.SubjectUser, UserID: 1, AuthTime: time.Now().Add(-time.Minute), AuthMethods: []string{authservice.AMRPassword}},
+			methods:        []string{authservice.AMRMultiFactor},
+			expectedStatus: http.StatusUnauthorized,
+			expectedCode:   reauthRequired.Code,
+		},
+		{
+			name:           "[Failed] API key",
+			principal:      &authservice.Principal{SubjectType: authservice.SubjectAPIKey, UserID: 1, Scopes: []string{authservice.ScopeProfileWrite}, AuthTime: time.Now().Add(-time.Second)},
+			expectedStatus: http.StatusUnauthorized,
+			expectedCode:   reauthRequired.Code,
+		},
+		{
+			name:           "[Failed] Not authenticated",
+			expectedStatus: http.StatusForbidden,
+			expectedCode:   commonerr.ErrorForbidden.Code,
+		},
+	}
+
+	for _, scn := range scenarios {
+		t.Run(scn.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPut, "/v1/me", nil)
+			if scn.principal != nil {
+				r = r.WithContext(authservice.WithPrincipal(context.TODO(), *scn.principal))
+			}
+
+			w := httptest.NewRecorder()
+			md.RequireRecentAuth(5*time.Minute, scn.methods...)(ok()).ServeHTTP(w, r)
+
+			if w.Code != scn.expectedStatus {
+				t.Errorf("status mismatch: want %d, got %d", scn.expectedStatus, w.Code)
+			}
+
+			if code := errorCode(t, w); code != scn.expectedCode {
+				t.Errorf("code mismatch: want %d, got %d", scn.expectedCode, code)
+			}
+		})
+	}
+}
+
+func TestAuthMiddleware_Auth(t *testing.T) {
+	enc := commonhttpenc.NewErrorEncoder(commonhttpenc.DefaultDictionary(), http.StatusInternalServerError)
+	authz, apiKeys := newAuthz()
+	md := commonhttpmiddleware.NewAuthMiddleware(authz, apiKeys, nil, enc)
+
+	scenarios := []struct {
+		name          string
+		authorization string
+
+		expectedStatus    int
+		expectedCode      commonerr.Code
+		expectedPrincipal *authservice.Principal
+	}{
+		{
+			name:              "[OK] Bearer token",
+			authorization:     "Bearer good-token",
+			expectedStatus:    http.StatusOK,
+			expectedPrincipal: &userPrincipal,
+		},
+		{
+			name:              "[OK] API key",
+			authorization:     "ApiKey good-key",
+			expectedStatus:    http.StatusOK,
+			expectedPrincipal: &apiKeyPrincipal,
+		},
+		{
+			name:           "[Failed] Invalid token",
+			authorization:  "Bearer bad-token",
+			expectedStatus: http.StatusUnauthorized,
+			expectedCode:   authservice.ErrTokenInvalid.New().Code,
+		},
+		{
+			name:           "[Failed] Invalid API key",
+			authorization:  "ApiKey bad-key",
+			expectedStatus: http.StatusUnauthorized,
+			expectedCode:   authservice.ErrAPIKeyInvalid.New().Code,
+		},
+		{
+			name:           "[Failed] API key sent as Bearer",
+			authorization:  "Bearer good-key",
+			expectedStatus: http.StatusUnauthorized,
+			expectedCode:   authservice.ErrTokenInvalid.New().Code,
+		},
+		{
+			name:           "[Failed] Unknown scheme",
+			authorization:  "Basic good-token",
+			expectedStatus: http.StatusForbidden,
+			expectedCode:   commonerr.ErrorForbidden.Code,
+		},
+		{
+			name:           "[Failed] Malformed header",
+			authorization:  "Bearer",
+			expectedStatus: http.StatusForbidden,
+			expectedCode:   commonerr.ErrorForbidden.Code,
+		},
+		{
+			name:           "[Failed] No header",
+			expectedStatus: http.StatusForbidden,
+			expectedCode:   commonerr.ErrorForbidden.Code,
+		},
+	}
+
+	for _, scn := range scenarios {
+		t.Run(scn.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/v1/me", nil)
+			if scn.authorization != "" {
+				r.Header.Set("Authorization", scn.authorization)
+			}
+
+			var got *authservice.Principal
+			w := httptest.NewRecorder()
+			md.Auth(capture(&got)).ServeHTTP(w, r)
+
+			if w.Code != scn.expectedStatus {
+				t.Errorf("status mismatch: want %d, got %d", scn.expectedStatus, w.Code)
+			}
+
+			if code := errorCode(t, w); code != scn.expectedCode {
+				t.Errorf("code mismatch: want %d, got %d", scn.expectedCode, code)
+			}
+
+			diff := cmp.Diff(scn.expectedPrincipal, got)
+			if diff != "" {
+				t.Errorf("principal mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestAuthMiddleware_RequireScope(t *testing.T) {
+	enc := commonhttpenc.NewErrorEncoder(commonhttpenc.DefaultDictionary(), http.StatusInternalServerError)
+	md := commonhttpmiddleware.NewAuthMiddleware(nil, nil, nil, enc)
+
+	scopeMissing := authservice.ErrScopeMissing.New()
+
+	scenarios := []struct {
+		name      string
+		principal *authservice.Principal
+		scopes    []string
+
+		expectedStatus int
+		expectedCode   commonerr.Code
+	}{
+		{
+			name:           "[OK] Login token on scoped route",
+			principal:      &userPrincipal,
+			scopes:         []string{authservice.ScopeProfileWrite},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "[OK] Login token on login token only route",
+			principal:      &userPrincipal,
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "[OK] API key with the scope",
+			principal:      &apiKeyPrincipal,
+			scopes:         []string{authservice.ScopeProfileRead},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "[Failed] API key without the scope",
+			principal:      &apiKeyPrincipal,
+			scopes:         []string{authservice.ScopeProfileWrite},
+			expectedStatus: http.StatusForbidden,
+			expectedCode:   scopeMissing.Code,
+		},
+		{
+			name:           "[Failed] API key on login token only route",
+			principal:      &apiKeyPrincipal,
+			expectedStatus: http.StatusForbidden,
+			expectedCode:   scopeMissing.Code,
+		},
+		{
+			name:           "[Failed] Not authenticated",
+			scopes:         []string{authservice.ScopeProfileRead},
+			expectedStatus: http.StatusForbidden,
+			expectedCode:   commonerr.ErrorForbidden.Code,
+		},
+	}
+
+	for _, scn := range scenarios {
+		t.Run(scn.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/v1/me", nil)
+			if scn.principal != nil {
+				r = r.WithContext(authservice.WithPrincipal(context.TODO(), *scn.principal))
+			}
+
+			w := httptest.NewRecorder()
+			md.RequireScope(scn.scopes...)(ok()).ServeHTTP(w, r)
+
+			if w.Code != scn.expectedStatus {
+				t.Errorf("status mismatch: want %d, got %d", scn.expectedStatus, w.Code)
+			}
+
+			if code := errorCode(t, w); code != scn.expectedCode {
+				t.Errorf("code mismatch: want %d, got %d", scn.expectedCode, code)
+			}
+		})
+	}
+}
+
+func TestAuthMiddleware_Auth_Cookie(t *testing.T) {
+	enc := commonhttpenc.NewErrorEncoder(commonhttpenc.DefaultDictionary(), http.StatusInternalServerError)
+	authz, apiKeys := newAuthz()
+	cookies := commonhttpcookie.New(commonhttpcookie.Config{})
+
+	csrfInvalid := authservice.ErrCSRFInvalid.New()
+
+	scenarios := []struct {
+		name          string
+		cookies       *commonhttpcookie.Jar
+		method        string
+		authorization string
+		session       string
+		csrf          string
+		csrfHeader    string
+
+		expectedStatus    int
+		expectedCode      commonerr.Code
+		expectedPrincipal *authservice.Principal
+	}{
+		{
+			name:              "[OK] Safe method needs no CSRF token",
+			cookies:           cookies,
+			method:            http.MethodGet,
+			session:           "good-token",
+			expectedStatus:    http.StatusOK,
+			expectedPrincipal: &userPrincipal,
+		},
+		{
+			name:              "[OK] Unsafe method with matching CSRF token",
+			cookies:           cookies,
+			method:            http.MethodPut,
+			session:           "good-token",
+			csrf:              "csrf",
+			csrfHeader:        "csrf",
+			expectedStatus:    http.StatusOK,
+			expectedPrincipal: &userPrincipal,
+		},
+		{
+			name:              "[OK] Authorization header goes first, without CSRF token",
+			cookies:           cookies,
+			method:            http.MethodDelete,
+			authorization:     "ApiKey good-key",
+			session:           "bad-token",
+			expectedStatus:    http.StatusOK,
+			expectedPrincipal: &apiKeyPrincipal,
+		},
+		{
+			name:           "[Failed] Unsafe method without CSRF header",
+			cookies:        cookies,
+			method:         http.MethodPost,
+			session:        "good-token",
+			csrf:           "csrf",
+			expectedStatus: http.StatusForbidden,
+			expectedCode:   csrfInvalid.Code,
+		},
+		{
+			name:           "[Failed] Unsafe method with wrong CSRF header",
+			cookies:        cookies,
+			method:         http.MethodPatch,
+			session:        "good-token",
+			csrf:           "csrf",
+			csrfHeader:     "other",
+			expectedStatus: http.StatusForbidden,
+			expectedCode:   csrfInvalid.Code,
+		},
+		{
+			name:           "[Failed] Unsafe method without CSRF cookie",
+			cookies:        cookies,
+			method:         http.MethodDelete,
+			session:        "good-token",
+			csrfHeader:     "csrf",
+			expectedStatus: http.StatusForbidden,
+			expectedCode:   csrfInvalid.Code,
+		},
+		{
+			name:           "[Failed] Invalid token cookie",
+			cookies:        cookies,
+			method:         http.MethodGet,
+			session:        "bad-token",
+			expectedStatus: http.StatusUnauthorized,
+			expectedCode:   authservice.ErrTokenInvalid.New().Code,
+		},
+		{
+			name:           "[Failed] No cookie",
+			cookies:        cookies,
+			method:         http.MethodGet,
+			expectedStatus: http.StatusForbidden,
+			expectedCode:   commonerr.ErrorForbidden.Code,
+		},
+		{
+			name:           "[Failed] Cookie mode disabled",
+			method:         http.MethodGet,
+			session:        "good-token",
+			expectedStatus: http.StatusForbidden,
+			expectedCode:   commonerr.ErrorForbidden.Code,
+		},
+	}
+
+	for _, scn := range scenarios {
+		t.Run(scn.name, func(t *testing.T) {
+			md := commonhttpmiddleware.NewAuthMiddleware(authz, apiKeys, scn.cookies, enc)
+
+			r := httptest.NewRequest(scn.method, "/v1/me", nil)
+			if scn.authorization != "" {
+				r.Header.Set("Authorization", scn.authorization)
+			}
+
+			if scn.session != "" {
+				r.AddCookie(&http.Cookie{Name: "waizly_session", Value: scn.session})
+			}
+
+			if scn.csrf != "" {
+				r.AddCookie(&http.Cookie{Name: "waizly_csrf", Value: scn.csrf})
+			}
+
+			if scn.csrfHeader != "" {
+				r.Header.Set(commonhttpcookie.CSRFHeader, scn.csrfHeader)
+			}
+
+			var got *authservice.Principal
+			w := httptest.NewRecorder()
+			md.Auth(capture(&got)).ServeHTTP(w, r)
+
+			if w.Code != scn.expectedStatus {
+				t.Errorf("status mismatch: want %d, got %d", scn.expectedStatus, w.Code)
+			}
+
+			if code := errorCode(t, w); code != scn.expectedCode {
+				t.Errorf("code mismatch: want %d, got %d", scn.expectedCode, code)
+			}
+
+			diff := cmp.Diff(scn.expectedPrincipal, got)
+			if diff != "" {
+				t.Errorf("principal mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
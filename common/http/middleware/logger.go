@@ -0,0 +1,77 @@
+package commonhttpmiddleware
+
+import (
+	"bytes"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5/middleware"
+
+	commonlog "waizlytest/common/log"
+)
+
+// maxLoggedBody caps request body logged on debug level
+const maxLoggedBody = 4 << 10
+
+type LoggerMiddleware struct {
+	logger *slog.Logger
+}
+
+func NewLoggerMiddleware(logger *slog.Logger) *LoggerMiddleware {
+	return &LoggerMiddleware{
+		logger: logger,
+	}
+}
+
+// Log writes one line per request. On debug level the request body is included,
+// with sensitive fields redacted.
+func (md *LoggerMiddleware) Log(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := commonlog.WithRequestFields(r.Context())
+		r = r.WithContext(ctx)
+
+		start := time.Now()
+		attrs := []slog.Attr{
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.Path),
+			slog.String("remote_addr", r.RemoteAddr),
+			slog.String("user_agent", r.UserAgent()),
+		}
+
+		if r.Body != nil && md.logger.Enabled(ctx, slog.LevelDebug) {
+			body, err := io.ReadAll(io.LimitReader(r.Body, maxLoggedBody))
+			if err == nil && len(body) > 0 {
+				attrs = append(attrs, slog.String("body", commonlog.RedactJSON(body)))
+			}
+
+			// give back what was read, so handler still sees the whole body
+			r.Body = struct {
+				io.Reader
+				io.Closer
+			}{io.MultiReader(bytes.NewReader(body), r.Body), r.Body}
+		}
+
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		next.ServeHTTP(ww, r)
+
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+
+		level := slog.LevelInfo
+		if status >= http.StatusInternalServerError {
+			level = slog.LevelError
+		}
+
+		attrs = append(attrs,
+			slog.Int("status", status),
+			slog.Int("bytes", ww.BytesWritten()),
+			slog.Float64("duration_ms", float64(time.Since(start).Microseconds())/1000),
+		)
+
+		md.logger.LogAttrs(ctx, level, "request completed", attrs...)
+	})
+}
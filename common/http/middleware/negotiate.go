@@ -0,0 +1,21 @@
+package commonhttpmiddleware
+
+import (
+	"net/http"
+
+	commonhttpenc "waizlytest/common/http/encoder"
+	commoni18n "waizlytest/common/i18n"
+)
+
+// Negotiate picks error format of the request from its `Accept` header,
+// see `commonhttpenc.NegotiateErrorFormat`, and language of messages from `Accept-Language`.
+func Negotiate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept")
+		w.Header().Add("Vary", "Accept-Language")
+
+		ctx := commonhttpenc.WithErrorFormat(r.Context(), commonhttpenc.NegotiateErrorFormat(r))
+		ctx = commoni18n.WithAcceptLanguage(ctx, r.Header.Get("Accept-Language"))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
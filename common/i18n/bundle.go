@@ -0,0 +1,119 @@
+package commoni18n
+
+import (
+	"embed"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"path"
+	"strings"
+
+	"golang.org/x/text/language"
+)
+
+//go:embed locales/*.json
+var locales embed.FS
+
+// Fallback is the language of messages written in code,
+// used whenever key is missing from the requested language.
+var Fallback = language.English
+
+var defaultBundle = MustLoad(locales, "locales")
+
+// Default returns bundle of the embedded locales.
+func Default() *Bundle {
+	return defaultBundle
+}
+
+// Bundle holds translated messages by language, each message is a `fmt` format
+// so translations may reorder arguments with explicit index, e.g `%[2]s`.
+// It is immutable once loaded, therefore safe for concurrent use.
+type Bundle struct {
+	tags     []language.Tag
+	matcher  language.Matcher
+	messages map[language.Tag]map[string]string
+}
+
+// Load reads every `<language>.json` of dir, which is flat object of key to message.
+// Fallback language must exist, it is picked when nothing else matches.
+func Load(fsys fs.FS, dir string) (*Bundle, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	b := &Bundle{
+		tags:     []language.Tag{Fallback},
+		messages: map[language.Tag]map[string]string{},
+	}
+
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || path.Ext(name) != ".json" {
+			continue
+		}
+
+		tag, err := language.Parse(strings.TrimSuffix(name, ".json"))
+		if err != nil {
+			return nil, fmt.Errorf("locale %s: %w", name, err)
+		}
+
+		raw, err := fs.ReadFile(fsys, path.Join(dir, name))
+		if err != nil {
+			return nil, err
+		}
+
+		var messages map[string]string
+		err = json.Unmarshal(raw, &messages)
+		if err != nil {
+			return nil, fmt.Errorf("locale %s: %w", name, err)
+		}
+
+		b.messages[tag] = messages
+		if tag != Fallback {
+			b.tags = append(b.tags, tag)
+		}
+	}
+
+	if _, ok := b.messages[Fallback]; !ok {
+		return nil, fmt.Errorf("locale %s is missing", Fallback)
+	}
+
+	// first tag is the default of matcher
+	b.matcher = language.NewMatcher(b.tags)
+	return b, nil
+}
+
+// MustLoad is Load which panics on error, meant for embedded locales.
+func MustLoad(fsys fs.FS, dir string) *Bundle {
+	b, err := Load(fsys, dir)
+	if err != nil {
+		panic(fmt.Sprintf("commoni18n: %v", err))
+	}
+
+	return b
+}
+
+// Match picks the best supported language of prefs, ordered by preference.
+func (b *Bundle) Match(prefs ...language.Tag) language.Tag {
+	_, i, _ := b.matcher.Match(prefs...)
+	return b.tags[i]
+}
+
+// Message formats key of tag with args, falling back to Fallback language.
+// It reports false when no language has the key.
+func (b *Bundle) Message(tag language.Tag, key string, args ...any) (string, bool) {
+	format, ok := b.messages[tag][key]
+	if !ok {
+		format, ok = b.messages[Fallback][key]
+		if !ok {
+			return "", false
+		}
+	}
+
+	if len(args) == 0 {
+		return format, true
+	}
+
+	return fmt.Sprintf(format, args...), true
+}
@@ -0,0 +1,26 @@
+package commoni18n
+
+import (
+	"context"
+
+	"golang.org/x/text/language"
+
+	"waizlytest/common/contextkey"
+)
+
+// WithAcceptLanguage stores preferred languages of `Accept-Language` header,
+// invalid header is treated as no preference.
+func WithAcceptLanguage(ctx context.Context, header string) context.Context {
+	prefs, _, err := language.ParseAcceptLanguage(header)
+	if err != nil {
+		prefs = nil
+	}
+
+	return context.WithValue(ctx, contextkey.LanguageContextKey, prefs)
+}
+
+// Preferences returns languages stored by WithAcceptLanguage, ordered by preference.
+func Preferences(ctx context.Context) []language.Tag {
+	prefs, _ := ctx.Value(contextkey.LanguageContextKey).([]language.Tag)
+	return prefs
+}
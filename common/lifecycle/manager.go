@@ -0,0 +1,198 @@
+package commonlifecycle
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// Exit codes returned by Manager.Run
+const (
+	ExitOK     = 0
+	ExitForced = 1
+)
+
+const defaultTimeout = 15 * time.Second
+
+// Manager runs the app lifecycle, on SIGINT/SIGTERM it shuts down in order:
+//  1. servers stop accepting new connections and drain in-flight requests
+//  2. background workers are told to stop and awaited
+//  3. closers (e.g DB) are called in reverse order of registration
+//
+// Everything must be done within the graceful timeout, otherwise the shutdown is forced.
+type Manager struct {
+	timeout time.Duration
+
+	// ctx is done once shutdown is requested
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	// workerCtx is done once servers are drained
+	workerCtx    context.Context
+	workerCancel context.CancelFunc
+	workers      sync.WaitGroup
+
+	servers []*http.Server
+	closers []closer
+
+	// failed receives error of server which stopped unexpectedly, or the one given to Fail
+	failed  chan error
+	signals chan os.Signal
+}
+
+type closer struct {
+	name string
+	fn   func() error
+}
+
+// New creates Manager, zero timeout falls back to 15 seconds.
+func New(timeout time.Duration) *Manager {
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+
+	m := &Manager{
+		timeout: timeout,
+		failed:  make(chan error, 1),
+		signals: make(chan os.Signal, 2),
+	}
+
+	m.ctx, m.cancel = context.WithCancel(context.Background())
+	m.workerCtx, m.workerCancel = context.WithCancel(context.Background())
+
+	signal.Notify(m.signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		select {
+		case sig := <-m.signals:
+			slog.Info("received signal, shutting down", slog.String("signal", sig.String()))
+			m.cancel()
+		case <-m.ctx.Done():
+		}
+	}()
+
+	return m
+}
+
+// Context is done once shutdown is requested, use it to abort startup work.
+func (m *Manager) Context() context.Context {
+	return m.ctx
+}
+
+// Stop requests shutdown, as if signal was received.
+func (m *Manager) Stop() {
+	m.cancel()
+}
+
+// Fail requests shutdown because the app can not go on, e.g. a dependency never came up.
+// Run then returns ExitForced.
+func (m *Manager) Fail(err error) {
+	select {
+	case m.failed <- err:
+	default:
+	}
+
+	m.cancel()
+}
+
+// Go runs fn as background worker. Its context is done after servers are drained,
+// and shutdown waits for fn to return.
+func (m *Manager) Go(name string, fn func(ctx context.Context)) {
+	m.workers.Add(1)
+	go func() {
+		defer m.workers.Done()
+		fn(m.workerCtx)
+		slog.Info("worker stopped", slog.String("worker", name))
+	}()
+}
+
+// Serve starts srv in background, unexpected stop of srv triggers shutdown.
+func (m *Manager) Serve(srv *http.Server) {
+	m.servers = append(m.servers, srv)
+
+	go func() {
+		err := srv.ListenAndServe()
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			m.Fail(err)
+		}
+	}()
+}
+
+// OnClose registers fn to be called last on shutdown, in reverse order of registration.
+func (m *Manager) OnClose(name string, fn func() error) {
+	m.closers = append(m.closers, closer{name: name, fn: fn})
+}
+
+// Run blocks until shutdown is requested then performs it.
+// It returns ExitForced when shutdown can not be done gracefully in time, or the app failed.
+func (m *Manager) Run() int {
+	<-m.ctx.Done()
+
+	code := ExitOK
+	select {
+	case err := <-m.failed:
+		slog.Error("app failed", slog.Any("error", err))
+		code = ExitForced
+	default:
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), m.timeout)
+	defer cancel()
+
+	// second signal forces the shutdown
+	go func() {
+		select {
+		case <-m.signals:
+			slog.Warn("received second signal, forcing shutdown")
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+
+	for _, srv := range m.servers {
+		err := srv.Shutdown(ctx)
+		if err != nil {
+			slog.Error("failed drain server", slog.String("addr", srv.Addr), slog.Any("error", err))
+			srv.Close()
+			code = ExitForced
+		}
+	}
+
+	m.workerCancel()
+
+	done := make(chan struct{})
+	go func() {
+		m.workers.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		slog.Error("background workers did not stop in time")
+		code = ExitForced
+	}
+
+	for i := len(m.closers) - 1; i >= 0; i-- {
+		c := m.closers[i]
+		err := c.fn()
+		if err != nil {
+			slog.Error("failed close", slog.String("resource", c.name), slog.Any("error", err))
+		}
+	}
+
+	signal.Stop(m.signals)
+
+	if code == ExitForced {
+		slog.Error("shutdown was forced")
+	} else {
+		slog.Info("shutdown completed")
+	}
+
+	return code
+}
@@ -0,0 +1,72 @@
+package commonlifecycle_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	commonlifecycle "waizlytest/common/lifecycle"
+)
+
+func TestManager_Run(t *testing.T) {
+	type scenario struct {
+		name     string
+		worker   func(ctx context.Context)
+		fail     error
+		expected int
+	}
+
+	scenarios := []scenario{
+		{
+			name: "[OK] Worker stops in time",
+			worker: func(ctx context.Context) {
+				<-ctx.Done()
+			},
+			expected: commonlifecycle.ExitOK,
+		},
+		{
+			name: "[Failed] App failed",
+			worker: func(ctx context.Context) {
+				<-ctx.Done()
+			},
+			fail:     errors.New("DB is unavailable"),
+			expected: commonlifecycle.ExitForced,
+		},
+		{
+			name: "[Failed] Worker ignores shutdown",
+			worker: func(ctx context.Context) {
+				time.Sleep(time.Second)
+			},
+			expected: commonlifecycle.ExitForced,
+		},
+	}
+
+	for _, scn := range scenarios {
+		t.Run(scn.name, func(t *testing.T) {
+			m := commonlifecycle.New(50 * time.Millisecond)
+
+			closed := false
+			m.OnClose("resource", func() error {
+				closed = true
+				return nil
+			})
+
+			m.Go("worker", scn.worker)
+			if scn.fail != nil {
+				m.Fail(scn.fail)
+			} else {
+				m.Stop()
+			}
+
+			code := m.Run()
+			if code != scn.expected {
+				t.Errorf("exit code mismatch: want %d, got %d", scn.expected, code)
+			}
+
+			if !closed {
+				t.Errorf("closer was not called")
+			}
+		})
+	}
+}
@@ -0,0 +1,146 @@
+package commonlog
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"strings"
+	"sync"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"go.opentelemetry.io/otel/trace"
+
+	"waizlytest/common/contextkey"
+)
+
+// Supported formats
+const (
+	FormatJSON = "json"
+	FormatText = "text"
+)
+
+const redacted = "[REDACTED]"
+
+// RedactedKeys are attribute & JSON body keys whose values never reach the logs.
+var RedactedKeys = []string{"password", "phone", "email", "token", "code"}
+
+type Config struct {
+	// Level is one of debug, info, warn or error. Empty value falls back to info.
+	Level string
+	// Format is FormatJSON or FormatText. Empty value falls back to FormatJSON.
+	Format string
+}
+
+// New creates logger which redacts RedactedKeys and decorates every record with
+// request scoped fields found on the context, see ContextHandler.
+func New(w io.Writer, cfg Config) *slog.Logger {
+	var level slog.Level
+	err := level.UnmarshalText([]byte(cfg.Level))
+	if err != nil {
+		level = slog.LevelInfo
+	}
+
+	opts := &slog.HandlerOptions{
+		Level: level,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if isRedacted(a.Key) {
+				return slog.String(a.Key, redacted)
+			}
+
+			return a
+		},
+	}
+
+	var h slog.Handler
+	if cfg.Format == FormatText {
+		h = slog.NewTextHandler(w, opts)
+	} else {
+		h = slog.NewJSONHandler(w, opts)
+	}
+
+	return slog.New(&ContextHandler{Handler: h})
+}
+
+func isRedacted(key string) bool {
+	for _, k := range RedactedKeys {
+		if strings.EqualFold(k, key) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ContextHandler adds request ID, user ID, route and trace ID found on the context to every record.
+type ContextHandler struct {
+	slog.Handler
+}
+
+func (h *ContextHandler) Handle(ctx context.Context, r slog.Record) error {
+	if reqID := middleware.GetReqID(ctx); reqID != "" {
+		r.AddAttrs(slog.String("request_id", reqID))
+	}
+
+	if f := fieldsFrom(ctx); f != nil {
+		if id, ok := f.userID(); ok {
+			r.AddAttrs(slog.Int64("user_id", id))
+		}
+	}
+
+	if rctx := chi.RouteContext(ctx); rctx != nil && rctx.RoutePattern() != "" {
+		r.AddAttrs(slog.String("route", rctx.RoutePattern()))
+	}
+
+	if sc := trace.SpanContextFromContext(ctx); sc.HasTraceID() {
+		r.AddAttrs(slog.String("trace_id", sc.TraceID().String()))
+	}
+
+	return h.Handler.Handle(ctx, r)
+}
+
+func (h *ContextHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &ContextHandler{Handler: h.Handler.WithAttrs(attrs)}
+}
+
+func (h *ContextHandler) WithGroup(name string) slog.Handler {
+	return &ContextHandler{Handler: h.Handler.WithGroup(name)}
+}
+
+// requestFields is mutable holder of fields only known deeper in the handler chain (e.g user ID),
+// so outer middleware like request logger can still see them.
+type requestFields struct {
+	mu    sync.RWMutex
+	id    int64
+	hasID bool
+}
+
+func (f *requestFields) userID() (int64, bool) {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	return f.id, f.hasID
+}
+
+// WithRequestFields prepares ctx to carry fields set later by SetUserID.
+func WithRequestFields(ctx context.Context) context.Context {
+	return context.WithValue(ctx, contextkey.LogFieldsContextKey, &requestFields{})
+}
+
+// SetUserID makes every following log line of the request carry the user ID.
+func SetUserID(ctx context.Context, id int64) {
+	f := fieldsFrom(ctx)
+	if f == nil {
+		return
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.id, f.hasID = id, true
+}
+
+func fieldsFrom(ctx context.Context) *requestFields {
+	f, _ := ctx.Value(contextkey.LogFieldsContextKey).(*requestFields)
+	return f
+}
@@ -0,0 +1,40 @@
+package commonlog
+
+import "encoding/json"
+
+// RedactJSON replaces values of RedactedKeys, at any depth, of JSON body.
+// Body which is not a valid JSON is dropped entirely, since it can not be inspected.
+func RedactJSON(body []byte) string {
+	var v any
+	err := json.Unmarshal(body, &v)
+	if err != nil {
+		return redacted
+	}
+
+	b, err := json.Marshal(redact(v))
+	if err != nil {
+		return redacted
+	}
+
+	return string(b)
+}
+
+func redact(v any) any {
+	switch vv := v.(type) {
+	case map[string]any:
+		for k, val := range vv {
+			if isRedacted(k) {
+				vv[k] = redacted
+				continue
+			}
+
+			vv[k] = redact(val)
+		}
+	case []any:
+		for i, val := range vv {
+			vv[i] = redact(val)
+		}
+	}
+
+	return v
+}
@@ -0,0 +1,47 @@
+package commonlog_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	commonlog "waizlytest/common/log"
+)
+
+func TestRedactJSON(t *testing.T) {
+	scenarios := []struct {
+		name     string
+		body     string
+		expected string
+	}{
+		{
+			name:     "[OK] Top level",
+			body:     `{"fullname":"Teest","password":"123123","phone":"0812"}`,
+			expected: `{"fullname":"Teest","password":"[REDACTED]","phone":"[REDACTED]"}`,
+		},
+		{
+			name:     "[OK] Nested",
+			body:     `{"users":[{"Phone":"0812"}]}`,
+			expected: `{"users":[{"Phone":"[REDACTED]"}]}`,
+		},
+		{
+			name:     "[OK] OTP code",
+			body:     `{"challenge_id":"abc","code":"123456"}`,
+			expected: `{"challenge_id":"abc","code":"[REDACTED]"}`,
+		},
+		{
+			name:     "[OK] Not a JSON",
+			body:     `phone=0812`,
+			expected: `[REDACTED]`,
+		},
+	}
+
+	for _, scn := range scenarios {
+		t.Run(scn.name, func(t *testing.T) {
+			diff := cmp.Diff(scn.expected, commonlog.RedactJSON([]byte(scn.body)))
+			if diff != "" {
+				t.Errorf("body mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
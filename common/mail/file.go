@@ -0,0 +1,66 @@
+package commonmail
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+var _ Mailer = (*FileMailer)(nil)
+
+// FileMailer writes every message as `.eml` file into a directory instead of sending it,
+// meant for local development & tests.
+type FileMailer struct {
+	dir  string
+	from string
+	seq  atomic.Int64
+}
+
+func NewFileMailer(dir, from string) (*FileMailer, error) {
+	err := os.MkdirAll(dir, 0o755)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FileMailer{
+		dir:  dir,
+		from: from,
+	}, nil
+}
+
+func (m *FileMailer) Send(ctx context.Context, msg Message) error {
+	now := time.Now()
+	name := fmt.Sprintf("%s-%d-%s.eml", now.Format("20060102T150405"), m.seq.Add(1), sanitize(msg.To))
+
+	return os.WriteFile(filepath.Join(m.dir, name), compose(m.from, msg, now), 0o644)
+}
+
+func sanitize(s string) string {
+	return strings.Map(func(r rune) rune {
+		if r == '/' || r == '\\' || r == os.PathSeparator {
+			return '_'
+		}
+
+		return r
+	}, s)
+}
+
+// compose builds RFC 5322 message of msg
+func compose(from string, msg Message, now time.Time) []byte {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", msg.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", msg.Subject)
+	fmt.Fprintf(&b, "Date: %s\r\n", now.Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(msg.Body, "\n", "\r\n"))
+
+	return []byte(b.String())
+}
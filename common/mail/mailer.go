@@ -0,0 +1,30 @@
+package commonmail
+
+import (
+	"context"
+	"net/mail"
+	"strings"
+)
+
+// Message is plain text email
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers Message, implementations are FileMailer & SMTPMailer.
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// NormalizeAddress returns bare, lower-cased address of s,
+// so every form of the same address is stored & compared equally.
+func NormalizeAddress(s string) (string, error) {
+	addr, err := mail.ParseAddress(strings.TrimSpace(s))
+	if err != nil {
+		return "", err
+	}
+
+	return strings.ToLower(addr.Address), nil
+}
@@ -0,0 +1,34 @@
+package commonmail
+
+import (
+	"context"
+	"net/mail"
+	"net/smtp"
+	"time"
+)
+
+var _ Mailer = (*SMTPMailer)(nil)
+
+// SMTPMailer sends message to SMTP server without authentication,
+// e.g a local sink such as MailHog or Mailpit.
+type SMTPMailer struct {
+	addr string
+	from string
+}
+
+func NewSMTPMailer(addr, from string) *SMTPMailer {
+	return &SMTPMailer{
+		addr: addr,
+		from: from,
+	}
+}
+
+func (m *SMTPMailer) Send(ctx context.Context, msg Message) error {
+	// envelope wants bare address, `from` may have display name
+	sender, err := mail.ParseAddress(m.from)
+	if err != nil {
+		return err
+	}
+
+	return smtp.SendMail(m.addr, nil, sender.Address, []string{msg.To}, compose(m.from, msg, time.Now()))
+}
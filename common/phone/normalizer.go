@@ -0,0 +1,43 @@
+package commonphone
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/nyaruka/phonenumbers"
+)
+
+// ErrInvalid is returned for input that is not a valid phone number
+var ErrInvalid = errors.New("invalid phone number")
+
+// Normalizer formats phone numbers to E.164, e.g `0812-3456-7890` of region `ID`
+// becomes `+6281234567890`, so every form of the same number is stored & compared equally.
+type Normalizer struct {
+	region string
+}
+
+// NewNormalizer creates Normalizer, region is ISO 3166-1 alpha-2 code used for
+// numbers written without country code.
+func NewNormalizer(region string) (*Normalizer, error) {
+	if phonenumbers.GetCountryCodeForRegion(region) == 0 {
+		return nil, fmt.Errorf("unsupported phone region %q", region)
+	}
+
+	return &Normalizer{
+		region: region,
+	}, nil
+}
+
+// Normalize returns raw in E.164 format or ErrInvalid.
+func (n *Normalizer) Normalize(raw string) (string, error) {
+	num, err := phonenumbers.Parse(raw, n.region)
+	if err != nil {
+		return "", ErrInvalid
+	}
+
+	if !phonenumbers.IsValidNumber(num) {
+		return "", ErrInvalid
+	}
+
+	return phonenumbers.Format(num, phonenumbers.E164), nil
+}
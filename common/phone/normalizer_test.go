@@ -0,0 +1,41 @@
+package commonphone_test
+
+import (
+	"testing"
+
+	commonphone "waizlytest/common/phone"
+)
+
+func TestNormalizer_Normalize(t *testing.T) {
+	n, err := commonphone.NewNormalizer("ID")
+	if err != nil {
+		t.Fatalf("failed instantiate normalizer: %v", err)
+	}
+
+	scenarios := []struct {
+		name     string
+		raw      string
+		expected string
+		err      error
+	}{
+		{name: "[OK] Local", raw: "081234567890", expected: "+6281234567890"},
+		{name: "[OK] Local with separators", raw: "0812-3456-7890", expected: "+6281234567890"},
+		{name: "[OK] International", raw: "+62 812 3456 7890", expected: "+6281234567890"},
+		{name: "[OK] Other region", raw: "+14155552671", expected: "+14155552671"},
+		{name: "[Failed] Too short", raw: "123123", err: commonphone.ErrInvalid},
+		{name: "[Failed] Garbage", raw: "phone", err: commonphone.ErrInvalid},
+	}
+
+	for _, scn := range scenarios {
+		t.Run(scn.name, func(t *testing.T) {
+			got, err := n.Normalize(scn.raw)
+			if err != scn.err {
+				t.Fatalf("err mismatch: want %v, got %v", scn.err, err)
+			}
+
+			if got != scn.expected {
+				t.Errorf("phone mismatch: want %q, got %q", scn.expected, got)
+			}
+		})
+	}
+}
@@ -0,0 +1,64 @@
+package commonratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// Limiter allows up to limit hits per key within a fixed window.
+// State is kept in memory, so each instance of the app limits on its own.
+type Limiter struct {
+	mu     sync.Mutex
+	limit  int
+	window time.Duration
+	hits   map[string]*counter
+	swept  time.Time
+	now    func() time.Time
+}
+
+type counter struct {
+	n     int
+	reset time.Time
+}
+
+func New(limit int, window time.Duration) *Limiter {
+	return &Limiter{
+		limit:  limit,
+		window: window,
+		hits:   map[string]*counter{},
+		now:    time.Now,
+	}
+}
+
+// Allow counts a hit of key, it reports false once key is over the limit of the current window.
+func (l *Limiter) Allow(key string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.sweep(now)
+
+	c, ok := l.hits[key]
+	if !ok || !now.Before(c.reset) {
+		c = &counter{reset: now.Add(l.window)}
+		l.hits[key] = c
+	}
+
+	c.n++
+	return c.n <= l.limit
+}
+
+// sweep drops expired counters once per window, so memory is bounded by keys of one window.
+func (l *Limiter) sweep(now time.Time) {
+	if now.Sub(l.swept) < l.window {
+		return
+	}
+
+	for k, c := range l.hits {
+		if !now.Before(c.reset) {
+			delete(l.hits, k)
+		}
+	}
+
+	l.swept = now
+}
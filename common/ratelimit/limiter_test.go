@@ -0,0 +1,71 @@
+package commonratelimit_test
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	commonratelimit "waizlytest/common/ratelimit"
+)
+
+func TestLimiter_Allow(t *testing.T) {
+	scenarios := []struct {
+		name     string
+		limit    int
+		hits     int
+		wait     time.Duration
+		expected bool
+	}{
+		{name: "[OK] First hit", limit: 3, expected: true},
+		{name: "[OK] Last hit within limit", limit: 3, hits: 2, expected: true},
+		{name: "[OK] Window passed", limit: 3, hits: 5, wait: 60 * time.Millisecond, expected: true},
+		{name: "[Failed] Over limit", limit: 3, hits: 3},
+		{name: "[Failed] Zero limit", limit: 0},
+	}
+
+	for _, scn := range scenarios {
+		t.Run(scn.name, func(t *testing.T) {
+			l := commonratelimit.New(scn.limit, 20*time.Millisecond)
+			for i := 0; i < scn.hits; i++ {
+				l.Allow("key")
+			}
+
+			time.Sleep(scn.wait)
+
+			if got := l.Allow("key"); got != scn.expected {
+				t.Errorf("expected %v, got %v", scn.expected, got)
+			}
+
+			if scn.limit > 0 && !l.Allow("other") {
+				t.Errorf("expected other key counted on its own")
+			}
+		})
+	}
+}
+
+func TestLimiter_Allow_Concurrent(t *testing.T) {
+	l := commonratelimit.New(10, time.Minute)
+
+	var allowed atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			if l.Allow("key") {
+				allowed.Add(1)
+			}
+
+			l.Allow(fmt.Sprintf("key-%d", i))
+		}(i)
+	}
+
+	wg.Wait()
+
+	if n := allowed.Load(); n != 10 {
+		t.Errorf("expected 10 hits allowed, got %d", n)
+	}
+}
@@ -0,0 +1,80 @@
+package commonretry
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"time"
+)
+
+// Backoff is exponential backoff policy with jitter.
+type Backoff struct {
+	// Initial is delay after first failed attempt, zero falls back to one second.
+	Initial time.Duration
+	// Max caps the delay, zero means no cap.
+	Max time.Duration
+	// Multiplier grows the delay on every attempt, less than 1 falls back to 2.
+	Multiplier float64
+	// Jitter is fraction of the delay randomised, e.g 0.2 makes delay vary by ±20%.
+	Jitter float64
+	// MaxAttempts bounds the attempts, zero means retry until context is done.
+	MaxAttempts int
+}
+
+// Delay returns how long to wait after given failed attempt, attempt starts from 1.
+func (b Backoff) Delay(attempt int) time.Duration {
+	multiplier := b.Multiplier
+	if multiplier < 1 {
+		multiplier = 2
+	}
+
+	initial := b.Initial
+	if initial <= 0 {
+		initial = time.Second
+	}
+
+	d := float64(initial) * math.Pow(multiplier, float64(attempt-1))
+	if b.Max > 0 && d > float64(b.Max) {
+		d = float64(b.Max)
+	}
+
+	if b.Jitter > 0 {
+		d += d * b.Jitter * (rand.Float64()*2 - 1)
+	}
+
+	return time.Duration(d)
+}
+
+// Do calls fn until it succeeds, attempts are exhausted or ctx is done.
+// onRetry is optional and called before waiting for the next attempt.
+// The last error of fn is returned when it gives up.
+func Do(
+	ctx context.Context,
+	b Backoff,
+	fn func(ctx context.Context) error,
+	onRetry func(attempt int, delay time.Duration, err error),
+) error {
+	for attempt := 1; ; attempt++ {
+		err := fn(ctx)
+		if err == nil {
+			return nil
+		}
+
+		if b.MaxAttempts > 0 && attempt >= b.MaxAttempts {
+			return err
+		}
+
+		delay := b.Delay(attempt)
+		if onRetry != nil {
+			onRetry(attempt, delay, err)
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return err
+		case <-timer.C:
+		}
+	}
+}
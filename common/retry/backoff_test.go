@@ -0,0 +1,76 @@
+package commonretry_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	commonretry "waizlytest/common/retry"
+)
+
+func TestBackoff_Delay(t *testing.T) {
+	b := commonretry.Backoff{
+		Initial: 100 * time.Millisecond,
+		Max:     time.Second,
+		Jitter:  0.2,
+	}
+
+	scenarios := []struct {
+		attempt  int
+		expected time.Duration
+	}{
+		{attempt: 1, expected: 100 * time.Millisecond},
+		{attempt: 2, expected: 200 * time.Millisecond},
+		{attempt: 4, expected: 800 * time.Millisecond},
+		{attempt: 10, expected: time.Second},
+	}
+
+	for _, scn := range scenarios {
+		for i := 0; i < 100; i++ {
+			got := b.Delay(scn.attempt)
+
+			low := time.Duration(float64(scn.expected) * 0.8)
+			high := time.Duration(float64(scn.expected) * 1.2)
+			if got < low || got > high {
+				t.Fatalf("attempt %d: delay %s is out of [%s, %s]", scn.attempt, got, low, high)
+			}
+		}
+	}
+}
+
+func TestDo(t *testing.T) {
+	b := commonretry.Backoff{
+		Initial:     time.Millisecond,
+		MaxAttempts: 3,
+	}
+
+	t.Run("[OK] Succeed after retry", func(t *testing.T) {
+		calls := 0
+		err := commonretry.Do(context.TODO(), b, func(ctx context.Context) error {
+			calls++
+			if calls < 2 {
+				return errors.New("not yet")
+			}
+
+			return nil
+		}, nil)
+		if err != nil || calls != 2 {
+			t.Errorf("expected success on 2nd call, got %d calls, err %v", calls, err)
+		}
+	})
+
+	t.Run("[Failed] Attempts exhausted", func(t *testing.T) {
+		calls := 0
+		retries := 0
+		err := commonretry.Do(context.TODO(), b, func(ctx context.Context) error {
+			calls++
+			return errors.New("down")
+		}, func(attempt int, delay time.Duration, err error) {
+			retries++
+		})
+		if err == nil || calls != 3 || retries != 2 {
+			t.Errorf("expected 3 calls & 2 retries, got %d calls, %d retries, err %v", calls, retries, err)
+		}
+	})
+}
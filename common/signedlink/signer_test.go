@@ -0,0 +1,54 @@
+package commonsignedlink_test
+
+import (
+	"testing"
+	"time"
+
+	commonsignedlink "waizlytest/common/signedlink"
+)
+
+func TestSigner_Verify(t *testing.T) {
+	signer, err := commonsignedlink.New("0123456789abcdef", time.Hour)
+	if err != nil {
+		t.Fatalf("failed instantiate signer: %v", err)
+	}
+
+	expired, err := commonsignedlink.New("0123456789abcdef", -time.Minute)
+	if err != nil {
+		t.Fatalf("failed instantiate signer: %v", err)
+	}
+
+	other, err := commonsignedlink.New("fedcba9876543210", time.Hour)
+	if err != nil {
+		t.Fatalf("failed instantiate signer: %v", err)
+	}
+
+	token := signer.Sign("test", "1:a@b.c")
+
+	scenarios := []struct {
+		name    string
+		purpose string
+		token   string
+		err     error
+	}{
+		{name: "[OK] Valid", purpose: "test", token: token},
+		{name: "[Failed] Other purpose", purpose: "other", token: token, err: commonsignedlink.ErrInvalid},
+		{name: "[Failed] Tampered", purpose: "test", token: "x" + token, err: commonsignedlink.ErrInvalid},
+		{name: "[Failed] Other secret", purpose: "test", token: other.Sign("test", "1:a@b.c"), err: commonsignedlink.ErrInvalid},
+		{name: "[Failed] Expired", purpose: "test", token: expired.Sign("test", "1:a@b.c"), err: commonsignedlink.ErrExpired},
+		{name: "[Failed] Garbage", purpose: "test", token: "garbage", err: commonsignedlink.ErrInvalid},
+	}
+
+	for _, scn := range scenarios {
+		t.Run(scn.name, func(t *testing.T) {
+			subject, err := signer.Verify(scn.purpose, scn.token)
+			if err != scn.err {
+				t.Fatalf("err mismatch: want %v, got %v", scn.err, err)
+			}
+
+			if err == nil && subject != "1:a@b.c" {
+				t.Errorf("subject mismatch: got %q", subject)
+			}
+		})
+	}
+}
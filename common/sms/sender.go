@@ -0,0 +1,43 @@
+package commonsms
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// Sender delivers text message to phone in E.164
+type Sender interface {
+	Send(ctx context.Context, to, text string) error
+}
+
+var _ Sender = (*FileSender)(nil)
+
+// FileSender appends every message to a file instead of sending it,
+// meant for local development & tests until an SMS gateway is integrated.
+type FileSender struct {
+	mu   sync.Mutex
+	path string
+}
+
+func NewFileSender(path string) *FileSender {
+	return &FileSender{
+		path: path,
+	}
+}
+
+func (s *FileSender) Send(ctx context.Context, to, text string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	f, err := os.OpenFile(s.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = fmt.Fprintf(f, "%s\t%s\t%q\n", time.Now().Format(time.RFC3339), to, text)
+	return err
+}
@@ -0,0 +1,114 @@
+package commontracing
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+// Supported exporters
+const (
+	ExporterNone   = "none"
+	ExporterStdout = "stdout"
+	ExporterFile   = "file"
+	ExporterOTLP   = "otlp"
+)
+
+type Config struct {
+	// Exporter is one of ExporterNone, ExporterStdout, ExporterFile or ExporterOTLP.
+	// Empty value falls back to ExporterNone.
+	Exporter string
+	// File is used by ExporterFile, spans are appended as JSON lines.
+	File string
+	// Endpoint is host:port of OTLP/HTTP collector, used by ExporterOTLP.
+	Endpoint string
+	Insecure bool
+
+	ServiceName string
+	// SampleRatio of the root spans, remote parent decision is always respected.
+	SampleRatio float64
+}
+
+// Setup installs global tracer provider & W3C trace-context propagator.
+// Returned shutdown flushes pending spans.
+func Setup(ctx context.Context, cfg Config) (func(ctx context.Context) error, error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+		propagation.TraceContext{},
+		propagation.Baggage{},
+	))
+
+	exporter, closeFn, err := newExporter(ctx, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	if exporter == nil {
+		return func(ctx context.Context) error { return nil }, nil
+	}
+
+	res, err := resource.Merge(resource.Default(), resource.NewWithAttributes(
+		semconv.SchemaURL,
+		semconv.ServiceName(cfg.ServiceName),
+	))
+	if err != nil {
+		return nil, err
+	}
+
+	tp := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	)
+	otel.SetTracerProvider(tp)
+
+	return func(ctx context.Context) error {
+		err := tp.Shutdown(ctx)
+		if closeFn != nil {
+			closeFn()
+		}
+
+		return err
+	}, nil
+}
+
+func newExporter(ctx context.Context, cfg Config) (sdktrace.SpanExporter, func(), error) {
+	switch cfg.Exporter {
+	case "", ExporterNone:
+		return nil, nil, nil
+	case ExporterStdout:
+		exp, err := stdouttrace.New(stdouttrace.WithWriter(os.Stdout), stdouttrace.WithPrettyPrint())
+		return exp, nil, err
+	case ExporterFile:
+		f, err := os.OpenFile(cfg.File, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		exp, err := stdouttrace.New(stdouttrace.WithWriter(io.Writer(f)))
+		if err != nil {
+			f.Close()
+			return nil, nil, err
+		}
+
+		return exp, func() { f.Close() }, nil
+	case ExporterOTLP:
+		opts := []otlptracehttp.Option{otlptracehttp.WithEndpoint(cfg.Endpoint)}
+		if cfg.Insecure {
+			opts = append(opts, otlptracehttp.WithInsecure())
+		}
+
+		exp, err := otlptracehttp.New(ctx, opts...)
+		return exp, nil, err
+	default:
+		return nil, nil, fmt.Errorf("unsupported tracing exporter: %s", cfg.Exporter)
+	}
+}
@@ -0,0 +1,178 @@
+package commonvalidation
+
+import (
+	"fmt"
+	"net/mail"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	commonerr "waizlytest/common/errors"
+	commoni18n "waizlytest/common/i18n"
+)
+
+// rule checks value of a field, param is the part after `=` of the tag, e.g `max=255`.
+// Nil pointers only reach `required`, the rest of rules treat them as absent.
+type rule struct {
+	key   string
+	check func(v reflect.Value, param int) bool
+}
+
+var rules = map[string]rule{
+	"required": {key: "validation.required", check: required},
+	"notblank": {key: "validation.not_blank", check: required},
+	"email":    {key: "validation.email", check: email},
+	"min":      {key: "validation.min", check: func(v reflect.Value, n int) bool { return size(v) >= n }},
+	"max":      {key: "validation.max", check: func(v reflect.Value, n int) bool { return size(v) <= n }},
+	"phone":    {key: "validation.phone", check: phone},
+}
+
+// Validate checks struct (or pointer to it) v by `validate` tags of its fields, e.g
+//
+//	FullName string `json:"fullname" validate:"required,max=255"`
+//	Email    string `json:"email" validate:"omitempty,email"`
+//
+// `omitempty` skips the rest of rules when the field is empty. `notblank` is `required` of optional
+// pointer fields, absent (nil) passes while present empty or whitespace value fails.
+// Every field is checked, the first failing rule of each field is reported
+// as field of `commonerr.DefInvalidRequest`, named by its `json` tag. It returns nil when v is valid.
+func Validate(v any) error {
+	rv := reflect.Indirect(reflect.ValueOf(v))
+	if rv.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("commonvalidation: %T is not a struct", v))
+	}
+
+	var e *commonerr.Error
+
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		tag := sf.Tag.Get("validate")
+		if tag == "" || !sf.IsExported() {
+			continue
+		}
+
+		key, args, ok := check(rv.Field(i), tag)
+		if ok {
+			continue
+		}
+
+		if e == nil {
+			e = commonerr.DefInvalidRequest.New()
+		}
+
+		english, _ := commoni18n.Default().Message(commoni18n.Fallback, key, args...)
+		e.AddTranslatableField(fieldName(sf), english, key, args...)
+	}
+
+	if e == nil {
+		return nil
+	}
+
+	return e
+}
+
+// check runs rules of tag in order, returning key & param of the first failing one.
+func check(v reflect.Value, tag string) (string, []any, bool) {
+	for _, r := range strings.Split(tag, ",") {
+		name, param, _ := strings.Cut(strings.TrimSpace(r), "=")
+		if name == "omitempty" {
+			if !required(v, 0) {
+				break
+			}
+
+			continue
+		}
+
+		rl, ok := rules[name]
+		if !ok {
+			panic(fmt.Sprintf("commonvalidation: unknown rule %q", name))
+		}
+
+		var args []any
+		n := 0
+		if param != "" {
+			var err error
+			n, err = strconv.Atoi(param)
+			if err != nil {
+				panic(fmt.Sprintf("commonvalidation: rule %q wants number, got %q", name, param))
+			}
+
+			args = append(args, n)
+		}
+
+		if name != "required" {
+			if v.Kind() == reflect.Pointer && v.IsNil() {
+				continue
+			}
+
+			v = reflect.Indirect(v)
+		}
+
+		if !rl.check(v, n) {
+			return rl.key, args, false
+		}
+	}
+
+	return "", nil, true
+}
+
+func fieldName(sf reflect.StructField) string {
+	name, _, _ := strings.Cut(sf.Tag.Get("json"), ",")
+	if name == "" || name == "-" {
+		return sf.Name
+	}
+
+	return name
+}
+
+func required(v reflect.Value, _ int) bool {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return false
+		}
+
+		v = v.Elem()
+	}
+
+	if v.Kind() == reflect.String {
+		return strings.TrimSpace(v.String()) != ""
+	}
+
+	return !v.IsZero()
+}
+
+// size is length of string in characters
+func size(v reflect.Value) int {
+	if v.Kind() != reflect.String {
+		panic(fmt.Sprintf("commonvalidation: size of %s is not supported", v.Kind()))
+	}
+
+	return utf8.RuneCountInString(v.String())
+}
+
+// phone only checks the shape, optional leading `+` then up to 15 digits as of E.164,
+// allowing common separators. Services normalize the number, see `commonphone.Normalizer`.
+func phone(v reflect.Value, _ int) bool {
+	s := strings.TrimPrefix(strings.TrimSpace(v.String()), "+")
+
+	digits := 0
+	for _, r := range s {
+		switch {
+		case r >= '0' && r <= '9':
+			digits++
+		case strings.ContainsRune(" -.()", r):
+		default:
+			return false
+		}
+	}
+
+	return digits >= 5 && digits <= 15
+}
+
+// email accepts bare address, without display name
+func email(v reflect.Value, _ int) bool {
+	addr, err := mail.ParseAddress(v.String())
+	return err == nil && addr.Address == v.String()
+}
@@ -0,0 +1,164 @@
+package commonvalidation_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+
+	commonerr "waizlytest/common/errors"
+	commonvalidation "waizlytest/common/validation"
+)
+
+func ptr(s string) *string {
+	return &s
+}
+
+type request struct {
+	FullName string  `json:"fullname" validate:"required,max=5"`
+	Email    string  `json:"email,omitempty" validate:"omitempty,email"`
+	Phone    string  `validate:"omitempty,phone"`
+	Nickname *string `json:"nickname" validate:"notblank,min=2,max=5"`
+	Bio      *string `json:"bio" validate:"max=3"`
+	Token    *string `json:"token" validate:"required"`
+	Ignored  string  `json:"ignored"`
+}
+
+func TestValidate(t *testing.T) {
+	valid := func() request {
+		return request{FullName: "Teest", Token: ptr("t")}
+	}
+
+	scenarios := []struct {
+		name     string
+		req      func() request
+		expected map[string]string
+	}{
+		{
+			name: "[OK] Valid",
+			req:  valid,
+		},
+		{
+			name: "[OK] Omitempty skips empty",
+			req: func() request {
+				r := valid()
+				r.Email = ""
+				r.Phone = "   "
+				return r
+			},
+		},
+		{
+			name: "[OK] Nil pointer is absent",
+			req: func() request {
+				r := valid()
+				r.Nickname, r.Bio = nil, nil
+				return r
+			},
+		},
+		{
+			name: "[OK] Present pointer",
+			req: func() request {
+				r := valid()
+				r.Nickname, r.Bio = ptr("Tes"), ptr("")
+				return r
+			},
+		},
+		{
+			name: "[Failed] Omitempty checks present",
+			req: func() request {
+				r := valid()
+				r.Email = "not an email"
+				r.Phone = "08abc"
+				return r
+			},
+			expected: map[string]string{"email": "must be a valid email address", "Phone": "must be a valid phone number"},
+		},
+		{
+			name: "[Failed] Present blank pointer",
+			req: func() request {
+				r := valid()
+				r.Nickname = ptr("  ")
+				return r
+			},
+			expected: map[string]string{"nickname": "must not be blank"},
+		},
+		{
+			name: "[Failed] Present empty pointer",
+			req: func() request {
+				r := valid()
+				r.Nickname = ptr("")
+				return r
+			},
+			expected: map[string]string{"nickname": "must not be blank"},
+		},
+		{
+			name: "[Failed] Pointer value checked",
+			req: func() request {
+				r := valid()
+				r.Nickname, r.Bio = ptr("Teeeest"), ptr("ééééé")
+				return r
+			},
+			expected: map[string]string{"nickname": "must be at most 5 characters", "bio": "must be at most 3 characters"},
+		},
+		{
+			name: "[Failed] Every field, first failing rule",
+			req: func() request {
+				return request{FullName: "  "}
+			},
+			expected: map[string]string{"fullname": "is required", "token": "is required"},
+		},
+	}
+
+	for _, scn := range scenarios {
+		t.Run(scn.name, func(t *testing.T) {
+			r := scn.req()
+			err := commonvalidation.Validate(&r)
+			if scn.expected == nil {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+
+				return
+			}
+
+			e, ok := err.(*commonerr.Error)
+			if !ok || !commonerr.DefInvalidRequest.Is(e) {
+				t.Fatalf("expected invalid request, got %v", err)
+			}
+
+			diff := cmp.Diff(scn.expected, map[string]string(e.Fields))
+			if diff != "" {
+				t.Errorf("fields mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestValidate_Panic(t *testing.T) {
+	scenarios := []struct {
+		name string
+		v    any
+	}{
+		{name: "[Failed] Unknown rule", v: struct {
+			Name string `validate:"required,nope"`
+		}{Name: "a"}},
+		{name: "[Failed] Param is not a number", v: struct {
+			Name string `validate:"max=abc"`
+		}{}},
+		{name: "[Failed] Size of non string", v: struct {
+			Age int `validate:"max=3"`
+		}{}},
+		{name: "[Failed] Not a struct", v: "request"},
+	}
+
+	for _, scn := range scenarios {
+		t.Run(scn.name, func(t *testing.T) {
+			defer func() {
+				if recover() == nil {
+					t.Errorf("expected panic")
+				}
+			}()
+
+			commonvalidation.Validate(scn.v)
+		})
+	}
+}
@@ -2,9 +2,22 @@ package config
 
 // using driver yaml
 type Configuration struct {
-	Server ServerConfig `yaml:"Server"`
-	DB     DBConfig     `yaml:"DB"`
-	Cert   CertConfig   `yaml:"Cert"`
+	Server  ServerConfig  `yaml:"Server"`
+	DB      DBConfig      `yaml:"DB"`
+	Cert    CertConfig    `yaml:"Cert"`
+	Token   TokenConfig   `yaml:"Token"`
+	Tracing TracingConfig `yaml:"Tracing"`
+	Log     LogConfig     `yaml:"Log"`
+	Phone   PhoneConfig   `yaml:"Phone"`
+	Mail    MailConfig    `yaml:"Mail"`
+
+	EmailVerification EmailVerificationConfig `yaml:"EmailVerification"`
+	SMS               SMSConfig               `yaml:"SMS"`
+	OTP               OTPConfig               `yaml:"OTP"`
+	Passkey           PasskeyConfig           `yaml:"Passkey"`
+	Introspection     IntrospectionConfig     `yaml:"Introspection"`
+	Cookie            CookieConfig            `yaml:"Cookie"`
+	StepUp            StepUpConfig            `yaml:"StepUp"`
 }
 
 type (
@@ -15,15 +28,157 @@ type (
 		ReadTimeoutInSecond     int    `yaml:"ReadTimeout"`
 		WriteTimeoutInSecond    int    `yaml:"WriteTimeout"`
 		APITimeout              int    `yaml:"APITimeout"`
+		// TrustProxy reads client address from X-Forwarded-For & X-Real-IP headers,
+		// only enable it behind a proxy which overwrites them.
+		TrustProxy bool `yaml:"TrustProxy"`
+		// InternalPort serves /debug/vars apart from the API, keep it unreachable from outside.
+		// Empty value disables it.
+		InternalPort string `yaml:"InternalPort"`
 	}
 	DBConfig struct {
-		RetryInterval int    `yaml:"RetryInterval"`
-		MaxIdleConn   int    `yaml:"MaxIdleConn"`
-		MaxConn       int    `yaml:"MaxConn"`
-		DSN           string `yaml:"DSN"`
+		// Driver selects the storage backend, see DriverPostgres & DriverSQLite.
+		// Empty value falls back to DriverPostgres.
+		Driver string `yaml:"Driver"`
+		// RetryInterval in seconds, is the first delay of connection retry, it grows exponentially
+		// up to RetryMaxInterval. Also used as interval of connection check at runtime.
+		RetryInterval    int `yaml:"RetryInterval"`
+		RetryMaxInterval int `yaml:"RetryMaxInterval"`
+		// RetryMaxAttempts bounds connection attempts on startup, zero means retry forever.
+		RetryMaxAttempts int    `yaml:"RetryMaxAttempts"`
+		MaxIdleConn      int    `yaml:"MaxIdleConn"`
+		MaxConn          int    `yaml:"MaxConn"`
+		DSN              string `yaml:"DSN"`
+		// AutoMigrate applies pending migrations of the driver on startup, see `waizlytest migrate` to run them apart.
+		AutoMigrate bool `yaml:"AutoMigrate"`
+
+		// ReplicaDSNs are optional read replicas, only supported by DriverPostgres.
+		ReplicaDSNs []string `yaml:"ReplicaDSNs"`
+		// ReplicaHealthCheckInterval in seconds, replica failing the check is taken out of rotation.
+		ReplicaHealthCheckInterval int `yaml:"ReplicaHealthCheckInterval"`
+
+		// PgxPool makes DriverPostgres use native pgx pool instead of `database/sql`,
+		// with statements prepared & cached per connection.
+		PgxPool bool `yaml:"PgxPool"`
+		// StatementCacheCapacity is only used by PgxPool, zero keeps pgx default.
+		StatementCacheCapacity int `yaml:"StatementCacheCapacity"`
 	}
 	CertConfig struct {
 		Public  string `yaml:"Public"`
 		Private string `yaml:"Private"`
 	}
+	TokenConfig struct {
+		// Issuer & Audience are `iss` & `aud` claims of the issued tokens, required on validation.
+		// Empty value is neither issued nor validated.
+		Issuer   string `yaml:"Issuer"`
+		Audience string `yaml:"Audience"`
+		// Leeway in seconds tolerates clock skew on `exp`, `nbf` & `iat`
+		LeewayInSecond int `yaml:"Leeway"`
+		// MaxAge in seconds rejects tokens issued longer ago regardless of their expiry, zero means no limit
+		MaxAgeInSecond int `yaml:"MaxAge"`
+	}
+	LogConfig struct {
+		// Level is one of debug, info, warn or error
+		Level string `yaml:"Level"`
+		// Format is json or text
+		Format string `yaml:"Format"`
+	}
+	MailConfig struct {
+		// Driver is one of MailDriverFile or MailDriverSMTP, empty value falls back to MailDriverFile
+		Driver string `yaml:"Driver"`
+		From   string `yaml:"From"`
+		// Dir is only used by MailDriverFile
+		Dir string `yaml:"Dir"`
+		// SMTPAddr is only used by MailDriverSMTP, e.g a local sink
+		SMTPAddr string `yaml:"SMTPAddr"`
+	}
+	EmailVerificationConfig struct {
+		// URL of the verification endpoint put on the link
+		URL string `yaml:"URL"`
+		// Secret signs the links, at least 16 bytes
+		Secret      string `yaml:"Secret"`
+		TTLInMinute int    `yaml:"TTL"`
+	}
+	SMSConfig struct {
+		// Driver is SMSDriverFile, until an SMS gateway is integrated
+		Driver string `yaml:"Driver"`
+		File   string `yaml:"File"`
+	}
+	OTPConfig struct {
+		CodeTTLInSecond int `yaml:"CodeTTL"`
+		MaxAttempts     int `yaml:"MaxAttempts"`
+		// LinkURL is page of the magic link sent by email, it posts the `token` query to complete the login
+		LinkURL string `yaml:"LinkURL"`
+		// Secret keys hash of the codes & signs the links, at least 16 bytes
+		Secret string `yaml:"Secret"`
+		// StartLimit of codes sent to the same phone or email, and IPLimit of requests from the same IP, per LimitWindow
+		StartLimit          int `yaml:"StartLimit"`
+		IPLimit             int `yaml:"IPLimit"`
+		LimitWindowInSecond int `yaml:"LimitWindow"`
+	}
+	PasskeyConfig struct {
+		// RPID is domain the passkeys are bound to, RPOrigins are origins of the pages running the ceremonies
+		RPID          string   `yaml:"RPID"`
+		RPDisplayName string   `yaml:"RPDisplayName"`
+		RPOrigins     []string `yaml:"RPOrigins"`
+		// Secret signs the ceremony sessions, at least 16 bytes
+		Secret              string `yaml:"Secret"`
+		CeremonyTTLInSecond int    `yaml:"CeremonyTTL"`
+	}
+	IntrospectionConfig struct {
+		// Clients are resource servers allowed to introspect tokens, by HTTP Basic auth
+		Clients []IntrospectionClient `yaml:"Clients"`
+		// CacheTTL bounds how late a revocation is seen by the resource servers
+		CacheTTLInSecond int `yaml:"CacheTTL"`
+	}
+	CookieConfig struct {
+		// Enabled makes login set the token in HttpOnly cookie for browser clients as well
+		Enabled  bool   `yaml:"Enabled"`
+		Name     string `yaml:"Name"`
+		CSRFName string `yaml:"CSRFName"`
+		Domain   string `yaml:"Domain"`
+		Path     string `yaml:"Path"`
+		// SameSite is one of Lax, Strict or None, empty is Lax
+		SameSite string `yaml:"SameSite"`
+	}
+	StepUpConfig struct {
+		// MaxAge in seconds is how long ago the user may have logged in to reach sensitive routes
+		MaxAgeInSecond int `yaml:"MaxAge"`
+		// Methods are `amr` values one of which the login must have, e.g `mfa`, empty allows any
+		Methods []string `yaml:"Methods"`
+	}
+	IntrospectionClient struct {
+		ID string `yaml:"ID"`
+		// Secret is at least 16 bytes
+		Secret string `yaml:"Secret"`
+	}
+	PhoneConfig struct {
+		// DefaultRegion is ISO 3166-1 alpha-2 code of phones written without country code, e.g ID
+		DefaultRegion string `yaml:"DefaultRegion"`
+	}
+	TracingConfig struct {
+		// Exporter is one of none, stdout, file or otlp
+		Exporter    string  `yaml:"Exporter"`
+		File        string  `yaml:"File"`
+		Endpoint    string  `yaml:"Endpoint"`
+		Insecure    bool    `yaml:"Insecure"`
+		ServiceName string  `yaml:"ServiceName"`
+		SampleRatio float64 `yaml:"SampleRatio"`
+	}
+)
+
+// Supported values of DBConfig.Driver
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
+
+// Supported values of MailConfig.Driver
+const (
+	MailDriverFile = "file"
+	MailDriverSMTP = "smtp"
+)
+
+// Supported values of SMSConfig.Driver
+const (
+	SMSDriverFile = "file"
 )
@@ -0,0 +1,41 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	commonerr "waizlytest/common/errors"
+)
+
+// runErrors prints the error catalog, every definition is registered
+// on init of the imported services.
+//
+//	waizlytest errors [-format markdown|json]
+func runErrors(args []string) int {
+	fs := flag.NewFlagSet("errors", flag.ContinueOnError)
+	format := fs.String("format", "markdown", "output format, markdown or json")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	defs := commonerr.Catalog()
+
+	var err error
+	switch *format {
+	case "markdown", "md":
+		err = commonerr.WriteMarkdown(os.Stdout, defs)
+	case "json":
+		err = commonerr.WriteJSON(os.Stdout, defs)
+	default:
+		fmt.Fprintf(os.Stderr, "unsupported format %q\n", *format)
+		return 2
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed write catalog: %v\n", err)
+		return 1
+	}
+
+	return 0
+}
@@ -2,72 +2,300 @@ package main
 
 import (
 	"context"
-	"database/sql"
+	"expvar"
 	"fmt"
-	"log"
+	"log/slog"
 	"net/http"
 	"os"
-	"os/signal"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
-
-	_ "github.com/jackc/pgx/v5/stdlib"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 
 	"waizlytest/config"
+	"waizlytest/migration"
 
-	commonhttpmiddleware "waizlytest/common/http/middleware"
+	promrepositories "waizlytest/repositories/prom"
 
-	pgrepositories "waizlytest/repositories/pg"
+	commondb "waizlytest/common/db"
+	commonhealth "waizlytest/common/health"
+	commonhttpcookie "waizlytest/common/http/cookie"
+	commonhttpenc "waizlytest/common/http/encoder"
+	commonhttpmiddleware "waizlytest/common/http/middleware"
+	commonlifecycle "waizlytest/common/lifecycle"
+	commonlog "waizlytest/common/log"
+	commonmail "waizlytest/common/mail"
+	commonphone "waizlytest/common/phone"
+	commonratelimit "waizlytest/common/ratelimit"
+	commonretry "waizlytest/common/retry"
+	commonsignedlink "waizlytest/common/signedlink"
+	commonsms "waizlytest/common/sms"
+	commontracing "waizlytest/common/tracing"
 
+	authservice "waizlytest/services/auth"
 	v1authhttphandler "waizlytest/services/auth/httphandlers/v1"
 	jwtauthservice "waizlytest/services/auth/jwt"
+	promauthservice "waizlytest/services/auth/prom"
 
 	v1userhttphandler "waizlytest/services/user/httphandlers/v1"
 	stduserservice "waizlytest/services/user/std"
 )
 
 func main() {
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, os.Interrupt)
+	if len(os.Args) > 1 && os.Args[1] == "errors" {
+		os.Exit(runErrors(os.Args[2:]))
+	}
 
-	ctx, cancel := context.WithCancel(context.Background())
+	if len(os.Args) > 1 && os.Args[1] == "phones" {
+		os.Exit(runPhones(os.Args[2:]))
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		os.Exit(runMigrate(os.Args[2:]))
+	}
 
 	cfg, err := config.Read("conf.yaml")
 	if err != nil {
 		panic(fmt.Sprintf("failed open conf file: %v", err))
 	}
 
-	db, err := sql.Open("pgx", cfg.DB.DSN)
+	logger := commonlog.New(os.Stdout, commonlog.Config{
+		Level:  cfg.Log.Level,
+		Format: cfg.Log.Format,
+	})
+	slog.SetDefault(logger)
+
+	lc := commonlifecycle.New(time.Second * time.Duration(cfg.Server.GracefulTimeoutInSecond))
+	ctx := lc.Context()
+
+	shutdownTracing, err := commontracing.Setup(ctx, commontracing.Config{
+		Exporter:    cfg.Tracing.Exporter,
+		File:        cfg.Tracing.File,
+		Endpoint:    cfg.Tracing.Endpoint,
+		Insecure:    cfg.Tracing.Insecure,
+		ServiceName: cfg.Tracing.ServiceName,
+		SampleRatio: cfg.Tracing.SampleRatio,
+	})
 	if err != nil {
-		panic(fmt.Sprintf("failed open DB: %v", err))
+		panic(fmt.Sprintf("failed setup tracing: %v", err))
 	}
 
-	db.SetMaxOpenConns(cfg.DB.MaxConn)
-	db.SetMaxIdleConns(cfg.DB.MaxIdleConn)
+	lc.OnClose("tracing", func() error {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+
+		return shutdownTracing(ctx)
+	})
 
-	err = db.PingContext(ctx)
+	st, err := openStorage(ctx, cfg.DB)
 	if err != nil {
-		panic(fmt.Sprintf("failed established DB conn: %v", err))
+		panic(fmt.Sprintf("failed open DB: %v", err))
 	}
 
-	userStorage := pgrepositories.NewUserRepository(db)
+	lc.OnClose("db", func() error {
+		st.Close()
+		return nil
+	})
+
+	for _, w := range st.workers {
+		lc.Go("db replicas health check", w)
+	}
 
-	authService, err := jwtauthservice.NewJWTAuth(userStorage, userStorage, cfg.Cert.Private, cfg.Cert.Public)
+	dbMonitor := commondb.NewMonitor(st.ping, commonretry.Backoff{
+		Initial:     time.Second * time.Duration(cfg.DB.RetryInterval),
+		Max:         time.Second * time.Duration(cfg.DB.RetryMaxInterval),
+		Jitter:      0.2,
+		MaxAttempts: cfg.DB.RetryMaxAttempts,
+	})
+	expvar.Publish("db_state", expvar.Func(func() any { return dbMonitor.State().String() }))
+
+	// connect in background, so liveness answers while DB is retried and readiness tells why it is not ready
+	lc.Go("db monitor", func(ctx context.Context) {
+		err := dbMonitor.Connect(ctx)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+
+			lc.Fail(fmt.Errorf("failed established DB conn: %w", err))
+			return
+		}
+
+		if cfg.DB.AutoMigrate {
+			applied, err := migration.Up(ctx, st.sqlDB, st.dialect)
+			if err != nil {
+				lc.Fail(fmt.Errorf("failed migrate DB: %w", err))
+				return
+			}
+
+			logger.Info("DB migrated", slog.Any("applied", applied))
+		}
+
+		dbMonitor.Watch(ctx, time.Second*time.Duration(cfg.DB.RetryInterval))
+	})
+
+	metricsRegistry := prometheus.NewRegistry()
+	metricsRegistry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	metricsRegistry.MustRegister(st.collectors...)
+
+	userStorage := promrepositories.NewUserRepository(st.userWriter, st.userReader, metricsRegistry)
+
+	phones, err := commonphone.NewNormalizer(cfg.Phone.DefaultRegion)
+	if err != nil {
+		panic(fmt.Sprintf("failed instatiate phone normalizer: %v", err))
+	}
+
+	authService, err := jwtauthservice.NewJWTAuth(userStorage, userStorage, st.sessionWriter, st.sessionReader, cfg.Cert.Private, cfg.Cert.Public, jwtauthservice.TokenConfig{
+		Issuer:   cfg.Token.Issuer,
+		Audience: cfg.Token.Audience,
+		Leeway:   time.Second * time.Duration(cfg.Token.LeewayInSecond),
+		MaxAge:   time.Second * time.Duration(cfg.Token.MaxAgeInSecond),
+	}, phones, logger)
 	if err != nil {
 		panic(fmt.Sprintf("failed instatiate auth: %v", err))
 	}
 
-	userService := stduserservice.NewService(userStorage, userStorage)
+	authnService := promauthservice.NewAuthn(authService, metricsRegistry)
+	authzService := promauthservice.NewAuthz(authService, metricsRegistry)
+
+	mailer, err := openMailer(cfg.Mail)
+	if err != nil {
+		panic(fmt.Sprintf("failed instatiate mailer: %v", err))
+	}
+
+	sms, err := openSMSSender(cfg.SMS)
+	if err != nil {
+		panic(fmt.Sprintf("failed instatiate SMS sender: %v", err))
+	}
+
+	otpWindow := time.Second * time.Duration(cfg.OTP.LimitWindowInSecond)
+	otpService, err := jwtauthservice.NewOTPAuth(
+		authService,
+		st.challengeWriter,
+		st.challengeReader,
+		mailer,
+		sms,
+		commonratelimit.New(cfg.OTP.StartLimit, otpWindow),
+		commonratelimit.New(cfg.OTP.IPLimit, otpWindow),
+		jwtauthservice.OTPConfig{
+			CodeTTL:     time.Second * time.Duration(cfg.OTP.CodeTTLInSecond),
+			MaxAttempts: cfg.OTP.MaxAttempts,
+			LinkURL:     cfg.OTP.LinkURL,
+			Secret:      cfg.OTP.Secret,
+		},
+		logger,
+	)
+	if err != nil {
+		panic(fmt.Sprintf("failed instatiate OTP: %v", err))
+	}
+
+	passwordlessService := promauthservice.NewPasswordless(otpService, metricsRegistry)
+
+	passkeyService, err := jwtauthservice.NewPasskeyAuth(authService, st.passkeyWriter, st.passkeyReader, jwtauthservice.PasskeyConfig{
+		RPID:          cfg.Passkey.RPID,
+		RPDisplayName: cfg.Passkey.RPDisplayName,
+		RPOrigins:     cfg.Passkey.RPOrigins,
+		CeremonyTTL:   time.Second * time.Duration(cfg.Passkey.CeremonyTTLInSecond),
+		Secret:        cfg.Passkey.Secret,
+	}, logger)
+	if err != nil {
+		panic(fmt.Sprintf("failed instatiate passkey: %v", err))
+	}
+
+	passkeyAuthnService := promauthservice.NewPasskeyAuthn(passkeyService, metricsRegistry)
+
+	apiKeyService := jwtauthservice.NewAPIKeyAuth(st.apiKeyWriter, st.apiKeyReader, logger)
+	apiKeyAuthzService := promauthservice.NewAPIKeyAuthz(apiKeyService, metricsRegistry)
+
+	introspectionClients := map[string]string{}
+	for _, c := range cfg.Introspection.Clients {
+		introspectionClients[c.ID] = c.Secret
+	}
+
+	introspectService, err := jwtauthservice.NewIntrospectAuth(authService, apiKeyService, jwtauthservice.IntrospectionConfig{
+		Clients:  introspectionClients,
+		CacheTTL: time.Second * time.Duration(cfg.Introspection.CacheTTLInSecond),
+	}, logger)
+	if err != nil {
+		panic(fmt.Sprintf("failed instatiate introspection: %v", err))
+	}
+
+	var cookies *commonhttpcookie.Jar
+	if cfg.Cookie.Enabled {
+		sameSite, err := commonhttpcookie.ParseSameSite(cfg.Cookie.SameSite)
+		if err != nil {
+			panic(fmt.Sprintf("failed instatiate cookie: %v", err))
+		}
+
+		cookies = commonhttpcookie.New(commonhttpcookie.Config{
+			Name:     cfg.Cookie.Name,
+			CSRFName: cfg.Cookie.CSRFName,
+			Domain:   cfg.Cookie.Domain,
+			Path:     cfg.Cookie.Path,
+			SameSite: sameSite,
+		})
+	}
+
+	verificationTTL := time.Minute * time.Duration(cfg.EmailVerification.TTLInMinute)
+	verificationSigner, err := commonsignedlink.New(cfg.EmailVerification.Secret, verificationTTL)
+	if err != nil {
+		panic(fmt.Sprintf("failed instatiate email verification: %v", err))
+	}
+
+	userService := stduserservice.NewService(userStorage, userStorage, phones, stduserservice.EmailVerification{
+		Mailer: mailer,
+		Signer: verificationSigner,
+		URL:    cfg.EmailVerification.URL,
+		TTL:    verificationTTL,
+	}, logger)
+
+	healthRegistry := commonhealth.NewRegistry(3 * time.Second)
+	healthRegistry.Register("db_state", func(ctx context.Context) error {
+		if !dbMonitor.Ready() {
+			return fmt.Errorf("DB is %s", dbMonitor.State())
+		}
+
+		return nil
+	})
+	healthRegistry.Register("db", st.ping)
+	healthRegistry.Register("migration", func(ctx context.Context) error {
+		// schema maintained by hand has nothing to compare with
+		managed, err := migration.Managed(ctx, st.sqlDB, st.dialect)
+		if err != nil || !managed {
+			return err
+		}
+
+		pending, err := migration.Pending(ctx, st.sqlDB, st.dialect)
+		if err != nil {
+			return err
+		}
+
+		if len(pending) > 0 {
+			return fmt.Errorf("pending migrations: %v", pending)
+		}
+
+		return nil
+	})
+	healthRegistry.Register("signing_key", authService.CheckSigningKeys)
 
 	r := chi.NewRouter()
 
 	// A good base middleware stack
 	r.Use(middleware.RequestID)
-	r.Use(middleware.RealIP)
-	r.Use(middleware.Logger)
+	if cfg.Server.TrustProxy {
+		r.Use(middleware.RealIP)
+	}
+	r.Use(commonhttpmiddleware.Tracing)
+	r.Use(commonhttpmiddleware.NewLoggerMiddleware(logger).Log)
+	r.Use(commonhttpmiddleware.NewMetricsMiddleware(metricsRegistry).Metrics)
 	r.Use(middleware.Recoverer)
+	r.Use(commonhttpmiddleware.Negotiate)
 
 	// Set a timeout value on the request context (ctx), that will signal
 	// through ctx.Done() that the request has timed out and further
@@ -78,28 +306,89 @@ func main() {
 		w.Write([]byte("hi"))
 	})
 
+	r.Get("/healthz", commonhealth.LivenessHandler())
+	r.Get("/readyz", healthRegistry.ReadinessHandler())
+
+	r.Handle("/metrics", promhttp.HandlerFor(metricsRegistry, promhttp.HandlerOpts{}))
+
 	r.Route("/v1", func(r chi.Router) {
+		errEnc := commonhttpenc.NewErrorEncoder(commonhttpenc.DefaultDictionary(), http.StatusInternalServerError)
+
 		{
-			hn := v1authhttphandler.NewAuthnHandler(authService)
+			hn := v1authhttphandler.NewAuthnHandler(authnService, cookies, errEnc)
 			r.Post("/login", hn.Login())
 		}
 
 		{
-			hn := v1userhttphandler.NewRegistratorHandler(userService)
+			hn := v1authhttphandler.NewOTPHandler(passwordlessService, cookies, errEnc)
+			r.Post("/login/otp/start", hn.Start())
+			r.Post("/login/otp/complete", hn.Complete())
+		}
+
+		{
+			hn := v1authhttphandler.NewPasskeyLoginHandler(passkeyAuthnService, cookies, errEnc)
+			r.Post("/login/passkey/begin", hn.Begin())
+			r.Post("/login/passkey/finish", hn.Finish())
+		}
+
+		{
+			hn := v1userhttphandler.NewRegistratorHandler(userService, errEnc)
 			r.Post("/register", hn.Register())
 		}
 
+		{
+			hn := v1userhttphandler.NewEmailVerificationHandler(userService, errEnc)
+			r.Get("/email/verify", hn.Verify())
+		}
+
+		{
+			hn := v1authhttphandler.NewIntrospectHandler(introspectService, errEnc)
+			r.Post("/introspect", hn.Introspect())
+		}
+
 		// RESTy routes for "articles" resource
 		r.Route("/me", func(r chi.Router) {
+			authMiddleware := commonhttpmiddleware.NewAuthMiddleware(authzService, apiKeyAuthzService, cookies, errEnc)
+			r.Use(authMiddleware.Auth)
+
+			stepUp := authMiddleware.RequireRecentAuth(time.Second*time.Duration(cfg.StepUp.MaxAgeInSecond), cfg.StepUp.Methods...)
+
+			hn := v1userhttphandler.NewMeHandler(userService, errEnc)
+
+			r.With(authMiddleware.RequireScope(authservice.ScopeProfileRead)).Get("/", hn.GetProfile())
+			r.With(authMiddleware.RequireScope(authservice.ScopeProfileWrite), stepUp).Put("/", hn.UpdateProfile())
+
 			{
-				authMiddleware := commonhttpmiddleware.NewAuthMiddleware(authService)
-				r.Use(authMiddleware.Auth)
+				hn := v1userhttphandler.NewEmailVerificationHandler(userService, errEnc)
+				r.With(authMiddleware.RequireScope(authservice.ScopeProfileWrite)).Post("/email/verification", hn.Resend())
 			}
 
-			hn := v1userhttphandler.NewMeHandler(userService)
+			// account security is managed by login token only, API keys can not reach it
+			r.Group(func(r chi.Router) {
+				r.Use(authMiddleware.RequireScope())
+
+				{
+					hn := v1authhttphandler.NewPasskeyHandler(passkeyService, errEnc)
+					r.Get("/passkeys", hn.List())
+					r.With(stepUp).Post("/passkeys", hn.FinishRegistration())
+					r.With(stepUp).Post("/passkeys/registration", hn.BeginRegistration())
+					r.Patch("/passkeys/{id}", hn.Rename())
+					r.Delete("/passkeys/{id}", hn.Delete())
+				}
+
+				{
+					hn := v1authhttphandler.NewSessionHandler(authService, errEnc)
+					r.Get("/sessions", hn.List())
+					r.Delete("/sessions/{id}", hn.Revoke())
+				}
 
-			r.Get("/", hn.GetProfile())
-			r.Put("/", hn.UpdateProfile())
+				{
+					hn := v1authhttphandler.NewAPIKeyHandler(apiKeyService, errEnc)
+					r.Get("/api-keys", hn.List())
+					r.With(stepUp).Post("/api-keys", hn.Create())
+					r.Delete("/api-keys/{id}", hn.Delete())
+				}
+			})
 		})
 	})
 
@@ -111,19 +400,42 @@ func main() {
 		Handler:      r,
 	}
 
-	go func() {
-		log.Printf("app server is up and running. Go to http://127.0.0.1" + cfg.Server.Port)
-		err := server.ListenAndServe()
-		if err != nil && err != http.ErrServerClosed {
-			panic(err)
-		}
-	}()
+	lc.Serve(server)
+	logger.Info("app server is up and running", slog.String("addr", "http://127.0.0.1"+cfg.Server.Port))
 
-	defer func() {
-		db.Close()
+	// pool & query stats carry process internals and raw DB errors, so they are kept off the API
+	if cfg.Server.InternalPort != "" {
+		internal := http.NewServeMux()
+		internal.Handle("/debug/vars", expvar.Handler())
 
-		cancel()
-	}()
+		lc.Serve(&http.Server{
+			Addr:         cfg.Server.InternalPort,
+			ReadTimeout:  time.Second * time.Duration(cfg.Server.ReadTimeoutInSecond),
+			WriteTimeout: time.Second * time.Duration(cfg.Server.WriteTimeoutInSecond),
+			Handler:      internal,
+		})
+		logger.Info("internal server is up and running", slog.String("addr", cfg.Server.InternalPort))
+	}
 
-	<-quit
+	os.Exit(lc.Run())
+}
+
+func openMailer(cfg config.MailConfig) (commonmail.Mailer, error) {
+	switch cfg.Driver {
+	case "", config.MailDriverFile:
+		return commonmail.NewFileMailer(cfg.Dir, cfg.From)
+	case config.MailDriverSMTP:
+		return commonmail.NewSMTPMailer(cfg.SMTPAddr, cfg.From), nil
+	default:
+		return nil, fmt.Errorf("unsupported mail driver: %s", cfg.Driver)
+	}
+}
+
+func openSMSSender(cfg config.SMSConfig) (commonsms.Sender, error) {
+	switch cfg.Driver {
+	case "", config.SMSDriverFile:
+		return commonsms.NewFileSender(cfg.File), nil
+	default:
+		return nil, fmt.Errorf("unsupported SMS driver: %s", cfg.Driver)
+	}
 }
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"waizlytest/config"
+	"waizlytest/migration"
+)
+
+// runMigrate applies or lists the migrations after the baseline schema,
+// so they can run as a deployment step apart from the app, see DB.AutoMigrate otherwise.
+//
+//	waizlytest migrate up|status
+func runMigrate(args []string) int {
+	if len(args) != 1 || (args[0] != "up" && args[0] != "status") {
+		fmt.Fprintln(os.Stderr, "usage: waizlytest migrate up|status")
+		return 2
+	}
+
+	cfg, err := config.Read("conf.yaml")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed open conf file: %v\n", err)
+		return 1
+	}
+
+	ctx := context.Background()
+	st, err := openStorage(ctx, cfg.DB)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed open DB: %v\n", err)
+		return 1
+	}
+	defer st.Close()
+
+	if args[0] == "status" {
+		pending, err := migration.Pending(ctx, st.sqlDB, st.dialect)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed list migrations: %v\n", err)
+			return 1
+		}
+
+		for _, v := range pending {
+			fmt.Printf("pending: %s\n", v)
+		}
+
+		return 0
+	}
+
+	applied, err := migration.Up(ctx, st.sqlDB, st.dialect)
+	for _, v := range applied {
+		fmt.Printf("applied: %s\n", v)
+	}
+
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed migrate DB: %v\n", err)
+		return 1
+	}
+
+	return 0
+}
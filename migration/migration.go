@@ -0,0 +1,165 @@
+package migration
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"fmt"
+	"path"
+	"sort"
+	"time"
+)
+
+//go:embed pg/*.sql sqlite/*.sql
+var files embed.FS
+
+// Dialect is directory name holding the migrations of a database
+type Dialect string
+
+const (
+	DialectPostgres Dialect = "pg"
+	DialectSQLite   Dialect = "sqlite"
+)
+
+// Baseline is the initial schema, it is set up by hand (see README) and the runner starts after it.
+const Baseline = "00000001_init.sql"
+
+// Versions lists the migrations of dialect after Baseline, ordered as they must be applied.
+func Versions(d Dialect) ([]string, error) {
+	entries, err := files.ReadDir(string(d))
+	if err != nil {
+		return nil, err
+	}
+
+	versions := make([]string, 0, len(entries))
+	for _, e := range entries {
+		if e.Name() != Baseline {
+			versions = append(versions, e.Name())
+		}
+	}
+
+	sort.Strings(versions)
+	return versions, nil
+}
+
+// Pending lists migrations which are not applied yet to db.
+func Pending(ctx context.Context, db *sql.DB, d Dialect) ([]string, error) {
+	versions, err := Versions(d)
+	if err != nil {
+		return nil, err
+	}
+
+	applied, err := appliedVersions(ctx, db, d)
+	if err != nil {
+		return nil, err
+	}
+
+	pending := []string{}
+	for _, v := range versions {
+		if !applied[v] {
+			pending = append(pending, v)
+		}
+	}
+
+	return pending, nil
+}
+
+// Up applies every pending migration, each within its own transaction.
+// It returns the applied versions.
+func Up(ctx context.Context, db *sql.DB, d Dialect) ([]string, error) {
+	_, err := db.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version VARCHAR(255) PRIMARY KEY,
+			applied_at TIMESTAMP NOT NULL
+		)
+	`)
+	if err != nil {
+		return nil, err
+	}
+
+	pending, err := Pending(ctx, db, d)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, v := range pending {
+		err = apply(ctx, db, d, v)
+		if err != nil {
+			return pending[:i], fmt.Errorf("failed apply migration %s: %w", v, err)
+		}
+	}
+
+	return pending, nil
+}
+
+func apply(ctx context.Context, db *sql.DB, d Dialect, version string) error {
+	ddl, err := files.ReadFile(path.Join(string(d), version))
+	if err != nil {
+		return err
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, string(ddl))
+	if err != nil {
+		return err
+	}
+
+	query := `INSERT INTO schema_migrations (version, applied_at) VALUES ($1, $2)`
+	if d == DialectSQLite {
+		query = `INSERT INTO schema_migrations (version, applied_at) VALUES (?, ?)`
+	}
+
+	_, err = tx.ExecContext(ctx, query, version, time.Now())
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func appliedVersions(ctx context.Context, db *sql.DB, d Dialect) (map[string]bool, error) {
+	applied := map[string]bool{}
+
+	managed, err := Managed(ctx, db, d)
+	if err != nil || !managed {
+		return applied, err
+	}
+
+	rows, err := db.QueryContext(ctx, `SELECT version FROM schema_migrations`)
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var v string
+		err = rows.Scan(&v)
+		if err != nil {
+			return nil, err
+		}
+
+		applied[v] = true
+	}
+
+	return applied, rows.Err()
+}
+
+// Managed reports whether the runner ever ran on db, that is schema_migrations exists.
+// Schema of the other databases is maintained by hand.
+func Managed(ctx context.Context, db *sql.DB, d Dialect) (bool, error) {
+	query := `SELECT to_regclass('schema_migrations') IS NOT NULL`
+	if d == DialectSQLite {
+		query = `SELECT COUNT(*) > 0 FROM sqlite_master WHERE type = 'table' AND name = 'schema_migrations'`
+	}
+
+	var exists bool
+	err := db.QueryRowContext(ctx, query).Scan(&exists)
+	return exists, err
+}
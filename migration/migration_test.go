@@ -0,0 +1,118 @@
+package migration_test
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	_ "modernc.org/sqlite"
+
+	"waizlytest/migration"
+)
+
+// newDB has the baseline schema set up by hand, as the README tells
+func newDB(t *testing.T) *sql.DB {
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatalf("failed open DB: %v", err)
+	}
+
+	// every connection of `:memory:` has its own database
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	ddl, err := os.ReadFile("sqlite/" + migration.Baseline)
+	if err != nil {
+		t.Fatalf("failed read baseline: %v", err)
+	}
+
+	_, err = db.Exec(string(ddl))
+	if err != nil {
+		t.Fatalf("failed apply baseline: %v", err)
+	}
+
+	return db
+}
+
+func TestVersions(t *testing.T) {
+	for _, d := range []migration.Dialect{migration.DialectPostgres, migration.DialectSQLite} {
+		versions, err := migration.Versions(d)
+		if err != nil {
+			t.Fatalf("failed list %s versions: %v", d, err)
+		}
+
+		if len(versions) == 0 || versions[0] != "00000002_unique_phone.sql" {
+			t.Errorf("expected %s versions to start after baseline, got %v", d, versions)
+		}
+	}
+
+	pg, _ := migration.Versions(migration.DialectPostgres)
+	sqlite, _ := migration.Versions(migration.DialectSQLite)
+	diff := cmp.Diff(pg, sqlite)
+	if diff != "" {
+		t.Errorf("expected every dialect has the same versions (-pg +sqlite):\n%s", diff)
+	}
+}
+
+func TestUp(t *testing.T) {
+	ctx := context.TODO()
+	db := newDB(t)
+
+	versions, err := migration.Versions(migration.DialectSQLite)
+	if err != nil {
+		t.Fatalf("failed list versions: %v", err)
+	}
+
+	// schema maintained by hand
+	managed, err := migration.Managed(ctx, db, migration.DialectSQLite)
+	if err != nil || managed {
+		t.Fatalf("expected DB not managed before Up, got %v, %v", managed, err)
+	}
+
+	pending, err := migration.Pending(ctx, db, migration.DialectSQLite)
+	if err != nil {
+		t.Fatalf("failed list pending: %v", err)
+	}
+
+	if diff := cmp.Diff(versions, pending); diff != "" {
+		t.Errorf("expected every version pending (-want +got):\n%s", diff)
+	}
+
+	applied, err := migration.Up(ctx, db, migration.DialectSQLite)
+	if err != nil {
+		t.Fatalf("failed migrate: %v", err)
+	}
+
+	if diff := cmp.Diff(versions, applied); diff != "" {
+		t.Errorf("expected every version applied (-want +got):\n%s", diff)
+	}
+
+	managed, err = migration.Managed(ctx, db, migration.DialectSQLite)
+	if err != nil || !managed {
+		t.Errorf("expected DB managed after Up, got %v, %v", managed, err)
+	}
+
+	pending, err = migration.Pending(ctx, db, migration.DialectSQLite)
+	if err != nil || len(pending) != 0 {
+		t.Errorf("expected nothing pending, got %v, %v", pending, err)
+	}
+
+	// applied migrations are not run again
+	applied, err = migration.Up(ctx, db, migration.DialectSQLite)
+	if err != nil || len(applied) != 0 {
+		t.Errorf("expected nothing applied again, got %v, %v", applied, err)
+	}
+}
+
+func TestPending_Unreachable(t *testing.T) {
+	db := newDB(t)
+	db.Close()
+
+	// unreachable DB is not a DB without migrations
+	pending, err := migration.Pending(context.TODO(), db, migration.DialectSQLite)
+	if err == nil {
+		t.Errorf("expected error, got pending %v", pending)
+	}
+}
@@ -0,0 +1,11 @@
+/**
+  *
+  * Phones are stored in E.164, unique among active users only,
+  * so a soft-deleted user does not block the phone from being registered again.
+  * Existing phones must be normalized before it is applied, see `waizlytest phones normalize`,
+  * active users sharing a phone make it fail.
+  */
+
+CREATE UNIQUE INDEX IF NOT EXISTS users_phone_active_key
+    ON users (phone)
+    WHERE deleted_at IS NULL;
@@ -0,0 +1,13 @@
+/**
+  *
+  * Optional email as another identifier of the user. Only verified email identifies the user,
+  * therefore it is unique among verified emails of active users, so nobody can hold someone else's email
+  * by registering it without verifying.
+  */
+
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email VARCHAR(255);
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email_verified_at TIMESTAMP;
+
+CREATE UNIQUE INDEX IF NOT EXISTS users_email_verified_key
+    ON users (email)
+    WHERE email_verified_at IS NOT NULL AND deleted_at IS NULL;
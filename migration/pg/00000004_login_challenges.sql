@@ -0,0 +1,17 @@
+/**
+  *
+  * One-time codes of passwordless login, only keyed hash of the code is stored.
+  */
+
+CREATE TABLE IF NOT EXISTS login_challenges (
+    id VARCHAR(64) PRIMARY KEY,
+    user_id INT NOT NULL,
+    code_hash VARCHAR(128) NOT NULL,
+    channel VARCHAR(16) NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    expires_at TIMESTAMP NOT NULL,
+    consumed_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS login_challenges_expires_at_idx ON login_challenges (expires_at);
@@ -0,0 +1,23 @@
+/**
+  *
+  * WebAuthn credentials of the users.
+  */
+
+CREATE TABLE IF NOT EXISTS passkeys (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL,
+    credential_id BYTEA NOT NULL,
+    public_key BYTEA NOT NULL,
+    attestation_type VARCHAR(32) NOT NULL DEFAULT '',
+    aaguid BYTEA,
+    sign_count BIGINT NOT NULL DEFAULT 0,
+    transports VARCHAR(128) NOT NULL DEFAULT '',
+    backup_eligible BOOLEAN NOT NULL DEFAULT FALSE,
+    backup_state BOOLEAN NOT NULL DEFAULT FALSE,
+    name VARCHAR(64) NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW(),
+    last_used_at TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS passkeys_credential_id_key ON passkeys (credential_id);
+CREATE INDEX IF NOT EXISTS passkeys_user_id_idx ON passkeys (user_id);
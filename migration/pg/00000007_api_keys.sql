@@ -0,0 +1,19 @@
+/**
+  *
+  * Personal API keys of the users, only hash of the secret is stored.
+  */
+
+CREATE TABLE IF NOT EXISTS api_keys (
+    id SERIAL PRIMARY KEY,
+    user_id INT NOT NULL,
+    name VARCHAR(64) NOT NULL,
+    prefix VARCHAR(16) NOT NULL,
+    secret_hash VARCHAR(64) NOT NULL,
+    scopes VARCHAR(255) NOT NULL DEFAULT '',
+    expires_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT NOW(),
+    last_used_at TIMESTAMP
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS api_keys_prefix_key ON api_keys (prefix);
+CREATE INDEX IF NOT EXISTS api_keys_user_id_idx ON api_keys (user_id);
@@ -0,0 +1,12 @@
+/**
+  *
+  * Challenges of finished passkey ceremonies, so a ceremony answer can not be replayed.
+  */
+
+CREATE TABLE IF NOT EXISTS passkey_challenges (
+    challenge VARCHAR(128) PRIMARY KEY,
+    expires_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS passkey_challenges_expires_at_idx ON passkey_challenges (expires_at);
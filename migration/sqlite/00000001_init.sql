@@ -0,0 +1,28 @@
+/**
+  *
+  * SQLite flavour of the initial schema, intended for single-node deployments.
+  * Requires SQLite 3.24 or newer for the attendance summary upsert.
+  */
+
+CREATE TABLE users (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    fullname VARCHAR(255) NOT NULL,
+    password VARCHAR(255) NOT NULL,
+    phone VARCHAR(20),
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    deleted_at DATETIME
+);
+
+
+CREATE TABLE user_attendance_summaries (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL UNIQUE,
+    total_login INTEGER NOT NULL
+);
+
+CREATE TABLE user_attendance_logs (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    login_at DATETIME NOT NULL
+);
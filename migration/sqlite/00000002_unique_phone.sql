@@ -0,0 +1,10 @@
+/**
+  *
+  * SQLite flavour of the active phone uniqueness, partial indexes require SQLite 3.8 or newer.
+  * Existing phones must be normalized before it is applied, see `waizlytest phones normalize`,
+  * active users sharing a phone make it fail.
+  */
+
+CREATE UNIQUE INDEX IF NOT EXISTS users_phone_active_key
+    ON users (phone)
+    WHERE deleted_at IS NULL;
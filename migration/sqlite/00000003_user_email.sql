@@ -0,0 +1,11 @@
+/**
+  *
+  * SQLite flavour of the optional user email, see the pg migration for the uniqueness rule.
+  */
+
+ALTER TABLE users ADD COLUMN email VARCHAR(255);
+ALTER TABLE users ADD COLUMN email_verified_at DATETIME;
+
+CREATE UNIQUE INDEX IF NOT EXISTS users_email_verified_key
+    ON users (email)
+    WHERE email_verified_at IS NOT NULL AND deleted_at IS NULL;
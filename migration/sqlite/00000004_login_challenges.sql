@@ -0,0 +1,17 @@
+/**
+  *
+  * SQLite flavour of the passwordless login codes.
+  */
+
+CREATE TABLE IF NOT EXISTS login_challenges (
+    id VARCHAR(64) PRIMARY KEY,
+    user_id INTEGER NOT NULL,
+    code_hash VARCHAR(128) NOT NULL,
+    channel VARCHAR(16) NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    expires_at DATETIME NOT NULL,
+    consumed_at DATETIME,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS login_challenges_expires_at_idx ON login_challenges (expires_at);
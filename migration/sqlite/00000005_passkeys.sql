@@ -0,0 +1,23 @@
+/**
+  *
+  * SQLite flavour of the WebAuthn credentials.
+  */
+
+CREATE TABLE IF NOT EXISTS passkeys (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    user_id INTEGER NOT NULL,
+    credential_id BLOB NOT NULL,
+    public_key BLOB NOT NULL,
+    attestation_type VARCHAR(32) NOT NULL DEFAULT '',
+    aaguid BLOB,
+    sign_count INTEGER NOT NULL DEFAULT 0,
+    transports VARCHAR(128) NOT NULL DEFAULT '',
+    backup_eligible BOOLEAN NOT NULL DEFAULT FALSE,
+    backup_state BOOLEAN NOT NULL DEFAULT FALSE,
+    name VARCHAR(64) NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    last_used_at DATETIME
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS passkeys_credential_id_key ON passkeys (credential_id);
+CREATE INDEX IF NOT EXISTS passkeys_user_id_idx ON passkeys (user_id);
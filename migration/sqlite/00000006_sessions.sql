@@ -0,0 +1,19 @@
+/**
+  *
+  * SQLite flavour of the login sessions.
+  */
+
+CREATE TABLE IF NOT EXISTS sessions (
+    id VARCHAR(64) PRIMARY KEY,
+    user_id INTEGER NOT NULL,
+    method VARCHAR(16) NOT NULL,
+    device_name VARCHAR(64) NOT NULL DEFAULT '',
+    user_agent VARCHAR(255) NOT NULL DEFAULT '',
+    ip VARCHAR(45) NOT NULL DEFAULT '',
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    last_seen_at DATETIME NOT NULL,
+    expires_at DATETIME NOT NULL,
+    revoked_at DATETIME
+);
+
+CREATE INDEX IF NOT EXISTS sessions_user_id_idx ON sessions (user_id, expires_at);
@@ -0,0 +1,12 @@
+/**
+  *
+  * SQLite flavour of the finished passkey ceremony challenges.
+  */
+
+CREATE TABLE IF NOT EXISTS passkey_challenges (
+    challenge VARCHAR(128) PRIMARY KEY,
+    expires_at DATETIME NOT NULL,
+    created_at DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS passkey_challenges_expires_at_idx ON passkey_challenges (expires_at);
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	commonphone "waizlytest/common/phone"
+	"waizlytest/config"
+	"waizlytest/migration"
+)
+
+// runPhones rewrites stored phones to E.164 of the configured region,
+// for users registered before phones were normalized.
+// Phones which collide after normalization are reported and left untouched.
+// Run it before migration 00000002_unique_phone.sql, which fails on such collisions.
+//
+//	waizlytest phones normalize [-dry-run]
+func runPhones(args []string) int {
+	if len(args) == 0 || args[0] != "normalize" {
+		fmt.Fprintln(os.Stderr, "usage: waizlytest phones normalize [-dry-run]")
+		return 2
+	}
+
+	fs := flag.NewFlagSet("phones normalize", flag.ContinueOnError)
+	dryRun := fs.Bool("dry-run", false, "only print the changes")
+	if err := fs.Parse(args[1:]); err != nil {
+		return 2
+	}
+
+	cfg, err := config.Read("conf.yaml")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed open conf file: %v\n", err)
+		return 1
+	}
+
+	phones, err := commonphone.NewNormalizer(cfg.Phone.DefaultRegion)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed instantiate phone normalizer: %v\n", err)
+		return 1
+	}
+
+	ctx := context.Background()
+	st, err := openStorage(ctx, cfg.DB)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed open DB: %v\n", err)
+		return 1
+	}
+	defer st.Close()
+
+	err = normalizePhones(ctx, os.Stdout, st.sqlDB, st.dialect, phones, *dryRun)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed normalize phones: %v\n", err)
+		return 1
+	}
+
+	return 0
+}
+
+// normalizePhones is meant to run before migration 00000002_unique_phone.sql, so it can not rely on
+// the unique index and looks for collisions among active users itself.
+// It returns error when some phones collide, they must be resolved by hand before the migration.
+func normalizePhones(ctx context.Context, w io.Writer, db *sql.DB, d migration.Dialect, phones *commonphone.Normalizer, dryRun bool) error {
+	rows, err := db.QueryContext(ctx, `SELECT id, phone, deleted_at IS NULL FROM users WHERE phone IS NOT NULL ORDER BY id`)
+	if err != nil {
+		return err
+	}
+
+	type user struct {
+		id       int64
+		from, to string
+		active   bool
+	}
+
+	var users []user
+	for rows.Next() {
+		var u user
+		err = rows.Scan(&u.id, &u.from, &u.active)
+		if err != nil {
+			rows.Close()
+			return err
+		}
+
+		u.to, err = phones.Normalize(u.from)
+		if err != nil {
+			fmt.Fprintf(w, "user %d: skipped, [%s] is not a valid phone\n", u.id, u.from)
+			u.to = u.from
+		}
+
+		users = append(users, u)
+	}
+
+	rows.Close()
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	// owners of every normalized phone, only active users must be unique
+	owners := map[string][]int64{}
+	for _, u := range users {
+		if u.active {
+			owners[u.to] = append(owners[u.to], u.id)
+		}
+	}
+
+	query := `UPDATE users SET phone = $1 WHERE id = $2`
+	if d == migration.DialectSQLite {
+		query = `UPDATE users SET phone = ? WHERE id = ?`
+	}
+
+	collisions := 0
+	for _, u := range users {
+		if u.active && len(owners[u.to]) > 1 {
+			fmt.Fprintf(w, "user %d: skipped, [%s] -> [%s] collides with users %v\n", u.id, u.from, u.to, owners[u.to])
+			collisions++
+			continue
+		}
+
+		if u.to == u.from {
+			continue
+		}
+
+		if dryRun {
+			fmt.Fprintf(w, "user %d: [%s] -> [%s]\n", u.id, u.from, u.to)
+			continue
+		}
+
+		_, err = db.ExecContext(ctx, query, u.to, u.id)
+		if err != nil {
+			return fmt.Errorf("failed update user %d: %w", u.id, err)
+		}
+
+		fmt.Fprintf(w, "user %d: [%s] -> [%s]\n", u.id, u.from, u.to)
+	}
+
+	if collisions > 0 {
+		return fmt.Errorf("%d active users share a phone, resolve them before migration 00000002_unique_phone.sql", collisions)
+	}
+
+	return nil
+}
@@ -0,0 +1,142 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	_ "modernc.org/sqlite"
+
+	commonphone "waizlytest/common/phone"
+	"waizlytest/migration"
+)
+
+// newPhonesDB has users as they were before migration 00000002_unique_phone.sql, without the unique index
+func newPhonesDB(t *testing.T) *sql.DB {
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatalf("failed open db: %v", err)
+	}
+
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	_, err = db.Exec(`
+		CREATE TABLE users (
+			id INTEGER PRIMARY KEY,
+			phone VARCHAR(20),
+			deleted_at DATETIME
+		)
+	`)
+	if err != nil {
+		t.Fatalf("failed create users: %v", err)
+	}
+
+	return db
+}
+
+func TestNormalizePhones(t *testing.T) {
+	type user struct {
+		id      int64
+		phone   any
+		deleted bool
+	}
+
+	scenarios := []struct {
+		name     string
+		users    []user
+		dryRun   bool
+		expected map[int64]any
+		errored  bool
+	}{
+		{
+			name:     "[OK] Normalized",
+			users:    []user{{id: 1, phone: "081211112222"}, {id: 2, phone: "+6281233334444"}, {id: 3, phone: nil}},
+			expected: map[int64]any{1: "+6281211112222", 2: "+6281233334444", 3: nil},
+		},
+		{
+			name:     "[OK] Dry run",
+			users:    []user{{id: 1, phone: "081211112222"}},
+			dryRun:   true,
+			expected: map[int64]any{1: "081211112222"},
+		},
+		{
+			name:     "[OK] Invalid phone is kept",
+			users:    []user{{id: 1, phone: "abc"}, {id: 2, phone: "081211112222"}},
+			expected: map[int64]any{1: "abc", 2: "+6281211112222"},
+		},
+		{
+			name:     "[OK] Deleted user may share phone",
+			users:    []user{{id: 1, phone: "081211112222", deleted: true}, {id: 2, phone: "+6281211112222"}},
+			expected: map[int64]any{1: "+6281211112222", 2: "+6281211112222"},
+		},
+		{
+			name:     "[Failed] Collision",
+			users:    []user{{id: 1, phone: "081211112222"}, {id: 2, phone: "+6281211112222"}, {id: 3, phone: "081233334444"}},
+			expected: map[int64]any{1: "081211112222", 2: "+6281211112222", 3: "+6281233334444"},
+			errored:  true,
+		},
+		{
+			name:     "[Failed] Duplicated before normalization",
+			users:    []user{{id: 1, phone: "081211112222"}, {id: 2, phone: "081211112222"}},
+			expected: map[int64]any{1: "081211112222", 2: "081211112222"},
+			errored:  true,
+		},
+	}
+
+	phones, err := commonphone.NewNormalizer("ID")
+	if err != nil {
+		t.Fatalf("failed instantiate phone normalizer: %v", err)
+	}
+
+	for _, scn := range scenarios {
+		t.Run(scn.name, func(t *testing.T) {
+			ctx := context.TODO()
+			db := newPhonesDB(t)
+
+			for _, u := range scn.users {
+				var deletedAt any
+				if u.deleted {
+					deletedAt = "2024-01-01 00:00:00"
+				}
+
+				_, err := db.Exec(`INSERT INTO users (id, phone, deleted_at) VALUES (?, ?, ?)`, u.id, u.phone, deletedAt)
+				if err != nil {
+					t.Fatalf("failed insert user: %v", err)
+				}
+			}
+
+			err := normalizePhones(ctx, io.Discard, db, migration.DialectSQLite, phones, scn.dryRun)
+			if (err != nil) != scn.errored {
+				t.Fatalf("expected errored %v, got %v", scn.errored, err)
+			}
+
+			got := map[int64]any{}
+			rows, err := db.Query(`SELECT id, phone FROM users`)
+			if err != nil {
+				t.Fatalf("failed query users: %v", err)
+			}
+
+			defer rows.Close()
+			for rows.Next() {
+				var id int64
+				var phone sql.NullString
+				if err := rows.Scan(&id, &phone); err != nil {
+					t.Fatalf("failed scan user: %v", err)
+				}
+
+				got[id] = nil
+				if phone.Valid {
+					got[id] = phone.String
+				}
+			}
+
+			diff := cmp.Diff(scn.expected, got)
+			if diff != "" {
+				t.Errorf("phones mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
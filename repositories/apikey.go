@@ -0,0 +1,20 @@
+package repositories
+
+import "time"
+
+// APIKey is personal key of the user for scripts & integrations, its secret is kept hashed
+type APIKey struct {
+	ID     int64
+	UserID int64
+	// Name is given by the user to tell the keys apart
+	Name string
+	// Prefix is the public part of the key, unique across users, it finds the key on authentication
+	Prefix string
+	// SecretHash is SHA-256 hex of the secret part of the key
+	SecretHash string
+	Scopes     []string
+	// ExpiresAt is nil for key which never expires
+	ExpiresAt  *time.Time
+	CreatedAt  time.Time
+	LastUsedAt *time.Time
+}
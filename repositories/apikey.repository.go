@@ -0,0 +1,20 @@
+package repositories
+
+import (
+	"context"
+	"time"
+)
+
+type APIKeyWriter interface {
+	CreateAPIKey(ctx context.Context, k *APIKey) (int64, error)
+	// TouchAPIKey updates last used time of the key
+	TouchAPIKey(ctx context.Context, id int64, at time.Time) error
+	// DeleteAPIKey reports false when the user has no such key
+	DeleteAPIKey(ctx context.Context, userID, id int64) (bool, error)
+}
+
+type APIKeyReader interface {
+	// FindAPIKeyByPrefix returns nil when no key has the prefix
+	FindAPIKeyByPrefix(ctx context.Context, prefix string) (*APIKey, error)
+	FindAPIKeysByUser(ctx context.Context, userID int64) ([]*APIKey, error)
+}
@@ -0,0 +1,19 @@
+package repositories
+
+import (
+	"context"
+
+	"waizlytest/common/contextkey"
+)
+
+// WithPrimary pins every read made with the returned context to the primary database.
+// Use it on read-after-write paths where replica lag is not acceptable.
+func WithPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, contextkey.PrimaryDBContextKey, true)
+}
+
+// PrimaryPinned reports whether reads of ctx should skip the replicas.
+func PrimaryPinned(ctx context.Context) bool {
+	pinned, _ := ctx.Value(contextkey.PrimaryDBContextKey).(bool)
+	return pinned
+}
@@ -0,0 +1,7 @@
+package repositories
+
+import "errors"
+
+// ErrDuplicate is returned by writers when a unique constraint is violated,
+// e.g phone of an active user is already taken. Implementations wrap the driver error with it.
+var ErrDuplicate = errors.New("duplicate")
@@ -0,0 +1,17 @@
+package repositories
+
+import "time"
+
+// LoginChallenge is one-time code sent to the user for passwordless login
+type LoginChallenge struct {
+	ID     string
+	UserID int64
+	// CodeHash is keyed hash of the code, the code itself is never stored
+	CodeHash string
+	// Channel the code is sent through, `sms` or `email`
+	Channel    string
+	Attempts   int
+	ExpiresAt  time.Time
+	ConsumedAt *time.Time
+	CreatedAt  time.Time
+}
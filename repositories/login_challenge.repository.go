@@ -0,0 +1,21 @@
+package repositories
+
+import (
+	"context"
+	"time"
+)
+
+type LoginChallengeWriter interface {
+	CreateLoginChallenge(ctx context.Context, c *LoginChallenge) error
+	// CountLoginChallengeAttempt counts an attempt before the code is compared, in one statement so
+	// concurrent attempts can not exceed max. It reports false when the challenge has no attempt left
+	// or is consumed.
+	CountLoginChallengeAttempt(ctx context.Context, id string, max int) (bool, error)
+	// ConsumeLoginChallenge marks the challenge used, it reports false when the challenge was
+	// consumed already, e.g by concurrent request, or has more than max attempts
+	ConsumeLoginChallenge(ctx context.Context, id string, max int, at time.Time) (bool, error)
+}
+
+type LoginChallengeReader interface {
+	FindLoginChallenge(ctx context.Context, id string) (*LoginChallenge, error)
+}
@@ -0,0 +1,42 @@
+package mockrepositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+
+	"waizlytest/repositories"
+)
+
+var _ (repositories.APIKeyWriter) = (*MockAPIKeyRepository)(nil)
+var _ (repositories.APIKeyReader) = (*MockAPIKeyRepository)(nil)
+
+type MockAPIKeyRepository struct {
+	mock.Mock
+}
+
+func (m *MockAPIKeyRepository) CreateAPIKey(ctx context.Context, k *repositories.APIKey) (int64, error) {
+	args := m.Called(ctx, k)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) FindAPIKeyByPrefix(ctx context.Context, prefix string) (*repositories.APIKey, error) {
+	args := m.Called(ctx, prefix)
+	return args.Get(0).(*repositories.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) FindAPIKeysByUser(ctx context.Context, userID int64) ([]*repositories.APIKey, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]*repositories.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) TouchAPIKey(ctx context.Context, id int64, at time.Time) error {
+	args := m.Called(ctx, id, at)
+	return args.Error(0)
+}
+
+func (m *MockAPIKeyRepository) DeleteAPIKey(ctx context.Context, userID, id int64) (bool, error) {
+	args := m.Called(ctx, userID, id)
+	return args.Bool(0), args.Error(1)
+}
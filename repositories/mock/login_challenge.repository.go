@@ -0,0 +1,37 @@
+package mockrepositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+
+	"waizlytest/repositories"
+)
+
+var _ (repositories.LoginChallengeWriter) = (*MockLoginChallengeRepository)(nil)
+var _ (repositories.LoginChallengeReader) = (*MockLoginChallengeRepository)(nil)
+
+type MockLoginChallengeRepository struct {
+	mock.Mock
+}
+
+func (m *MockLoginChallengeRepository) CreateLoginChallenge(ctx context.Context, c *repositories.LoginChallenge) error {
+	args := m.Called(ctx, c)
+	return args.Error(0)
+}
+
+func (m *MockLoginChallengeRepository) FindLoginChallenge(ctx context.Context, id string) (*repositories.LoginChallenge, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*repositories.LoginChallenge), args.Error(1)
+}
+
+func (m *MockLoginChallengeRepository) CountLoginChallengeAttempt(ctx context.Context, id string, max int) (bool, error) {
+	args := m.Called(ctx, id, max)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockLoginChallengeRepository) ConsumeLoginChallenge(ctx context.Context, id string, max int, at time.Time) (bool, error) {
+	args := m.Called(ctx, id, max, at)
+	return args.Bool(0), args.Error(1)
+}
@@ -0,0 +1,47 @@
+package mockrepositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+
+	"waizlytest/repositories"
+)
+
+var _ (repositories.PasskeyWriter) = (*MockPasskeyRepository)(nil)
+var _ (repositories.PasskeyReader) = (*MockPasskeyRepository)(nil)
+
+type MockPasskeyRepository struct {
+	mock.Mock
+}
+
+func (m *MockPasskeyRepository) CreatePasskey(ctx context.Context, p *repositories.Passkey) (int64, error) {
+	args := m.Called(ctx, p)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockPasskeyRepository) FindPasskeysByUser(ctx context.Context, userID int64) ([]*repositories.Passkey, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]*repositories.Passkey), args.Error(1)
+}
+
+func (m *MockPasskeyRepository) UpdatePasskeyUsage(ctx context.Context, id int64, signCount uint32, backupState bool, at time.Time) error {
+	args := m.Called(ctx, id, signCount, backupState, at)
+	return args.Error(0)
+}
+
+func (m *MockPasskeyRepository) RenamePasskey(ctx context.Context, userID, id int64, name string) (bool, error) {
+	args := m.Called(ctx, userID, id, name)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockPasskeyRepository) DeletePasskey(ctx context.Context, userID, id int64) (bool, error) {
+	args := m.Called(ctx, userID, id)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockPasskeyRepository) ConsumePasskeyChallenge(ctx context.Context, challenge string, expiresAt time.Time) (bool, error) {
+	args := m.Called(ctx, challenge, expiresAt)
+	return args.Bool(0), args.Error(1)
+}
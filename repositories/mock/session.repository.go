@@ -0,0 +1,42 @@
+package mockrepositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+
+	"waizlytest/repositories"
+)
+
+var _ (repositories.SessionWriter) = (*MockSessionRepository)(nil)
+var _ (repositories.SessionReader) = (*MockSessionRepository)(nil)
+
+type MockSessionRepository struct {
+	mock.Mock
+}
+
+func (m *MockSessionRepository) CreateSession(ctx context.Context, s *repositories.Session) error {
+	args := m.Called(ctx, s)
+	return args.Error(0)
+}
+
+func (m *MockSessionRepository) FindSession(ctx context.Context, id string) (*repositories.Session, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(*repositories.Session), args.Error(1)
+}
+
+func (m *MockSessionRepository) FindActiveSessionsByUser(ctx context.Context, userID int64, now time.Time) ([]*repositories.Session, error) {
+	args := m.Called(ctx, userID, now)
+	return args.Get(0).([]*repositories.Session), args.Error(1)
+}
+
+func (m *MockSessionRepository) TouchSession(ctx context.Context, id string, at time.Time) error {
+	args := m.Called(ctx, id, at)
+	return args.Error(0)
+}
+
+func (m *MockSessionRepository) RevokeSession(ctx context.Context, userID int64, id string, at time.Time) (bool, error) {
+	args := m.Called(ctx, userID, id, at)
+	return args.Bool(0), args.Error(1)
+}
@@ -44,3 +44,8 @@ func (m *MockUserRepository) FindUserByID(ctx context.Context, id int64) (*repos
 	args := m.Called(ctx, id)
 	return args.Get(0).(*repositories.User), args.Error(1)
 }
+
+func (m *MockUserRepository) FindUserByEmail(ctx context.Context, email string) (*repositories.User, error) {
+	args := m.Called(ctx, email)
+	return args.Get(0).(*repositories.User), args.Error(1)
+}
@@ -0,0 +1,25 @@
+package repositories
+
+import "time"
+
+// Passkey is WebAuthn credential registered by the user
+type Passkey struct {
+	ID     int64
+	UserID int64
+	// CredentialID is chosen by the authenticator, unique across users
+	CredentialID []byte
+	// PublicKey is COSE encoded credential public key
+	PublicKey       []byte
+	AttestationType string
+	AAGUID          []byte
+	// SignCount is the last signature counter reported by the authenticator, 0 when it does not count
+	SignCount uint32
+	// Transports are hints how to reach the authenticator, e.g `usb`, `internal`
+	Transports     []string
+	BackupEligible bool
+	BackupState    bool
+	// Name is given by the user to tell the passkeys apart
+	Name       string
+	CreatedAt  time.Time
+	LastUsedAt *time.Time
+}
@@ -0,0 +1,22 @@
+package repositories
+
+import (
+	"context"
+	"time"
+)
+
+type PasskeyWriter interface {
+	CreatePasskey(ctx context.Context, p *Passkey) (int64, error)
+	// UpdatePasskeyUsage saves sign counter & backup state of a login
+	UpdatePasskeyUsage(ctx context.Context, id int64, signCount uint32, backupState bool, at time.Time) error
+	// RenamePasskey & DeletePasskey report false when the user has no such passkey
+	RenamePasskey(ctx context.Context, userID, id int64, name string) (bool, error)
+	DeletePasskey(ctx context.Context, userID, id int64) (bool, error)
+	// ConsumePasskeyChallenge records challenge of a finished ceremony, it reports false
+	// when the challenge was recorded already, i.e the ceremony is replayed
+	ConsumePasskeyChallenge(ctx context.Context, challenge string, expiresAt time.Time) (bool, error)
+}
+
+type PasskeyReader interface {
+	FindPasskeysByUser(ctx context.Context, userID int64) ([]*Passkey, error)
+}
@@ -0,0 +1,150 @@
+package pgrepositories
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"waizlytest/repositories"
+)
+
+var _ (repositories.APIKeyWriter) = (*APIKeyRepository)(nil)
+var _ (repositories.APIKeyReader) = (*APIKeyRepository)(nil)
+
+// APIKeyRepository implementation both of `repositories.APIKeyWriter` & `repositories.APIKeyReader`
+type APIKeyRepository struct {
+	db *DB
+}
+
+func NewAPIKeyRepository(db *DB) *APIKeyRepository {
+	return &APIKeyRepository{
+		db: db,
+	}
+}
+
+const apiKeyColumns = `id, user_id, name, prefix, secret_hash, scopes, expires_at, created_at, last_used_at`
+
+func scanAPIKey(rw row) (*repositories.APIKey, error) {
+	var k repositories.APIKey
+	var scopes string
+
+	err := rw.Scan(
+		&k.ID,
+		&k.UserID,
+		&k.Name,
+		&k.Prefix,
+		&k.SecretHash,
+		&scopes,
+		&k.ExpiresAt,
+		&k.CreatedAt,
+		&k.LastUsedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if scopes != "" {
+		k.Scopes = strings.Split(scopes, ",")
+	}
+
+	return &k, nil
+}
+
+func (r *APIKeyRepository) CreateAPIKey(ctx context.Context, k *repositories.APIKey) (int64, error) {
+	query := `
+		INSERT INTO api_keys (user_id, name, prefix, secret_hash, scopes, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id
+	`
+
+	var id int64
+	err := r.db.writer().queryRow(ctx, query, k.UserID, k.Name, k.Prefix, k.SecretHash, strings.Join(k.Scopes, ","), k.ExpiresAt, k.CreatedAt).Scan(&id)
+	if err != nil {
+		return 0, mapError(err)
+	}
+
+	return id, nil
+}
+
+func (r *APIKeyRepository) FindAPIKeyByPrefix(ctx context.Context, prefix string) (*repositories.APIKey, error) {
+	query := `
+		SELECT ` + apiKeyColumns + `
+		FROM api_keys
+		WHERE prefix = $1
+		LIMIT 1
+	`
+
+	k, err := scanAPIKey(r.db.reader(ctx).queryRow(ctx, query, prefix))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return k, nil
+}
+
+func (r *APIKeyRepository) FindAPIKeysByUser(ctx context.Context, userID int64) ([]*repositories.APIKey, error) {
+	query := `
+		SELECT ` + apiKeyColumns + `
+		FROM api_keys
+		WHERE user_id = $1
+		ORDER BY id
+	`
+
+	rs, err := r.db.reader(ctx).query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rs.Close()
+
+	keys := []*repositories.APIKey{}
+	for rs.Next() {
+		k, err := scanAPIKey(rs)
+		if err != nil {
+			return nil, err
+		}
+
+		keys = append(keys, k)
+	}
+
+	return keys, rs.Err()
+}
+
+func (r *APIKeyRepository) TouchAPIKey(ctx context.Context, id int64, at time.Time) error {
+	query := `
+		UPDATE api_keys
+		SET last_used_at = $2
+		WHERE id = $1
+	`
+
+	err := r.db.writer().exec(ctx, query, id, at)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *APIKeyRepository) DeleteAPIKey(ctx context.Context, userID, id int64) (bool, error) {
+	query := `
+		DELETE FROM api_keys
+		WHERE id = $2 AND user_id = $1
+		RETURNING id
+	`
+
+	var deleted int64
+	err := r.db.writer().queryRow(ctx, query, userID, id).Scan(&deleted)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	return true, nil
+}
@@ -0,0 +1,101 @@
+package pgrepositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// conn is what the repositories need from a database handle,
+// so they work both on `database/sql` and on native `pgxpool`.
+type conn interface {
+	exec(ctx context.Context, query string, args ...any) error
+	queryRow(ctx context.Context, query string, args ...any) row
+	query(ctx context.Context, query string, args ...any) (rows, error)
+	ping(ctx context.Context) error
+}
+
+type row interface {
+	Scan(dest ...any) error
+}
+
+// rows is the common part of `*sql.Rows` & `pgx.Rows`, Close must be called once done
+type rows interface {
+	Next() bool
+	Scan(dest ...any) error
+	Err() error
+	Close()
+}
+
+type sqlConn struct {
+	db *sql.DB
+}
+
+func (c sqlConn) exec(ctx context.Context, query string, args ...any) error {
+	_, err := c.db.ExecContext(ctx, query, args...)
+	return err
+}
+
+func (c sqlConn) queryRow(ctx context.Context, query string, args ...any) row {
+	return c.db.QueryRowContext(ctx, query, args...)
+}
+
+func (c sqlConn) query(ctx context.Context, query string, args ...any) (rows, error) {
+	rs, err := c.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+
+	return sqlRows{rs}, nil
+}
+
+func (c sqlConn) ping(ctx context.Context) error {
+	return c.db.PingContext(ctx)
+}
+
+type poolConn struct {
+	pool *pgxpool.Pool
+}
+
+func (c poolConn) exec(ctx context.Context, query string, args ...any) error {
+	_, err := c.pool.Exec(ctx, query, args...)
+	return err
+}
+
+func (c poolConn) queryRow(ctx context.Context, query string, args ...any) row {
+	return poolRow{c.pool.QueryRow(ctx, query, args...)}
+}
+
+func (c poolConn) query(ctx context.Context, query string, args ...any) (rows, error) {
+	return c.pool.Query(ctx, query, args...)
+}
+
+func (c poolConn) ping(ctx context.Context) error {
+	return c.pool.Ping(ctx)
+}
+
+// poolRow translates `pgx.ErrNoRows` so repositories only check `sql.ErrNoRows`
+type poolRow struct {
+	row pgx.Row
+}
+
+func (r poolRow) Scan(dest ...any) error {
+	err := r.row.Scan(dest...)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return sql.ErrNoRows
+	}
+
+	return err
+}
+
+// sqlRows drops error of Close, which is reported by Err already
+type sqlRows struct {
+	*sql.Rows
+}
+
+func (r sqlRows) Close() {
+	r.Rows.Close()
+}
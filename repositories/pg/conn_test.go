@@ -0,0 +1,101 @@
+package pgrepositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/jackc/pgx/v5"
+	_ "modernc.org/sqlite"
+)
+
+type fakeRow struct {
+	err error
+}
+
+func (r fakeRow) Scan(dest ...any) error {
+	return r.err
+}
+
+func TestPoolRow_Scan(t *testing.T) {
+	other := errors.New("conn closed")
+
+	scenarios := []struct {
+		name     string
+		err      error
+		expected error
+	}{
+		{name: "[OK] Found"},
+		{name: "[Failed] No rows", err: pgx.ErrNoRows, expected: sql.ErrNoRows},
+		{name: "[Failed] Other error", err: other, expected: other},
+	}
+
+	for _, scn := range scenarios {
+		t.Run(scn.name, func(t *testing.T) {
+			err := poolRow{fakeRow{scn.err}}.Scan()
+			if !errors.Is(err, scn.expected) {
+				t.Errorf("expected %v, got %v", scn.expected, err)
+			}
+		})
+	}
+}
+
+// sqlConn only relies on `database/sql`, so any driver works to check it
+func TestSQLConn(t *testing.T) {
+	ctx := context.TODO()
+
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatalf("failed open db: %v", err)
+	}
+
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	var c conn = sqlConn{db}
+
+	if err := c.ping(ctx); err != nil {
+		t.Fatalf("failed ping: %v", err)
+	}
+
+	if err := c.exec(ctx, "CREATE TABLE items (id INTEGER PRIMARY KEY, name TEXT)"); err != nil {
+		t.Fatalf("failed exec: %v", err)
+	}
+
+	if err := c.exec(ctx, "INSERT INTO items (name) VALUES (?), (?)", "a", "b"); err != nil {
+		t.Fatalf("failed exec: %v", err)
+	}
+
+	var name string
+	if err := c.queryRow(ctx, "SELECT name FROM items WHERE id = ?", 2).Scan(&name); err != nil || name != "b" {
+		t.Errorf("expected b, got %q, %v", name, err)
+	}
+
+	if err := c.queryRow(ctx, "SELECT name FROM items WHERE id = ?", 3).Scan(&name); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("expected sql.ErrNoRows, got %v", err)
+	}
+
+	rs, err := c.query(ctx, "SELECT name FROM items ORDER BY id")
+	if err != nil {
+		t.Fatalf("failed query: %v", err)
+	}
+
+	var names []string
+	for rs.Next() {
+		if err := rs.Scan(&name); err != nil {
+			t.Fatalf("failed scan: %v", err)
+		}
+
+		names = append(names, name)
+	}
+
+	rs.Close()
+	if err := rs.Err(); err != nil || len(names) != 2 || names[0] != "a" || names[1] != "b" {
+		t.Errorf("expected [a b], got %v, %v", names, err)
+	}
+
+	if _, err := c.query(ctx, "SELECT nope FROM items"); err == nil {
+		t.Errorf("expected error of bad query")
+	}
+}
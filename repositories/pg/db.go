@@ -0,0 +1,137 @@
+package pgrepositories
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+
+	"waizlytest/repositories"
+)
+
+const defaultHealthCheckInterval = 5 * time.Second
+
+// DB routes the repository queries.
+// Writes always go to the primary, while reads are spread round-robin across
+// healthy replicas unless the context is pinned through `repositories.WithPrimary`.
+type DB struct {
+	primary  conn
+	replicas []*replica
+
+	next uint64
+}
+
+type replica struct {
+	conn    conn
+	healthy atomic.Bool
+}
+
+// NewDB creates DB on top of `database/sql` with optional replicas, every replica is
+// considered healthy until HealthCheck tells otherwise.
+func NewDB(primary *sql.DB, replicas ...*sql.DB) *DB {
+	db := &DB{
+		primary: tracedConn{sqlConn{primary}},
+	}
+
+	for _, r := range replicas {
+		db.addReplica(tracedConn{sqlConn{r}})
+	}
+
+	return db
+}
+
+// NewPoolDB is like NewDB, but on top of native pgx pools.
+func NewPoolDB(primary *pgxpool.Pool, replicas ...*pgxpool.Pool) *DB {
+	db := &DB{
+		primary: tracedConn{poolConn{primary}},
+	}
+
+	for _, r := range replicas {
+		db.addReplica(tracedConn{poolConn{r}})
+	}
+
+	return db
+}
+
+func (db *DB) addReplica(c conn) {
+	rp := &replica{conn: c}
+	rp.healthy.Store(true)
+
+	db.replicas = append(db.replicas, rp)
+}
+
+// Ping checks connection to the primary.
+func (db *DB) Ping(ctx context.Context) error {
+	return db.primary.ping(ctx)
+}
+
+func (db *DB) writer() conn {
+	return db.primary
+}
+
+// reader picks next healthy replica, falls back to primary when there is none.
+func (db *DB) reader(ctx context.Context) conn {
+	if len(db.replicas) == 0 || repositories.PrimaryPinned(ctx) {
+		return db.primary
+	}
+
+	n := uint64(len(db.replicas))
+	start := atomic.AddUint64(&db.next, 1)
+	for i := uint64(0); i < n; i++ {
+		rp := db.replicas[(start+i)%n]
+		if rp.healthy.Load() {
+			return rp.conn
+		}
+	}
+
+	return db.primary
+}
+
+// HealthCheck pings every replica right away then on each interval. Failing replica is taken
+// out of rotation and comes back once it answers again. It blocks until ctx is done.
+func (db *DB) HealthCheck(ctx context.Context, interval time.Duration) {
+	if len(db.replicas) == 0 {
+		return
+	}
+
+	if interval <= 0 {
+		interval = defaultHealthCheckInterval
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		db.checkReplicas(ctx, interval)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (db *DB) checkReplicas(ctx context.Context, timeout time.Duration) {
+	for i, rp := range db.replicas {
+		pingCtx, cancel := context.WithTimeout(ctx, timeout)
+		err := rp.conn.ping(pingCtx)
+		cancel()
+
+		if ctx.Err() != nil {
+			return
+		}
+
+		healthy := err == nil
+		if rp.healthy.Swap(healthy) != healthy {
+			if healthy {
+				slog.InfoContext(ctx, "DB replica is back in rotation", slog.Int("replica", i))
+			} else {
+				slog.WarnContext(ctx, "DB replica is out of rotation", slog.Int("replica", i), slog.Any("error", err))
+			}
+		}
+	}
+}
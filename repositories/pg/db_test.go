@@ -0,0 +1,171 @@
+package pgrepositories
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"waizlytest/repositories"
+)
+
+// stubConn answers every query with err, its ping fails while down
+type stubConn struct {
+	name string
+	err  error
+	down atomic.Bool
+}
+
+func (c *stubConn) exec(ctx context.Context, query string, args ...any) error {
+	return c.err
+}
+
+func (c *stubConn) queryRow(ctx context.Context, query string, args ...any) row {
+	return fakeRow{c.err}
+}
+
+func (c *stubConn) query(ctx context.Context, query string, args ...any) (rows, error) {
+	if c.err != nil {
+		return nil, c.err
+	}
+
+	return stubRows{}, nil
+}
+
+func (c *stubConn) ping(ctx context.Context) error {
+	if c.down.Load() {
+		return errors.New(c.name + " is down")
+	}
+
+	return nil
+}
+
+type stubRows struct {
+	err error
+}
+
+func (stubRows) Next() bool             { return false }
+func (stubRows) Scan(dest ...any) error { return nil }
+func (r stubRows) Err() error           { return r.err }
+func (stubRows) Close()                 {}
+
+func newStubDB(replicas int) (*DB, *stubConn, []*stubConn) {
+	primary := &stubConn{name: "primary"}
+	db := &DB{primary: primary}
+
+	var rps []*stubConn
+	for i := 0; i < replicas; i++ {
+		rp := &stubConn{name: "replica"}
+		rps = append(rps, rp)
+		db.addReplica(rp)
+	}
+
+	return db, primary, rps
+}
+
+func TestDB_Reader(t *testing.T) {
+	scenarios := []struct {
+		name      string
+		replicas  int
+		unhealthy []int
+		pinned    bool
+		// expected is index of the replica read by each call, -1 is primary
+		expected []int
+	}{
+		{name: "[OK] No replica", expected: []int{-1, -1}},
+		{name: "[OK] Round-robin", replicas: 2, expected: []int{1, 0, 1, 0}},
+		{name: "[OK] Pinned to primary", replicas: 2, pinned: true, expected: []int{-1, -1}},
+		{name: "[OK] Unhealthy replica skipped", replicas: 3, unhealthy: []int{1}, expected: []int{2, 2, 0, 2}},
+		{name: "[OK] Every replica unhealthy", replicas: 2, unhealthy: []int{0, 1}, expected: []int{-1, -1}},
+	}
+
+	for _, scn := range scenarios {
+		t.Run(scn.name, func(t *testing.T) {
+			db, primary, rps := newStubDB(scn.replicas)
+			for _, i := range scn.unhealthy {
+				db.replicas[i].healthy.Store(false)
+			}
+
+			ctx := context.TODO()
+			if scn.pinned {
+				ctx = repositories.WithPrimary(ctx)
+			}
+
+			for call, want := range scn.expected {
+				var expected conn = primary
+				if want >= 0 {
+					expected = rps[want]
+				}
+
+				if got := db.reader(ctx); got != expected {
+					t.Errorf("call %d: expected %s %d, got %v", call, expected.(*stubConn).name, want, got)
+				}
+			}
+
+			if db.writer() != conn(primary) {
+				t.Errorf("expected writes to primary")
+			}
+		})
+	}
+}
+
+// eventually polls cond, so background health check has time to run
+func eventually(t *testing.T, cond func() bool, msg string) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal(msg)
+		}
+
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestDB_HealthCheck(t *testing.T) {
+	db, primary, rps := newStubDB(2)
+	rps[0].down.Store(true)
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	done := make(chan struct{})
+	go func() {
+		db.HealthCheck(ctx, 20*time.Millisecond)
+		close(done)
+	}()
+
+	eventually(t, func() bool { return !db.replicas[0].healthy.Load() }, "expected down replica out of rotation")
+
+	for i := 0; i < 4; i++ {
+		if got := db.reader(context.TODO()); got != conn(rps[1]) {
+			t.Fatalf("expected reads failed over to healthy replica, got %v", got)
+		}
+	}
+
+	rps[1].down.Store(true)
+	eventually(t, func() bool { return db.reader(context.TODO()) == conn(primary) }, "expected reads fall back to primary")
+
+	rps[0].down.Store(false)
+	eventually(t, func() bool { return db.replicas[0].healthy.Load() }, "expected replica back in rotation")
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("expected health check stopped once ctx is done")
+	}
+}
+
+func TestDB_HealthCheck_Startup(t *testing.T) {
+	db, _, rps := newStubDB(1)
+	rps[0].down.Store(true)
+
+	ctx, cancel := context.WithCancel(context.TODO())
+	defer cancel()
+
+	// interval never ticks within the test, only the check on start runs
+	go db.HealthCheck(ctx, time.Hour)
+
+	eventually(t, func() bool { return !db.replicas[0].healthy.Load() }, "expected replica checked on start")
+}
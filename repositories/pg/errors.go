@@ -0,0 +1,24 @@
+package pgrepositories
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgconn"
+
+	"waizlytest/repositories"
+)
+
+// uniqueViolation is SQLSTATE of unique constraint violation
+const uniqueViolation = "23505"
+
+// mapError turns unique violation into `repositories.ErrDuplicate`,
+// both `database/sql` & pgx pool report *pgconn.PgError.
+func mapError(err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == uniqueViolation {
+		return fmt.Errorf("%w: %s", repositories.ErrDuplicate, pgErr.ConstraintName)
+	}
+
+	return err
+}
@@ -0,0 +1,109 @@
+package pgrepositories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"waizlytest/repositories"
+)
+
+var _ (repositories.LoginChallengeWriter) = (*LoginChallengeRepository)(nil)
+var _ (repositories.LoginChallengeReader) = (*LoginChallengeRepository)(nil)
+
+// LoginChallengeRepository implementation both of `repositories.LoginChallengeWriter` & `repositories.LoginChallengeReader`
+type LoginChallengeRepository struct {
+	db *DB
+}
+
+func NewLoginChallengeRepository(db *DB) *LoginChallengeRepository {
+	return &LoginChallengeRepository{
+		db: db,
+	}
+}
+
+func (r *LoginChallengeRepository) CreateLoginChallenge(ctx context.Context, c *repositories.LoginChallenge) error {
+	query := `
+		INSERT INTO login_challenges (id, user_id, code_hash, channel, attempts, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+
+	err := r.db.writer().exec(ctx, query, c.ID, c.UserID, c.CodeHash, c.Channel, c.Attempts, c.ExpiresAt, c.CreatedAt)
+	if err != nil {
+		return mapError(err)
+	}
+
+	return nil
+}
+
+func (r *LoginChallengeRepository) FindLoginChallenge(ctx context.Context, id string) (*repositories.LoginChallenge, error) {
+	query := `
+		SELECT id, user_id, code_hash, channel, attempts, expires_at, consumed_at, created_at
+		FROM login_challenges
+		WHERE id = $1
+		LIMIT 1
+	`
+
+	var c repositories.LoginChallenge
+	err := r.db.reader(ctx).queryRow(ctx, query, id).Scan(
+		&c.ID,
+		&c.UserID,
+		&c.CodeHash,
+		&c.Channel,
+		&c.Attempts,
+		&c.ExpiresAt,
+		&c.ConsumedAt,
+		&c.CreatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return &c, nil
+}
+
+func (r *LoginChallengeRepository) CountLoginChallengeAttempt(ctx context.Context, id string, max int) (bool, error) {
+	query := `
+		UPDATE login_challenges
+		SET attempts = attempts + 1
+		WHERE id = $1 AND attempts < $2 AND consumed_at IS NULL
+		RETURNING attempts
+	`
+
+	var attempts int
+	err := r.db.writer().queryRow(ctx, query, id, max).Scan(&attempts)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (r *LoginChallengeRepository) ConsumeLoginChallenge(ctx context.Context, id string, max int, at time.Time) (bool, error) {
+	query := `
+		UPDATE login_challenges
+		SET consumed_at = $2
+		WHERE id = $1 AND consumed_at IS NULL AND attempts <= $3
+		RETURNING id
+	`
+
+	var consumed string
+	err := r.db.writer().queryRow(ctx, query, id, at, max).Scan(&consumed)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	return true, nil
+}
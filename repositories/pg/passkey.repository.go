@@ -0,0 +1,176 @@
+package pgrepositories
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"waizlytest/repositories"
+)
+
+var _ (repositories.PasskeyWriter) = (*PasskeyRepository)(nil)
+var _ (repositories.PasskeyReader) = (*PasskeyRepository)(nil)
+
+// PasskeyRepository implementation both of `repositories.PasskeyWriter` & `repositories.PasskeyReader`
+type PasskeyRepository struct {
+	db *DB
+}
+
+func NewPasskeyRepository(db *DB) *PasskeyRepository {
+	return &PasskeyRepository{
+		db: db,
+	}
+}
+
+func (r *PasskeyRepository) CreatePasskey(ctx context.Context, p *repositories.Passkey) (int64, error) {
+	query := `
+		INSERT INTO passkeys (user_id, credential_id, public_key, attestation_type, aaguid, sign_count, transports, backup_eligible, backup_state, name, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id
+	`
+
+	var id int64
+	err := r.db.writer().queryRow(
+		ctx,
+		query,
+		p.UserID,
+		p.CredentialID,
+		p.PublicKey,
+		p.AttestationType,
+		p.AAGUID,
+		int64(p.SignCount),
+		strings.Join(p.Transports, ","),
+		p.BackupEligible,
+		p.BackupState,
+		p.Name,
+		p.CreatedAt,
+	).Scan(&id)
+	if err != nil {
+		return 0, mapError(err)
+	}
+
+	return id, nil
+}
+
+func (r *PasskeyRepository) FindPasskeysByUser(ctx context.Context, userID int64) ([]*repositories.Passkey, error) {
+	query := `
+		SELECT id, user_id, credential_id, public_key, attestation_type, aaguid, sign_count, transports, backup_eligible, backup_state, name, created_at, last_used_at
+		FROM passkeys
+		WHERE user_id = $1
+		ORDER BY id
+	`
+
+	rs, err := r.db.reader(ctx).query(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rs.Close()
+
+	passkeys := []*repositories.Passkey{}
+	for rs.Next() {
+		var p repositories.Passkey
+		var signCount int64
+		var transports string
+
+		err = rs.Scan(
+			&p.ID,
+			&p.UserID,
+			&p.CredentialID,
+			&p.PublicKey,
+			&p.AttestationType,
+			&p.AAGUID,
+			&signCount,
+			&transports,
+			&p.BackupEligible,
+			&p.BackupState,
+			&p.Name,
+			&p.CreatedAt,
+			&p.LastUsedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		p.SignCount = uint32(signCount)
+		if transports != "" {
+			p.Transports = strings.Split(transports, ",")
+		}
+
+		passkeys = append(passkeys, &p)
+	}
+
+	return passkeys, rs.Err()
+}
+
+func (r *PasskeyRepository) UpdatePasskeyUsage(ctx context.Context, id int64, signCount uint32, backupState bool, at time.Time) error {
+	query := `
+		UPDATE passkeys
+		SET sign_count = $2, backup_state = $3, last_used_at = $4
+		WHERE id = $1
+	`
+
+	err := r.db.writer().exec(ctx, query, id, int64(signCount), backupState, at)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *PasskeyRepository) RenamePasskey(ctx context.Context, userID, id int64, name string) (bool, error) {
+	query := `
+		UPDATE passkeys
+		SET name = $3
+		WHERE id = $2 AND user_id = $1
+		RETURNING id
+	`
+
+	return r.affected(r.db.writer().queryRow(ctx, query, userID, id, name))
+}
+
+func (r *PasskeyRepository) DeletePasskey(ctx context.Context, userID, id int64) (bool, error) {
+	query := `
+		DELETE FROM passkeys
+		WHERE id = $2 AND user_id = $1
+		RETURNING id
+	`
+
+	return r.affected(r.db.writer().queryRow(ctx, query, userID, id))
+}
+
+// affected reads id returned by an UPDATE or DELETE, no rows means nothing matched
+func (r *PasskeyRepository) affected(rw row) (bool, error) {
+	var id int64
+	err := rw.Scan(&id)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	return true, nil
+}
+
+func (r *PasskeyRepository) ConsumePasskeyChallenge(ctx context.Context, challenge string, expiresAt time.Time) (bool, error) {
+	query := `
+		INSERT INTO passkey_challenges (challenge, expires_at)
+		VALUES ($1, $2)
+		ON CONFLICT (challenge) DO NOTHING
+		RETURNING challenge
+	`
+
+	var consumed string
+	err := r.db.writer().queryRow(ctx, query, challenge, expiresAt).Scan(&consumed)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	return true, nil
+}
@@ -0,0 +1,81 @@
+package pgrepositories
+
+import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// PoolConfig configures native pgx pool.
+type PoolConfig struct {
+	MaxConns int32
+	MinConns int32
+
+	// StatementCacheCapacity is number of prepared statements cached per connection,
+	// zero keeps pgx default.
+	StatementCacheCapacity int
+
+	// Tracer is optional, see QueryTracer.
+	Tracer pgx.QueryTracer
+}
+
+// NewPool creates `pgxpool.Pool` which prepares & caches every statement it runs.
+func NewPool(ctx context.Context, dsn string, cfg PoolConfig) (*pgxpool.Pool, error) {
+	pcfg, err := pgxpool.ParseConfig(dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	if cfg.MaxConns > 0 {
+		pcfg.MaxConns = cfg.MaxConns
+	}
+
+	if cfg.MinConns > 0 {
+		pcfg.MinConns = cfg.MinConns
+	}
+
+	pcfg.ConnConfig.DefaultQueryExecMode = pgx.QueryExecModeCacheStatement
+	if cfg.StatementCacheCapacity > 0 {
+		pcfg.ConnConfig.StatementCacheCapacity = cfg.StatementCacheCapacity
+	}
+
+	pcfg.ConnConfig.Tracer = cfg.Tracer
+
+	return pgxpool.NewWithConfig(ctx, pcfg)
+}
+
+// PoolStat is snapshot of `pgxpool.Stat` ready to be exposed for monitoring.
+type PoolStat struct {
+	AcquireCount            int64   `json:"acquire_count"`
+	AcquireDurationSeconds  float64 `json:"acquire_duration_seconds"`
+	AcquiredConns           int32   `json:"acquired_conns"`
+	CanceledAcquireCount    int64   `json:"canceled_acquire_count"`
+	ConstructingConns       int32   `json:"constructing_conns"`
+	EmptyAcquireCount       int64   `json:"empty_acquire_count"`
+	IdleConns               int32   `json:"idle_conns"`
+	MaxConns                int32   `json:"max_conns"`
+	TotalConns              int32   `json:"total_conns"`
+	NewConnsCount           int64   `json:"new_conns_count"`
+	MaxLifetimeDestroyCount int64   `json:"max_lifetime_destroy_count"`
+	MaxIdleDestroyCount     int64   `json:"max_idle_destroy_count"`
+}
+
+func PoolStats(pool *pgxpool.Pool) PoolStat {
+	st := pool.Stat()
+
+	return PoolStat{
+		AcquireCount:            st.AcquireCount(),
+		AcquireDurationSeconds:  st.AcquireDuration().Seconds(),
+		AcquiredConns:           st.AcquiredConns(),
+		CanceledAcquireCount:    st.CanceledAcquireCount(),
+		ConstructingConns:       st.ConstructingConns(),
+		EmptyAcquireCount:       st.EmptyAcquireCount(),
+		IdleConns:               st.IdleConns(),
+		MaxConns:                st.MaxConns(),
+		TotalConns:              st.TotalConns(),
+		NewConnsCount:           st.NewConnsCount(),
+		MaxLifetimeDestroyCount: st.MaxLifetimeDestroyCount(),
+		MaxIdleDestroyCount:     st.MaxIdleDestroyCount(),
+	}
+}
@@ -0,0 +1,143 @@
+package pgrepositories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"waizlytest/repositories"
+)
+
+var _ (repositories.SessionWriter) = (*SessionRepository)(nil)
+var _ (repositories.SessionReader) = (*SessionRepository)(nil)
+
+// SessionRepository implementation both of `repositories.SessionWriter` & `repositories.SessionReader`
+type SessionRepository struct {
+	db *DB
+}
+
+func NewSessionRepository(db *DB) *SessionRepository {
+	return &SessionRepository{
+		db: db,
+	}
+}
+
+const sessionColumns = `id, user_id, method, device_name, user_agent, ip, created_at, last_seen_at, expires_at, revoked_at`
+
+func scanSession(rw row) (*repositories.Session, error) {
+	var s repositories.Session
+	err := rw.Scan(
+		&s.ID,
+		&s.UserID,
+		&s.Method,
+		&s.DeviceName,
+		&s.UserAgent,
+		&s.IP,
+		&s.CreatedAt,
+		&s.LastSeenAt,
+		&s.ExpiresAt,
+		&s.RevokedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &s, nil
+}
+
+func (r *SessionRepository) CreateSession(ctx context.Context, s *repositories.Session) error {
+	query := `
+		INSERT INTO sessions (id, user_id, method, device_name, user_agent, ip, created_at, last_seen_at, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+
+	err := r.db.writer().exec(ctx, query, s.ID, s.UserID, s.Method, s.DeviceName, s.UserAgent, s.IP, s.CreatedAt, s.LastSeenAt, s.ExpiresAt)
+	if err != nil {
+		return mapError(err)
+	}
+
+	return nil
+}
+
+func (r *SessionRepository) FindSession(ctx context.Context, id string) (*repositories.Session, error) {
+	query := `
+		SELECT ` + sessionColumns + `
+		FROM sessions
+		WHERE id = $1
+		LIMIT 1
+	`
+
+	s, err := scanSession(r.db.reader(ctx).queryRow(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func (r *SessionRepository) FindActiveSessionsByUser(ctx context.Context, userID int64, now time.Time) ([]*repositories.Session, error) {
+	query := `
+		SELECT ` + sessionColumns + `
+		FROM sessions
+		WHERE user_id = $1 AND revoked_at IS NULL AND expires_at > $2
+		ORDER BY last_seen_at DESC
+	`
+
+	rs, err := r.db.reader(ctx).query(ctx, query, userID, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rs.Close()
+
+	sessions := []*repositories.Session{}
+	for rs.Next() {
+		s, err := scanSession(rs)
+		if err != nil {
+			return nil, err
+		}
+
+		sessions = append(sessions, s)
+	}
+
+	return sessions, rs.Err()
+}
+
+func (r *SessionRepository) TouchSession(ctx context.Context, id string, at time.Time) error {
+	query := `
+		UPDATE sessions
+		SET last_seen_at = $2
+		WHERE id = $1
+	`
+
+	err := r.db.writer().exec(ctx, query, id, at)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *SessionRepository) RevokeSession(ctx context.Context, userID int64, id string, at time.Time) (bool, error) {
+	query := `
+		UPDATE sessions
+		SET revoked_at = $3
+		WHERE id = $2 AND user_id = $1 AND revoked_at IS NULL
+		RETURNING id
+	`
+
+	var revoked string
+	err := r.db.writer().queryRow(ctx, query, userID, id, at).Scan(&revoked)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	return true, nil
+}
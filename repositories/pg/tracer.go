@@ -0,0 +1,98 @@
+package pgrepositories
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+var _ (pgx.QueryTracer) = (*QueryTracer)(nil)
+
+// QueryStats is aggregated numbers of a single query.
+type QueryStats struct {
+	Calls         int64   `json:"calls"`
+	Errors        int64   `json:"errors"`
+	Rows          int64   `json:"rows"`
+	TotalSeconds  float64 `json:"total_seconds"`
+	MaxSeconds    float64 `json:"max_seconds"`
+	LastError     string  `json:"last_error,omitempty"`
+	LastErrorTime string  `json:"last_error_time,omitempty"`
+}
+
+// QueryTracer implements `pgx.QueryTracer`, it records latency, row count and errors per query.
+type QueryTracer struct {
+	mu    sync.Mutex
+	stats map[string]*QueryStats
+}
+
+func NewQueryTracer() *QueryTracer {
+	return &QueryTracer{
+		stats: map[string]*QueryStats{},
+	}
+}
+
+type traceKey struct{}
+
+type traceStart struct {
+	sql string
+	at  time.Time
+}
+
+func (t *QueryTracer) TraceQueryStart(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	return context.WithValue(ctx, traceKey{}, traceStart{sql: data.SQL, at: time.Now()})
+}
+
+func (t *QueryTracer) TraceQueryEnd(ctx context.Context, _ *pgx.Conn, data pgx.TraceQueryEndData) {
+	start, ok := ctx.Value(traceKey{}).(traceStart)
+	if !ok {
+		return
+	}
+
+	elapsed := time.Since(start.at).Seconds()
+	key := compactSQL(start.sql)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	st, ok := t.stats[key]
+	if !ok {
+		st = &QueryStats{}
+		t.stats[key] = st
+	}
+
+	st.Calls++
+	st.TotalSeconds += elapsed
+	if elapsed > st.MaxSeconds {
+		st.MaxSeconds = elapsed
+	}
+
+	if data.Err != nil {
+		st.Errors++
+		st.LastError = data.Err.Error()
+		st.LastErrorTime = time.Now().Format(time.RFC3339)
+		return
+	}
+
+	st.Rows += data.CommandTag.RowsAffected()
+}
+
+// Stats returns snapshot of every query recorded so far, keyed by its SQL.
+func (t *QueryTracer) Stats() map[string]QueryStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	snapshot := make(map[string]QueryStats, len(t.stats))
+	for k, v := range t.stats {
+		snapshot[k] = *v
+	}
+
+	return snapshot
+}
+
+// compactSQL collapses whitespaces, so multiline queries become readable keys
+func compactSQL(sql string) string {
+	return strings.Join(strings.Fields(sql), " ")
+}
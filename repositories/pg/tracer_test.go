@@ -0,0 +1,77 @@
+package pgrepositories_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+
+	pgrepositories "waizlytest/repositories/pg"
+)
+
+func trace(tracer *pgrepositories.QueryTracer, sql string, tag string, err error) {
+	ctx := tracer.TraceQueryStart(context.TODO(), nil, pgx.TraceQueryStartData{SQL: sql})
+	tracer.TraceQueryEnd(ctx, nil, pgx.TraceQueryEndData{CommandTag: pgconn.NewCommandTag(tag), Err: err})
+}
+
+func TestQueryTracer(t *testing.T) {
+	tracer := pgrepositories.NewQueryTracer()
+
+	trace(tracer, "SELECT id\n\t\tFROM users\n\t\tWHERE id = $1", "SELECT 1", nil)
+	trace(tracer, "SELECT id FROM users WHERE id = $1", "SELECT 1", nil)
+	trace(tracer, "SELECT id FROM users WHERE id = $1", "", errors.New("conn closed"))
+	trace(tracer, "UPDATE users SET fullname = $1", "UPDATE 3", nil)
+
+	// end without start is ignored
+	tracer.TraceQueryEnd(context.TODO(), nil, pgx.TraceQueryEndData{CommandTag: pgconn.NewCommandTag("DELETE 9")})
+
+	expected := map[string]pgrepositories.QueryStats{
+		"SELECT id FROM users WHERE id = $1": {Calls: 3, Errors: 1, Rows: 2, LastError: "conn closed"},
+		"UPDATE users SET fullname = $1":     {Calls: 1, Rows: 3},
+	}
+
+	got := tracer.Stats()
+	diff := cmp.Diff(expected, got, cmpopts.IgnoreFields(pgrepositories.QueryStats{}, "TotalSeconds", "MaxSeconds", "LastErrorTime"))
+	if diff != "" {
+		t.Errorf("stats mismatch (-want +got):\n%s", diff)
+	}
+
+	st := got["SELECT id FROM users WHERE id = $1"]
+	if st.MaxSeconds > st.TotalSeconds || st.LastErrorTime == "" {
+		t.Errorf("unexpected timings: %+v", st)
+	}
+
+	// snapshot is a copy
+	got["UPDATE users SET fullname = $1"] = pgrepositories.QueryStats{}
+	if tracer.Stats()["UPDATE users SET fullname = $1"].Calls != 1 {
+		t.Errorf("expected stats untouched by snapshot changes")
+	}
+}
+
+func TestQueryTracer_Concurrent(t *testing.T) {
+	tracer := pgrepositories.NewQueryTracer()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < 100; j++ {
+				trace(tracer, "SELECT 1", "SELECT 1", nil)
+				tracer.Stats()
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if st := tracer.Stats()["SELECT 1"]; st.Calls != 800 || st.Rows != 800 {
+		t.Errorf("expected 800 calls & rows, got %+v", st)
+	}
+}
@@ -0,0 +1,131 @@
+package pgrepositories
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+var tracer = otel.Tracer("waizlytest/repositories/pg")
+
+// tracedConn wraps conn, so every query gets its own client span
+type tracedConn struct {
+	conn conn
+}
+
+func (c tracedConn) exec(ctx context.Context, query string, args ...any) error {
+	ctx, span := startSpan(ctx, query)
+	defer span.End()
+
+	err := c.conn.exec(ctx, query, args...)
+	endSpan(span, err)
+	return err
+}
+
+func (c tracedConn) queryRow(ctx context.Context, query string, args ...any) row {
+	ctx, span := startSpan(ctx, query)
+	return tracedRow{row: c.conn.queryRow(ctx, query, args...), span: span}
+}
+
+func (c tracedConn) query(ctx context.Context, query string, args ...any) (rows, error) {
+	ctx, span := startSpan(ctx, query)
+
+	rs, err := c.conn.query(ctx, query, args...)
+	if err != nil {
+		endSpan(span, err)
+		span.End()
+		return nil, err
+	}
+
+	return &tracedRows{rows: rs, span: span}, nil
+}
+
+func (c tracedConn) ping(ctx context.Context) error {
+	return c.conn.ping(ctx)
+}
+
+// tracedRow ends the span once the row is read
+type tracedRow struct {
+	row  row
+	span trace.Span
+}
+
+func (r tracedRow) Scan(dest ...any) error {
+	defer r.span.End()
+
+	err := r.row.Scan(dest...)
+	// no rows is a valid answer, not a failure
+	if err != sql.ErrNoRows {
+		endSpan(r.span, err)
+	}
+
+	return err
+}
+
+// tracedRows ends the span once the rows are closed
+type tracedRows struct {
+	rows
+	span trace.Span
+}
+
+func (r *tracedRows) Close() {
+	r.rows.Close()
+	endSpan(r.span, r.rows.Err())
+	r.span.End()
+}
+
+func startSpan(ctx context.Context, query string) (context.Context, trace.Span) {
+	operation, table := summarize(query)
+
+	return tracer.Start(ctx, strings.TrimSpace(operation+" "+table),
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			semconv.DBSystemPostgreSQL,
+			semconv.DBOperationName(operation),
+			semconv.DBCollectionName(table),
+			semconv.DBQueryText(compactSQL(query)),
+		),
+	)
+}
+
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+}
+
+// summarize returns SQL operation & the table it works on, e.g `SELECT`, `users`
+func summarize(query string) (string, string) {
+	words := strings.Fields(query)
+	if len(words) == 0 {
+		return "", ""
+	}
+
+	operation := strings.ToUpper(words[0])
+
+	after := ""
+	switch operation {
+	case "SELECT", "DELETE":
+		after = "FROM"
+	case "INSERT":
+		after = "INTO"
+	case "UPDATE":
+		if len(words) > 1 {
+			return operation, words[1]
+		}
+	}
+
+	for i, w := range words {
+		if strings.EqualFold(w, after) && i+1 < len(words) {
+			return operation, words[i+1]
+		}
+	}
+
+	return operation, ""
+}
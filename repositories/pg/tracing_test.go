@@ -0,0 +1,192 @@
+package pgrepositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestSummarize(t *testing.T) {
+	scenarios := []struct {
+		name      string
+		query     string
+		operation string
+		table     string
+	}{
+		{name: "[OK] Select", query: "SELECT id, phone FROM users WHERE id = $1", operation: "SELECT", table: "users"},
+		{name: "[OK] Multiline lowercase", query: "\n\t\tselect id\n\t\tfrom sessions\n\t\twhere id = $1", operation: "SELECT", table: "sessions"},
+		{name: "[OK] Insert", query: "INSERT INTO api_keys (id) VALUES ($1)", operation: "INSERT", table: "api_keys"},
+		{name: "[OK] Update", query: "UPDATE users SET phone = $1", operation: "UPDATE", table: "users"},
+		{name: "[OK] Delete", query: "DELETE FROM passkeys WHERE id = $1", operation: "DELETE", table: "passkeys"},
+		{name: "[OK] No table", query: "SELECT 1", operation: "SELECT"},
+		{name: "[OK] Other operation", query: "BEGIN", operation: "BEGIN"},
+		{name: "[OK] Update without table", query: "UPDATE", operation: "UPDATE"},
+		{name: "[Failed] Empty query", query: "  "},
+	}
+
+	for _, scn := range scenarios {
+		t.Run(scn.name, func(t *testing.T) {
+			operation, table := summarize(scn.query)
+			if operation != scn.operation || table != scn.table {
+				t.Errorf("expected %q %q, got %q %q", scn.operation, scn.table, operation, table)
+			}
+		})
+	}
+}
+
+func TestTracedConn(t *testing.T) {
+	recorder := tracetest.NewSpanRecorder()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(recorder))
+
+	prev := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { otel.SetTracerProvider(prev) })
+
+	failure := errors.New("conn closed")
+
+	scenarios := []struct {
+		name string
+		err  error
+		run  func(c conn) error
+		// span is expected name, its operation & table attributes are the two words of it
+		span   string
+		query  string
+		status codes.Code
+	}{
+		{
+			name: "[OK] Exec",
+			run: func(c conn) error {
+				return c.exec(context.TODO(), "INSERT INTO users\n\t(fullname) VALUES ($1)", "Teest")
+			},
+			span:   "INSERT users",
+			query:  "INSERT INTO users (fullname) VALUES ($1)",
+			status: codes.Unset,
+		},
+		{
+			name: "[OK] Query row",
+			run: func(c conn) error {
+				return c.queryRow(context.TODO(), "SELECT fullname FROM users WHERE id = $1", 1).Scan()
+			},
+			span:   "SELECT users",
+			query:  "SELECT fullname FROM users WHERE id = $1",
+			status: codes.Unset,
+		},
+		{
+			name: "[OK] No rows is not a failure",
+			err:  sql.ErrNoRows,
+			run: func(c conn) error {
+				err := c.queryRow(context.TODO(), "SELECT fullname FROM users WHERE id = $1", 1).Scan()
+				if errors.Is(err, sql.ErrNoRows) {
+					return nil
+				}
+
+				return err
+			},
+			span:   "SELECT users",
+			query:  "SELECT fullname FROM users WHERE id = $1",
+			status: codes.Unset,
+		},
+		{
+			name: "[OK] Query ends on close",
+			run: func(c conn) error {
+				rs, err := c.query(context.TODO(), "SELECT id FROM sessions WHERE user_id = $1", 1)
+				if err != nil {
+					return err
+				}
+
+				rs.Close()
+				return nil
+			},
+			span:   "SELECT sessions",
+			query:  "SELECT id FROM sessions WHERE user_id = $1",
+			status: codes.Unset,
+		},
+		{
+			name: "[Failed] Exec",
+			err:  failure,
+			run: func(c conn) error {
+				err := c.exec(context.TODO(), "UPDATE users SET phone = $1", "+62812")
+				if !errors.Is(err, failure) {
+					return errors.New("expected error of conn")
+				}
+
+				return nil
+			},
+			span:   "UPDATE users",
+			query:  "UPDATE users SET phone = $1",
+			status: codes.Error,
+		},
+		{
+			name: "[Failed] Query",
+			err:  failure,
+			run: func(c conn) error {
+				_, err := c.query(context.TODO(), "SELECT id FROM sessions", 1)
+				if !errors.Is(err, failure) {
+					return errors.New("expected error of conn")
+				}
+
+				return nil
+			},
+			span:   "SELECT sessions",
+			query:  "SELECT id FROM sessions",
+			status: codes.Error,
+		},
+	}
+
+	for _, scn := range scenarios {
+		t.Run(scn.name, func(t *testing.T) {
+			before := len(recorder.Ended())
+
+			err := scn.run(tracedConn{&stubConn{name: "primary", err: scn.err}})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			spans := recorder.Ended()[before:]
+			if len(spans) != 1 {
+				t.Fatalf("expected 1 ended span, got %d", len(spans))
+			}
+
+			span := spans[0]
+			if span.Name() != scn.span || span.SpanKind() != trace.SpanKindClient {
+				t.Errorf("expected client span %q, got %s %q", scn.span, span.SpanKind(), span.Name())
+			}
+
+			if span.Status().Code != scn.status {
+				t.Errorf("status mismatch: want %v, got %v", scn.status, span.Status().Code)
+			}
+
+			if scn.status == codes.Error && len(span.Events()) == 0 {
+				t.Errorf("expected error recorded on span")
+			}
+
+			got := map[attribute.Key]string{}
+			for _, kv := range span.Attributes() {
+				got[kv.Key] = kv.Value.Emit()
+			}
+
+			operation, table, _ := strings.Cut(scn.span, " ")
+			expected := map[attribute.Key]string{
+				"db.system":          "postgresql",
+				"db.operation.name":  operation,
+				"db.collection.name": table,
+				"db.query.text":      scn.query,
+			}
+
+			diff := cmp.Diff(expected, got)
+			if diff != "" {
+				t.Errorf("attributes mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
@@ -12,34 +12,64 @@ var _ (repositories.UserReader) = (*UserRepository)(nil)
 
 // UserRepository implementation both of `repositories.UserWriter` & `repositories.UserReader`
 type UserRepository struct {
-	db *sql.DB
+	db *DB
 }
 
-func NewUserRepository(db *sql.DB) *UserRepository {
+func NewUserRepository(db *DB) *UserRepository {
 	return &UserRepository{
 		db: db,
 	}
 }
 
+// userColumns are selected by every finder, see scanUser.
+// Phone & email are optional, kept as empty string on the model.
+const userColumns = `id, fullname, password, COALESCE(phone, ''), COALESCE(email, ''), email_verified_at, created_at, updated_at, deleted_at`
+
+func scanUser(rw row) (*repositories.User, error) {
+	var user repositories.User
+	err := rw.Scan(
+		&user.ID,
+		&user.FullName,
+		&user.Password,
+		&user.Phone,
+		&user.Email,
+		&user.EmailVerifiedAt,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+		&user.DeletedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return &user, nil
+}
+
 func (r *UserRepository) CreateUser(ctx context.Context, u *repositories.User) (int64, error) {
 	query := `
-		INSERT INTO users (fullname, password, phone, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO users (fullname, password, phone, email, email_verified_at, created_at, updated_at)
+		VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, ''), $5, $6, $7)
 		RETURNING id
 	`
 
 	var id int64
-	err := r.db.QueryRowContext(
+	err := r.db.writer().queryRow(
 		ctx,
 		query,
 		u.FullName,
 		u.Password,
 		u.Phone,
+		u.Email,
+		u.EmailVerifiedAt,
 		u.CreatedAt,
 		u.UpdatedAt,
 	).Scan(&id)
 	if err != nil {
-		return 0, err
+		return 0, mapError(err)
 	}
 
 	return id, nil
@@ -47,60 +77,36 @@ func (r *UserRepository) CreateUser(ctx context.Context, u *repositories.User) (
 
 func (r *UserRepository) FindUserByPhone(ctx context.Context, phone string) (*repositories.User, error) {
 	query := `
-		SELECT id, fullname, password, phone, created_at, updated_at, deleted_at
+		SELECT ` + userColumns + `
 		FROM users
-		WHERE phone = $1
+		WHERE phone = $1 AND deleted_at IS NULL
 		LIMIT 1
 	`
 
-	var user repositories.User
-	err := r.db.QueryRowContext(ctx, query, phone).Scan(
-		&user.ID,
-		&user.FullName,
-		&user.Password,
-		&user.Phone,
-		&user.CreatedAt,
-		&user.UpdatedAt,
-		&user.DeletedAt,
-	)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, nil
-		}
+	return scanUser(r.db.reader(ctx).queryRow(ctx, query, phone))
+}
 
-		return nil, err
-	}
+// FindUserByEmail only finds active user whose email is verified.
+func (r *UserRepository) FindUserByEmail(ctx context.Context, email string) (*repositories.User, error) {
+	query := `
+		SELECT ` + userColumns + `
+		FROM users
+		WHERE email = $1 AND email_verified_at IS NOT NULL AND deleted_at IS NULL
+		LIMIT 1
+	`
 
-	return &user, nil
+	return scanUser(r.db.reader(ctx).queryRow(ctx, query, email))
 }
 
 func (r *UserRepository) FindUserByID(ctx context.Context, id int64) (*repositories.User, error) {
 	query := `
-		SELECT id, fullname, password,  phone, created_at, updated_at, deleted_at
+		SELECT ` + userColumns + `
 		FROM users
 		WHERE id = $1
 		LIMIT 1
 	`
 
-	var user repositories.User
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&user.ID,
-		&user.FullName,
-		&user.Password,
-		&user.Phone,
-		&user.CreatedAt,
-		&user.UpdatedAt,
-		&user.DeletedAt,
-	)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			return nil, nil
-		}
-
-		return nil, err
-	}
-
-	return &user, nil
+	return scanUser(r.db.reader(ctx).queryRow(ctx, query, id))
 }
 
 func (r *UserRepository) CreateUserAttendance(ctx context.Context, ua *repositories.UserAttendance) error {
@@ -109,7 +115,7 @@ func (r *UserRepository) CreateUserAttendance(ctx context.Context, ua *repositor
 		VALUES ($1, $2)
 	`
 
-	_, err := r.db.ExecContext(ctx, query, ua.UserID, ua.LoginAt)
+	err := r.db.writer().exec(ctx, query, ua.UserID, ua.LoginAt)
 	if err != nil {
 		return err
 	}
@@ -120,21 +126,23 @@ func (r *UserRepository) CreateUserAttendance(ctx context.Context, ua *repositor
 func (r *UserRepository) UpdateUser(ctx context.Context, u *repositories.User) error {
 	query := `
 		UPDATE users
-		SET fullname = $2, phone = $3, updated_at = $4, deleted_at = $5
+		SET fullname = $2, phone = NULLIF($3, ''), email = NULLIF($4, ''), email_verified_at = $5, updated_at = $6, deleted_at = $7
 		WHERE id = $1
 	`
 
-	_, err := r.db.ExecContext(
+	err := r.db.writer().exec(
 		ctx,
 		query,
 		u.ID,
 		u.FullName,
 		u.Phone,
+		u.Email,
+		u.EmailVerifiedAt,
 		u.UpdatedAt,
 		u.DeletedAt,
 	)
 	if err != nil {
-		return err
+		return mapError(err)
 	}
 
 	return nil
@@ -148,7 +156,7 @@ func (r *UserRepository) SaveUserAttendanceSummary(ctx context.Context, userID i
 		DO UPDATE SET total_login = user_attendance_summaries.total_login + EXCLUDED.total_login
 	`
 
-	_, err := r.db.ExecContext(ctx, query, userID)
+	err := r.db.writer().exec(ctx, query, userID)
 	if err != nil {
 		return err
 	}
@@ -0,0 +1,67 @@
+package promrepositories
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	pgrepositories "waizlytest/repositories/pg"
+)
+
+var _ (prometheus.Collector) = (*PoolCollector)(nil)
+
+// PoolCollector exposes native pgx pool stats, the counterpart of
+// `collectors.NewDBStatsCollector` for `database/sql`.
+type PoolCollector struct {
+	stat func() pgrepositories.PoolStat
+
+	maxConns        *prometheus.Desc
+	totalConns      *prometheus.Desc
+	idleConns       *prometheus.Desc
+	acquiredConns   *prometheus.Desc
+	acquireCount    *prometheus.Desc
+	acquireDuration *prometheus.Desc
+	emptyAcquire    *prometheus.Desc
+	canceledAcquire *prometheus.Desc
+}
+
+func NewPoolCollector(name string, stat func() pgrepositories.PoolStat) *PoolCollector {
+	labels := prometheus.Labels{"db_name": name}
+	desc := func(n, help string) *prometheus.Desc {
+		return prometheus.NewDesc("pgxpool_"+n, help, nil, labels)
+	}
+
+	return &PoolCollector{
+		stat:            stat,
+		maxConns:        desc("max_conns", "Maximum size of the pool."),
+		totalConns:      desc("total_conns", "Total number of connections in the pool."),
+		idleConns:       desc("idle_conns", "Number of idle connections in the pool."),
+		acquiredConns:   desc("acquired_conns", "Number of currently acquired connections."),
+		acquireCount:    desc("acquire_total", "Cumulative count of successful acquires."),
+		acquireDuration: desc("acquire_duration_seconds_total", "Total duration of successful acquires."),
+		emptyAcquire:    desc("empty_acquire_total", "Acquires which had to wait for a connection."),
+		canceledAcquire: desc("canceled_acquire_total", "Acquires canceled by context."),
+	}
+}
+
+func (c *PoolCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.maxConns
+	ch <- c.totalConns
+	ch <- c.idleConns
+	ch <- c.acquiredConns
+	ch <- c.acquireCount
+	ch <- c.acquireDuration
+	ch <- c.emptyAcquire
+	ch <- c.canceledAcquire
+}
+
+func (c *PoolCollector) Collect(ch chan<- prometheus.Metric) {
+	st := c.stat()
+
+	ch <- prometheus.MustNewConstMetric(c.maxConns, prometheus.GaugeValue, float64(st.MaxConns))
+	ch <- prometheus.MustNewConstMetric(c.totalConns, prometheus.GaugeValue, float64(st.TotalConns))
+	ch <- prometheus.MustNewConstMetric(c.idleConns, prometheus.GaugeValue, float64(st.IdleConns))
+	ch <- prometheus.MustNewConstMetric(c.acquiredConns, prometheus.GaugeValue, float64(st.AcquiredConns))
+	ch <- prometheus.MustNewConstMetric(c.acquireCount, prometheus.CounterValue, float64(st.AcquireCount))
+	ch <- prometheus.MustNewConstMetric(c.acquireDuration, prometheus.CounterValue, st.AcquireDurationSeconds)
+	ch <- prometheus.MustNewConstMetric(c.emptyAcquire, prometheus.CounterValue, float64(st.EmptyAcquireCount))
+	ch <- prometheus.MustNewConstMetric(c.canceledAcquire, prometheus.CounterValue, float64(st.CanceledAcquireCount))
+}
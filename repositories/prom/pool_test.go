@@ -0,0 +1,54 @@
+package promrepositories_test
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/prometheus/client_golang/prometheus"
+
+	pgrepositories "waizlytest/repositories/pg"
+	promrepositories "waizlytest/repositories/prom"
+)
+
+func TestPoolCollector(t *testing.T) {
+	stat := pgrepositories.PoolStat{
+		AcquireCount:           7,
+		AcquireDurationSeconds: 0.5,
+		AcquiredConns:          2,
+		CanceledAcquireCount:   1,
+		EmptyAcquireCount:      4,
+		IdleConns:              3,
+		MaxConns:               10,
+		TotalConns:             5,
+	}
+
+	reg := prometheus.NewRegistry()
+	reg.MustRegister(promrepositories.NewPoolCollector("primary", func() pgrepositories.PoolStat { return stat }))
+
+	expected := map[string]float64{
+		"pgxpool_max_conns":                      10,
+		"pgxpool_total_conns":                    5,
+		"pgxpool_idle_conns":                     3,
+		"pgxpool_acquired_conns":                 2,
+		"pgxpool_acquire_total":                  7,
+		"pgxpool_acquire_duration_seconds_total": 0.5,
+		"pgxpool_empty_acquire_total":            4,
+		"pgxpool_canceled_acquire_total":         1,
+	}
+
+	got := map[string]float64{}
+	for name := range expected {
+		for labels, v := range gather(t, reg, name) {
+			if labels != "primary" {
+				t.Errorf("expected %s labelled by db_name primary, got %q", name, labels)
+			}
+
+			got[name] = v
+		}
+	}
+
+	diff := cmp.Diff(expected, got)
+	if diff != "" {
+		t.Errorf("pool stats mismatch (-want +got):\n%s", diff)
+	}
+}
@@ -0,0 +1,109 @@
+package promrepositories
+
+import (
+	"context"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	"waizlytest/repositories"
+)
+
+var _ (repositories.UserWriter) = (*UserRepository)(nil)
+var _ (repositories.UserReader) = (*UserRepository)(nil)
+
+// UserRepository decorates both `repositories.UserWriter` & `repositories.UserReader`
+// with query latency histogram and registration counter.
+type UserRepository struct {
+	writer repositories.UserWriter
+	reader repositories.UserReader
+
+	duration      *prometheus.HistogramVec
+	registrations prometheus.Counter
+}
+
+func NewUserRepository(
+	writer repositories.UserWriter,
+	reader repositories.UserReader,
+	reg prometheus.Registerer,
+) *UserRepository {
+	r := &UserRepository{
+		writer: writer,
+		reader: reader,
+		duration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "repository_query_duration_seconds",
+			Help:    "Duration of repository calls by operation and result.",
+			Buckets: prometheus.DefBuckets,
+		}, []string{"operation", "result"}),
+		registrations: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "user_registrations_total",
+			Help: "Users successfully registered.",
+		}),
+	}
+
+	reg.MustRegister(r.duration, r.registrations)
+	return r
+}
+
+func (r *UserRepository) observe(operation string, start time.Time, err error) {
+	result := "ok"
+	if err != nil {
+		result = "error"
+	}
+
+	r.duration.WithLabelValues(operation, result).Observe(time.Since(start).Seconds())
+}
+
+func (r *UserRepository) CreateUser(ctx context.Context, u *repositories.User) (int64, error) {
+	start := time.Now()
+	id, err := r.writer.CreateUser(ctx, u)
+	r.observe("CreateUser", start, err)
+
+	if err == nil {
+		r.registrations.Inc()
+	}
+
+	return id, err
+}
+
+func (r *UserRepository) UpdateUser(ctx context.Context, u *repositories.User) error {
+	start := time.Now()
+	err := r.writer.UpdateUser(ctx, u)
+	r.observe("UpdateUser", start, err)
+	return err
+}
+
+func (r *UserRepository) CreateUserAttendance(ctx context.Context, ua *repositories.UserAttendance) error {
+	start := time.Now()
+	err := r.writer.CreateUserAttendance(ctx, ua)
+	r.observe("CreateUserAttendance", start, err)
+	return err
+}
+
+func (r *UserRepository) SaveUserAttendanceSummary(ctx context.Context, userID int64) error {
+	start := time.Now()
+	err := r.writer.SaveUserAttendanceSummary(ctx, userID)
+	r.observe("SaveUserAttendanceSummary", start, err)
+	return err
+}
+
+func (r *UserRepository) FindUserByPhone(ctx context.Context, phone string) (*repositories.User, error) {
+	start := time.Now()
+	usr, err := r.reader.FindUserByPhone(ctx, phone)
+	r.observe("FindUserByPhone", start, err)
+	return usr, err
+}
+
+func (r *UserRepository) FindUserByEmail(ctx context.Context, email string) (*repositories.User, error) {
+	start := time.Now()
+	usr, err := r.reader.FindUserByEmail(ctx, email)
+	r.observe("FindUserByEmail", start, err)
+	return usr, err
+}
+
+func (r *UserRepository) FindUserByID(ctx context.Context, id int64) (*repositories.User, error) {
+	start := time.Now()
+	usr, err := r.reader.FindUserByID(ctx, id)
+	r.observe("FindUserByID", start, err)
+	return usr, err
+}
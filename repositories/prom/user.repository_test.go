@@ -0,0 +1,153 @@
+package promrepositories_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/mock"
+
+	"waizlytest/repositories"
+	mockrepositories "waizlytest/repositories/mock"
+	promrepositories "waizlytest/repositories/prom"
+)
+
+// gather returns samples of the metric keyed by its label values joined by "/",
+// histogram sample is its count
+func gather(t *testing.T, reg *prometheus.Registry, name string) map[string]float64 {
+	t.Helper()
+
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("failed gather: %v", err)
+	}
+
+	got := map[string]float64{}
+	for _, f := range families {
+		if f.GetName() != name {
+			continue
+		}
+
+		for _, m := range f.GetMetric() {
+			var values []string
+			for _, l := range m.GetLabel() {
+				values = append(values, l.GetValue())
+			}
+
+			key := strings.Join(values, "/")
+			switch {
+			case m.GetHistogram() != nil:
+				got[key] = float64(m.GetHistogram().GetSampleCount())
+			case m.GetCounter() != nil:
+				got[key] = m.GetCounter().GetValue()
+			default:
+				got[key] = m.GetGauge().GetValue()
+			}
+		}
+	}
+
+	return got
+}
+
+func TestUserRepository(t *testing.T) {
+	failure := errors.New("conn closed")
+
+	scenarios := []struct {
+		name  string
+		setup func(m *mockrepositories.MockUserRepository)
+		run   func(r *promrepositories.UserRepository) error
+		// expected is keyed by `operation/result`
+		expected      map[string]float64
+		registrations map[string]float64
+	}{
+		{
+			name: "[OK] Create user",
+			setup: func(m *mockrepositories.MockUserRepository) {
+				m.On("CreateUser", mock.Anything, mock.Anything).Return(int64(1), nil)
+			},
+			run: func(r *promrepositories.UserRepository) error {
+				_, err := r.CreateUser(context.TODO(), &repositories.User{})
+				return err
+			},
+			expected:      map[string]float64{"CreateUser/ok": 1},
+			registrations: map[string]float64{"": 1},
+		},
+		{
+			name: "[OK] Find user",
+			setup: func(m *mockrepositories.MockUserRepository) {
+				m.On("FindUserByID", mock.Anything, mock.Anything).Return(&repositories.User{}, nil)
+				m.On("FindUserByPhone", mock.Anything, mock.Anything).Return((*repositories.User)(nil), failure)
+			},
+			run: func(r *promrepositories.UserRepository) error {
+				r.FindUserByID(context.TODO(), 1)
+				r.FindUserByID(context.TODO(), 2)
+				r.FindUserByPhone(context.TODO(), "+62812")
+				return nil
+			},
+			expected:      map[string]float64{"FindUserByID/ok": 2, "FindUserByPhone/error": 1},
+			registrations: map[string]float64{"": 0},
+		},
+		{
+			name: "[Failed] Create user is not a registration",
+			setup: func(m *mockrepositories.MockUserRepository) {
+				m.On("CreateUser", mock.Anything, mock.Anything).Return(int64(0), failure)
+			},
+			run: func(r *promrepositories.UserRepository) error {
+				_, err := r.CreateUser(context.TODO(), &repositories.User{})
+				if !errors.Is(err, failure) {
+					return errors.New("expected error of repository")
+				}
+
+				return nil
+			},
+			expected:      map[string]float64{"CreateUser/error": 1},
+			registrations: map[string]float64{"": 0},
+		},
+		{
+			name: "[Failed] Update user",
+			setup: func(m *mockrepositories.MockUserRepository) {
+				m.On("UpdateUser", mock.Anything, mock.Anything).Return(failure)
+			},
+			run: func(r *promrepositories.UserRepository) error {
+				err := r.UpdateUser(context.TODO(), &repositories.User{})
+				if !errors.Is(err, failure) {
+					return errors.New("expected error of repository")
+				}
+
+				return nil
+			},
+			expected:      map[string]float64{"UpdateUser/error": 1},
+			registrations: map[string]float64{"": 0},
+		},
+	}
+
+	for _, scn := range scenarios {
+		t.Run(scn.name, func(t *testing.T) {
+			usrStorage := &mockrepositories.MockUserRepository{}
+			scn.setup(usrStorage)
+
+			reg := prometheus.NewRegistry()
+			r := promrepositories.NewUserRepository(usrStorage, usrStorage, reg)
+
+			err := scn.run(r)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			diff := cmp.Diff(scn.expected, gather(t, reg, "repository_query_duration_seconds"))
+			if diff != "" {
+				t.Errorf("durations mismatch (-want +got):\n%s", diff)
+			}
+
+			diff = cmp.Diff(scn.registrations, gather(t, reg, "user_registrations_total"))
+			if diff != "" {
+				t.Errorf("registrations mismatch (-want +got):\n%s", diff)
+			}
+
+			usrStorage.AssertExpectations(t)
+		})
+	}
+}
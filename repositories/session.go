@@ -0,0 +1,20 @@
+package repositories
+
+import "time"
+
+// Session is a login of the user, the token refers it by `sid` claim
+type Session struct {
+	ID     string
+	UserID int64
+	// Method of the login, e.g `password`, `otp` or `passkey`
+	Method string
+	// DeviceName is given by the client, UserAgent & IP are taken from the login request
+	DeviceName string
+	UserAgent  string
+	IP         string
+	CreatedAt  time.Time
+	LastSeenAt time.Time
+	// ExpiresAt is expiry of the token
+	ExpiresAt time.Time
+	RevokedAt *time.Time
+}
@@ -0,0 +1,20 @@
+package repositories
+
+import (
+	"context"
+	"time"
+)
+
+type SessionWriter interface {
+	CreateSession(ctx context.Context, s *Session) error
+	// TouchSession updates last seen time of the session
+	TouchSession(ctx context.Context, id string, at time.Time) error
+	// RevokeSession reports false when the user has no such active session
+	RevokeSession(ctx context.Context, userID int64, id string, at time.Time) (bool, error)
+}
+
+type SessionReader interface {
+	FindSession(ctx context.Context, id string) (*Session, error)
+	// FindActiveSessionsByUser finds sessions neither revoked nor expired at now, the latest seen first
+	FindActiveSessionsByUser(ctx context.Context, userID int64, now time.Time) ([]*Session, error)
+}
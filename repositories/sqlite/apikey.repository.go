@@ -0,0 +1,148 @@
+package sqliterepositories
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"waizlytest/repositories"
+)
+
+var _ (repositories.APIKeyWriter) = (*APIKeyRepository)(nil)
+var _ (repositories.APIKeyReader) = (*APIKeyRepository)(nil)
+
+// APIKeyRepository implementation both of `repositories.APIKeyWriter` & `repositories.APIKeyReader`
+// on top of SQLite.
+type APIKeyRepository struct {
+	db *sql.DB
+}
+
+func NewAPIKeyRepository(db *sql.DB) *APIKeyRepository {
+	return &APIKeyRepository{
+		db: db,
+	}
+}
+
+const apiKeyColumns = `id, user_id, name, prefix, secret_hash, scopes, expires_at, created_at, last_used_at`
+
+func scanAPIKey(rw scanner) (*repositories.APIKey, error) {
+	var k repositories.APIKey
+	var scopes string
+
+	err := rw.Scan(
+		&k.ID,
+		&k.UserID,
+		&k.Name,
+		&k.Prefix,
+		&k.SecretHash,
+		&scopes,
+		&k.ExpiresAt,
+		&k.CreatedAt,
+		&k.LastUsedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if scopes != "" {
+		k.Scopes = strings.Split(scopes, ",")
+	}
+
+	return &k, nil
+}
+
+func (r *APIKeyRepository) CreateAPIKey(ctx context.Context, k *repositories.APIKey) (int64, error) {
+	query := `
+		INSERT INTO api_keys (user_id, name, prefix, secret_hash, scopes, expires_at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`
+
+	res, err := r.db.ExecContext(ctx, query, k.UserID, k.Name, k.Prefix, k.SecretHash, strings.Join(k.Scopes, ","), k.ExpiresAt, k.CreatedAt)
+	if err != nil {
+		return 0, mapError(err)
+	}
+
+	return res.LastInsertId()
+}
+
+func (r *APIKeyRepository) FindAPIKeyByPrefix(ctx context.Context, prefix string) (*repositories.APIKey, error) {
+	query := `
+		SELECT ` + apiKeyColumns + `
+		FROM api_keys
+		WHERE prefix = ?
+		LIMIT 1
+	`
+
+	k, err := scanAPIKey(r.db.QueryRowContext(ctx, query, prefix))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return k, nil
+}
+
+func (r *APIKeyRepository) FindAPIKeysByUser(ctx context.Context, userID int64) ([]*repositories.APIKey, error) {
+	query := `
+		SELECT ` + apiKeyColumns + `
+		FROM api_keys
+		WHERE user_id = ?
+		ORDER BY id
+	`
+
+	rs, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rs.Close()
+
+	keys := []*repositories.APIKey{}
+	for rs.Next() {
+		k, err := scanAPIKey(rs)
+		if err != nil {
+			return nil, err
+		}
+
+		keys = append(keys, k)
+	}
+
+	return keys, rs.Err()
+}
+
+func (r *APIKeyRepository) TouchAPIKey(ctx context.Context, id int64, at time.Time) error {
+	query := `
+		UPDATE api_keys
+		SET last_used_at = ?
+		WHERE id = ?
+	`
+
+	_, err := r.db.ExecContext(ctx, query, at, id)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *APIKeyRepository) DeleteAPIKey(ctx context.Context, userID, id int64) (bool, error) {
+	query := `
+		DELETE FROM api_keys
+		WHERE id = ? AND user_id = ?
+	`
+
+	res, err := r.db.ExecContext(ctx, query, id, userID)
+	if err != nil {
+		return false, err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return n == 1, nil
+}
@@ -0,0 +1,21 @@
+package sqliterepositories
+
+import (
+	"errors"
+	"fmt"
+
+	"modernc.org/sqlite"
+	sqlite3 "modernc.org/sqlite/lib"
+
+	"waizlytest/repositories"
+)
+
+// mapError turns unique violation into `repositories.ErrDuplicate`.
+func mapError(err error) error {
+	var sqliteErr *sqlite.Error
+	if errors.As(err, &sqliteErr) && sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE {
+		return fmt.Errorf("%w: %s", repositories.ErrDuplicate, sqliteErr.Error())
+	}
+
+	return err
+}
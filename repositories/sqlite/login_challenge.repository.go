@@ -0,0 +1,102 @@
+package sqliterepositories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"waizlytest/repositories"
+)
+
+var _ (repositories.LoginChallengeWriter) = (*LoginChallengeRepository)(nil)
+var _ (repositories.LoginChallengeReader) = (*LoginChallengeRepository)(nil)
+
+// LoginChallengeRepository implementation both of `repositories.LoginChallengeWriter` & `repositories.LoginChallengeReader`
+// on top of SQLite.
+type LoginChallengeRepository struct {
+	db *sql.DB
+}
+
+func NewLoginChallengeRepository(db *sql.DB) *LoginChallengeRepository {
+	return &LoginChallengeRepository{
+		db: db,
+	}
+}
+
+func (r *LoginChallengeRepository) CreateLoginChallenge(ctx context.Context, c *repositories.LoginChallenge) error {
+	query := `
+		INSERT INTO login_challenges (id, user_id, code_hash, channel, attempts, expires_at, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`
+
+	_, err := r.db.ExecContext(ctx, query, c.ID, c.UserID, c.CodeHash, c.Channel, c.Attempts, c.ExpiresAt, c.CreatedAt)
+	if err != nil {
+		return mapError(err)
+	}
+
+	return nil
+}
+
+func (r *LoginChallengeRepository) FindLoginChallenge(ctx context.Context, id string) (*repositories.LoginChallenge, error) {
+	query := `
+		SELECT id, user_id, code_hash, channel, attempts, expires_at, consumed_at, created_at
+		FROM login_challenges
+		WHERE id = ?
+		LIMIT 1
+	`
+
+	var c repositories.LoginChallenge
+	err := r.db.QueryRowContext(ctx, query, id).Scan(
+		&c.ID,
+		&c.UserID,
+		&c.CodeHash,
+		&c.Channel,
+		&c.Attempts,
+		&c.ExpiresAt,
+		&c.ConsumedAt,
+		&c.CreatedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return &c, nil
+}
+
+func (r *LoginChallengeRepository) CountLoginChallengeAttempt(ctx context.Context, id string, max int) (bool, error) {
+	query := `
+		UPDATE login_challenges
+		SET attempts = attempts + 1
+		WHERE id = ? AND attempts < ? AND consumed_at IS NULL
+	`
+
+	return r.affected(r.db.ExecContext(ctx, query, id, max))
+}
+
+func (r *LoginChallengeRepository) ConsumeLoginChallenge(ctx context.Context, id string, max int, at time.Time) (bool, error) {
+	query := `
+		UPDATE login_challenges
+		SET consumed_at = ?
+		WHERE id = ? AND consumed_at IS NULL AND attempts <= ?
+	`
+
+	return r.affected(r.db.ExecContext(ctx, query, at, id, max))
+}
+
+// affected reports whether an UPDATE matched the row
+func (r *LoginChallengeRepository) affected(res sql.Result, err error) (bool, error) {
+	if err != nil {
+		return false, err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return n == 1, nil
+}
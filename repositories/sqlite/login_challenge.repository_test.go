@@ -0,0 +1,80 @@
+package sqliterepositories_test
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"waizlytest/repositories"
+	sqliterepositories "waizlytest/repositories/sqlite"
+)
+
+func TestLoginChallengeRepository_Attempts(t *testing.T) {
+	ctx := context.TODO()
+	repo := sqliterepositories.NewLoginChallengeRepository(newDB(t))
+
+	now := time.Now().UTC().Truncate(time.Second)
+	err := repo.CreateLoginChallenge(ctx, &repositories.LoginChallenge{
+		ID:        "c1",
+		UserID:    1,
+		CodeHash:  "hash",
+		Channel:   "sms",
+		ExpiresAt: now.Add(time.Minute),
+		CreatedAt: now,
+	})
+	if err != nil {
+		t.Fatalf("failed create challenge: %v", err)
+	}
+
+	// concurrent guesses get no more than max attempts
+	var counted atomic.Int32
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			ok, err := repo.CountLoginChallengeAttempt(ctx, "c1", 3)
+			if err != nil {
+				t.Errorf("failed count attempt: %v", err)
+			}
+
+			if ok {
+				counted.Add(1)
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if n := counted.Load(); n != 3 {
+		t.Fatalf("expected 3 attempts counted, got %d", n)
+	}
+
+	c, err := repo.FindLoginChallenge(ctx, "c1")
+	if err != nil || c.Attempts != 3 {
+		t.Fatalf("expected 3 attempts stored, got %+v, %v", c, err)
+	}
+
+	ok, err := repo.ConsumeLoginChallenge(ctx, "c1", 2, now)
+	if err != nil || ok {
+		t.Fatalf("challenge over max must not be consumed: %v, %v", ok, err)
+	}
+
+	ok, err = repo.ConsumeLoginChallenge(ctx, "c1", 3, now)
+	if err != nil || !ok {
+		t.Fatalf("failed consume challenge: %v, %v", ok, err)
+	}
+
+	ok, err = repo.ConsumeLoginChallenge(ctx, "c1", 3, now)
+	if err != nil || ok {
+		t.Fatalf("challenge must be consumed once: %v, %v", ok, err)
+	}
+
+	ok, err = repo.CountLoginChallengeAttempt(ctx, "c1", 10)
+	if err != nil || ok {
+		t.Fatalf("consumed challenge must have no attempt left: %v, %v", ok, err)
+	}
+}
@@ -0,0 +1,161 @@
+package sqliterepositories
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"waizlytest/repositories"
+)
+
+var _ (repositories.PasskeyWriter) = (*PasskeyRepository)(nil)
+var _ (repositories.PasskeyReader) = (*PasskeyRepository)(nil)
+
+// PasskeyRepository implementation both of `repositories.PasskeyWriter` & `repositories.PasskeyReader`
+// on top of SQLite.
+type PasskeyRepository struct {
+	db *sql.DB
+}
+
+func NewPasskeyRepository(db *sql.DB) *PasskeyRepository {
+	return &PasskeyRepository{
+		db: db,
+	}
+}
+
+func (r *PasskeyRepository) CreatePasskey(ctx context.Context, p *repositories.Passkey) (int64, error) {
+	query := `
+		INSERT INTO passkeys (user_id, credential_id, public_key, attestation_type, aaguid, sign_count, transports, backup_eligible, backup_state, name, created_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	res, err := r.db.ExecContext(
+		ctx,
+		query,
+		p.UserID,
+		p.CredentialID,
+		p.PublicKey,
+		p.AttestationType,
+		p.AAGUID,
+		int64(p.SignCount),
+		strings.Join(p.Transports, ","),
+		p.BackupEligible,
+		p.BackupState,
+		p.Name,
+		p.CreatedAt,
+	)
+	if err != nil {
+		return 0, mapError(err)
+	}
+
+	return res.LastInsertId()
+}
+
+func (r *PasskeyRepository) FindPasskeysByUser(ctx context.Context, userID int64) ([]*repositories.Passkey, error) {
+	query := `
+		SELECT id, user_id, credential_id, public_key, attestation_type, aaguid, sign_count, transports, backup_eligible, backup_state, name, created_at, last_used_at
+		FROM passkeys
+		WHERE user_id = ?
+		ORDER BY id
+	`
+
+	rs, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rs.Close()
+
+	passkeys := []*repositories.Passkey{}
+	for rs.Next() {
+		var p repositories.Passkey
+		var signCount int64
+		var transports string
+
+		err = rs.Scan(
+			&p.ID,
+			&p.UserID,
+			&p.CredentialID,
+			&p.PublicKey,
+			&p.AttestationType,
+			&p.AAGUID,
+			&signCount,
+			&transports,
+			&p.BackupEligible,
+			&p.BackupState,
+			&p.Name,
+			&p.CreatedAt,
+			&p.LastUsedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		p.SignCount = uint32(signCount)
+		if transports != "" {
+			p.Transports = strings.Split(transports, ",")
+		}
+
+		passkeys = append(passkeys, &p)
+	}
+
+	return passkeys, rs.Err()
+}
+
+func (r *PasskeyRepository) UpdatePasskeyUsage(ctx context.Context, id int64, signCount uint32, backupState bool, at time.Time) error {
+	query := `
+		UPDATE passkeys
+		SET sign_count = ?, backup_state = ?, last_used_at = ?
+		WHERE id = ?
+	`
+
+	_, err := r.db.ExecContext(ctx, query, int64(signCount), backupState, at, id)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *PasskeyRepository) RenamePasskey(ctx context.Context, userID, id int64, name string) (bool, error) {
+	query := `
+		UPDATE passkeys
+		SET name = ?
+		WHERE id = ? AND user_id = ?
+	`
+
+	return r.affected(r.db.ExecContext(ctx, query, name, id, userID))
+}
+
+func (r *PasskeyRepository) DeletePasskey(ctx context.Context, userID, id int64) (bool, error) {
+	query := `
+		DELETE FROM passkeys
+		WHERE id = ? AND user_id = ?
+	`
+
+	return r.affected(r.db.ExecContext(ctx, query, id, userID))
+}
+
+func (r *PasskeyRepository) ConsumePasskeyChallenge(ctx context.Context, challenge string, expiresAt time.Time) (bool, error) {
+	query := `
+		INSERT INTO passkey_challenges (challenge, expires_at)
+		VALUES (?, ?)
+		ON CONFLICT (challenge) DO NOTHING
+	`
+
+	return r.affected(r.db.ExecContext(ctx, query, challenge, expiresAt))
+}
+
+// affected reports whether an UPDATE, DELETE or INSERT ... DO NOTHING matched the row
+func (r *PasskeyRepository) affected(res sql.Result, err error) (bool, error) {
+	if err != nil {
+		return false, err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return n == 1, nil
+}
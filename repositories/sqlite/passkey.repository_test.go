@@ -0,0 +1,102 @@
+package sqliterepositories_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"waizlytest/repositories"
+	sqliterepositories "waizlytest/repositories/sqlite"
+)
+
+func TestPasskeyRepository_Passkey(t *testing.T) {
+	ctx := context.TODO()
+	repo := sqliterepositories.NewPasskeyRepository(newDB(t))
+
+	now := time.Now().UTC().Truncate(time.Second)
+	pk := &repositories.Passkey{
+		UserID:          1,
+		CredentialID:    []byte{1, 2, 3},
+		PublicKey:       []byte{4, 5, 6},
+		AttestationType: "none",
+		AAGUID:          make([]byte, 16),
+		SignCount:       3,
+		Transports:      []string{"usb", "nfc"},
+		BackupEligible:  true,
+		Name:            "YubiKey",
+		CreatedAt:       now,
+	}
+
+	id, err := repo.CreatePasskey(ctx, pk)
+	if err != nil {
+		t.Fatalf("failed create passkey: %v", err)
+	}
+
+	pk.ID = id
+
+	_, err = repo.CreatePasskey(ctx, &repositories.Passkey{UserID: 2, CredentialID: []byte{1, 2, 3}, PublicKey: []byte{7}, Name: "Copy", CreatedAt: now})
+	if !errors.Is(err, repositories.ErrDuplicate) {
+		t.Fatalf("expected duplicate credential, got %v", err)
+	}
+
+	err = repo.UpdatePasskeyUsage(ctx, id, 4, true, now)
+	if err != nil {
+		t.Fatalf("failed update usage: %v", err)
+	}
+
+	pk.SignCount, pk.BackupState, pk.LastUsedAt = 4, true, &now
+
+	got, err := repo.FindPasskeysByUser(ctx, 1)
+	if err != nil {
+		t.Fatalf("failed find passkeys: %v", err)
+	}
+
+	if diff := cmp.Diff([]*repositories.Passkey{pk}, got); diff != "" {
+		t.Fatalf("passkeys mismatch (-want +got):\n%s", diff)
+	}
+
+	ok, err := repo.RenamePasskey(ctx, 2, id, "Stolen")
+	if err != nil || ok {
+		t.Fatalf("rename by other user must not match: %v, %v", ok, err)
+	}
+
+	ok, err = repo.RenamePasskey(ctx, 1, id, "Office key")
+	if err != nil || !ok {
+		t.Fatalf("failed rename: %v, %v", ok, err)
+	}
+
+	ok, err = repo.DeletePasskey(ctx, 1, id)
+	if err != nil || !ok {
+		t.Fatalf("failed delete: %v, %v", ok, err)
+	}
+
+	got, err = repo.FindPasskeysByUser(ctx, 1)
+	if err != nil || len(got) != 0 {
+		t.Fatalf("expected no passkeys, got %v, %v", got, err)
+	}
+}
+
+func TestPasskeyRepository_ConsumePasskeyChallenge(t *testing.T) {
+	ctx := context.TODO()
+	repo := sqliterepositories.NewPasskeyRepository(newDB(t))
+
+	expiresAt := time.Now().Add(time.Minute)
+
+	ok, err := repo.ConsumePasskeyChallenge(ctx, "challenge", expiresAt)
+	if err != nil || !ok {
+		t.Fatalf("failed consume challenge: %v, %v", ok, err)
+	}
+
+	ok, err = repo.ConsumePasskeyChallenge(ctx, "challenge", expiresAt)
+	if err != nil || ok {
+		t.Fatalf("consumed challenge must not be consumed again: %v, %v", ok, err)
+	}
+
+	ok, err = repo.ConsumePasskeyChallenge(ctx, "other", expiresAt)
+	if err != nil || !ok {
+		t.Fatalf("failed consume other challenge: %v, %v", ok, err)
+	}
+}
@@ -0,0 +1,148 @@
+package sqliterepositories
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"waizlytest/repositories"
+)
+
+var _ (repositories.SessionWriter) = (*SessionRepository)(nil)
+var _ (repositories.SessionReader) = (*SessionRepository)(nil)
+
+// SessionRepository implementation both of `repositories.SessionWriter` & `repositories.SessionReader`
+// on top of SQLite.
+type SessionRepository struct {
+	db *sql.DB
+}
+
+func NewSessionRepository(db *sql.DB) *SessionRepository {
+	return &SessionRepository{
+		db: db,
+	}
+}
+
+const sessionColumns = `id, user_id, method, device_name, user_agent, ip, created_at, last_seen_at, expires_at, revoked_at`
+
+// scanner is either *sql.Row or *sql.Rows
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanSession(rw scanner) (*repositories.Session, error) {
+	var s repositories.Session
+	err := rw.Scan(
+		&s.ID,
+		&s.UserID,
+		&s.Method,
+		&s.DeviceName,
+		&s.UserAgent,
+		&s.IP,
+		&s.CreatedAt,
+		&s.LastSeenAt,
+		&s.ExpiresAt,
+		&s.RevokedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return &s, nil
+}
+
+func (r *SessionRepository) CreateSession(ctx context.Context, s *repositories.Session) error {
+	query := `
+		INSERT INTO sessions (id, user_id, method, device_name, user_agent, ip, created_at, last_seen_at, expires_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+
+	_, err := r.db.ExecContext(ctx, query, s.ID, s.UserID, s.Method, s.DeviceName, s.UserAgent, s.IP, s.CreatedAt, s.LastSeenAt, s.ExpiresAt)
+	if err != nil {
+		return mapError(err)
+	}
+
+	return nil
+}
+
+func (r *SessionRepository) FindSession(ctx context.Context, id string) (*repositories.Session, error) {
+	query := `
+		SELECT ` + sessionColumns + `
+		FROM sessions
+		WHERE id = ?
+		LIMIT 1
+	`
+
+	s, err := scanSession(r.db.QueryRowContext(ctx, query, id))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return s, nil
+}
+
+func (r *SessionRepository) FindActiveSessionsByUser(ctx context.Context, userID int64, now time.Time) ([]*repositories.Session, error) {
+	query := `
+		SELECT ` + sessionColumns + `
+		FROM sessions
+		WHERE user_id = ? AND revoked_at IS NULL AND expires_at > ?
+		ORDER BY last_seen_at DESC
+	`
+
+	rs, err := r.db.QueryContext(ctx, query, userID, now)
+	if err != nil {
+		return nil, err
+	}
+	defer rs.Close()
+
+	sessions := []*repositories.Session{}
+	for rs.Next() {
+		s, err := scanSession(rs)
+		if err != nil {
+			return nil, err
+		}
+
+		sessions = append(sessions, s)
+	}
+
+	return sessions, rs.Err()
+}
+
+func (r *SessionRepository) TouchSession(ctx context.Context, id string, at time.Time) error {
+	query := `
+		UPDATE sessions
+		SET last_seen_at = ?
+		WHERE id = ?
+	`
+
+	_, err := r.db.ExecContext(ctx, query, at, id)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *SessionRepository) RevokeSession(ctx context.Context, userID int64, id string, at time.Time) (bool, error) {
+	query := `
+		UPDATE sessions
+		SET revoked_at = ?
+		WHERE id = ? AND user_id = ? AND revoked_at IS NULL
+	`
+
+	res, err := r.db.ExecContext(ctx, query, at, id, userID)
+	if err != nil {
+		return false, err
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return n == 1, nil
+}
@@ -0,0 +1,87 @@
+package sqliterepositories_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+
+	"waizlytest/repositories"
+	sqliterepositories "waizlytest/repositories/sqlite"
+)
+
+func TestSessionRepository_Session(t *testing.T) {
+	ctx := context.TODO()
+	repo := sqliterepositories.NewSessionRepository(newDB(t))
+
+	now := time.Now().UTC().Truncate(time.Second)
+	ss := &repositories.Session{
+		ID:         "a1",
+		UserID:     1,
+		Method:     "password",
+		DeviceName: "Laptop",
+		UserAgent:  "Mozilla/5.0",
+		IP:         "127.0.0.1",
+		CreatedAt:  now,
+		LastSeenAt: now,
+		ExpiresAt:  now.Add(time.Hour),
+	}
+
+	err := repo.CreateSession(ctx, ss)
+	if err != nil {
+		t.Fatalf("failed create session: %v", err)
+	}
+
+	// expired one is not listed
+	err = repo.CreateSession(ctx, &repositories.Session{ID: "a2", UserID: 1, Method: "otp", CreatedAt: now, LastSeenAt: now, ExpiresAt: now.Add(-time.Second)})
+	if err != nil {
+		t.Fatalf("failed create session: %v", err)
+	}
+
+	seen := now.Add(time.Minute)
+	err = repo.TouchSession(ctx, "a1", seen)
+	if err != nil {
+		t.Fatalf("failed touch session: %v", err)
+	}
+
+	ss.LastSeenAt = seen
+
+	got, err := repo.FindActiveSessionsByUser(ctx, 1, now)
+	if err != nil {
+		t.Fatalf("failed find sessions: %v", err)
+	}
+
+	if diff := cmp.Diff([]*repositories.Session{ss}, got); diff != "" {
+		t.Fatalf("sessions mismatch (-want +got):\n%s", diff)
+	}
+
+	ok, err := repo.RevokeSession(ctx, 2, "a1", now)
+	if err != nil || ok {
+		t.Fatalf("session of another user must not be revoked: %v, %v", ok, err)
+	}
+
+	ok, err = repo.RevokeSession(ctx, 1, "a1", now)
+	if err != nil || !ok {
+		t.Fatalf("failed revoke session: %v, %v", ok, err)
+	}
+
+	found, err := repo.FindSession(ctx, "a1")
+	if err != nil {
+		t.Fatalf("failed find session: %v", err)
+	}
+
+	if found.RevokedAt == nil {
+		t.Fatalf("session is not revoked: %+v", found)
+	}
+
+	got, err = repo.FindActiveSessionsByUser(ctx, 1, now)
+	if err != nil || len(got) != 0 {
+		t.Fatalf("revoked session must not be listed: %v, %v", got, err)
+	}
+
+	found, err = repo.FindSession(ctx, "missing")
+	if err != nil || found != nil {
+		t.Fatalf("expected no session, got %v, %v", found, err)
+	}
+}
@@ -0,0 +1,166 @@
+package sqliterepositories
+
+import (
+	"context"
+	"database/sql"
+
+	"waizlytest/repositories"
+)
+
+var _ (repositories.UserWriter) = (*UserRepository)(nil)
+var _ (repositories.UserReader) = (*UserRepository)(nil)
+
+// UserRepository implementation both of `repositories.UserWriter` & `repositories.UserReader`
+// on top of SQLite, for deployments that do not run Postgres.
+type UserRepository struct {
+	db *sql.DB
+}
+
+func NewUserRepository(db *sql.DB) *UserRepository {
+	return &UserRepository{
+		db: db,
+	}
+}
+
+// userColumns are selected by every finder, see scanUser.
+// Phone & email are optional, kept as empty string on the model.
+const userColumns = `id, fullname, password, COALESCE(phone, ''), COALESCE(email, ''), email_verified_at, created_at, updated_at, deleted_at`
+
+func scanUser(row *sql.Row) (*repositories.User, error) {
+	var user repositories.User
+	err := row.Scan(
+		&user.ID,
+		&user.FullName,
+		&user.Password,
+		&user.Phone,
+		&user.Email,
+		&user.EmailVerifiedAt,
+		&user.CreatedAt,
+		&user.UpdatedAt,
+		&user.DeletedAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+func (r *UserRepository) CreateUser(ctx context.Context, u *repositories.User) (int64, error) {
+	query := `
+		INSERT INTO users (fullname, password, phone, email, email_verified_at, created_at, updated_at)
+		VALUES (?, ?, NULLIF(?, ''), NULLIF(?, ''), ?, ?, ?)
+	`
+
+	res, err := r.db.ExecContext(
+		ctx,
+		query,
+		u.FullName,
+		u.Password,
+		u.Phone,
+		u.Email,
+		u.EmailVerifiedAt,
+		u.CreatedAt,
+		u.UpdatedAt,
+	)
+	if err != nil {
+		return 0, mapError(err)
+	}
+
+	return res.LastInsertId()
+}
+
+func (r *UserRepository) FindUserByPhone(ctx context.Context, phone string) (*repositories.User, error) {
+	query := `
+		SELECT ` + userColumns + `
+		FROM users
+		WHERE phone = ? AND deleted_at IS NULL
+		LIMIT 1
+	`
+
+	return scanUser(r.db.QueryRowContext(ctx, query, phone))
+}
+
+// FindUserByEmail only finds active user whose email is verified.
+func (r *UserRepository) FindUserByEmail(ctx context.Context, email string) (*repositories.User, error) {
+	query := `
+		SELECT ` + userColumns + `
+		FROM users
+		WHERE email = ? AND email_verified_at IS NOT NULL AND deleted_at IS NULL
+		LIMIT 1
+	`
+
+	return scanUser(r.db.QueryRowContext(ctx, query, email))
+}
+
+func (r *UserRepository) FindUserByID(ctx context.Context, id int64) (*repositories.User, error) {
+	query := `
+		SELECT ` + userColumns + `
+		FROM users
+		WHERE id = ?
+		LIMIT 1
+	`
+
+	return scanUser(r.db.QueryRowContext(ctx, query, id))
+}
+
+func (r *UserRepository) CreateUserAttendance(ctx context.Context, ua *repositories.UserAttendance) error {
+	query := `
+		INSERT INTO user_attendance_logs (user_id, login_at)
+		VALUES (?, ?)
+	`
+
+	_, err := r.db.ExecContext(ctx, query, ua.UserID, ua.LoginAt)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r *UserRepository) UpdateUser(ctx context.Context, u *repositories.User) error {
+	query := `
+		UPDATE users
+		SET fullname = ?, phone = NULLIF(?, ''), email = NULLIF(?, ''), email_verified_at = ?, updated_at = ?, deleted_at = ?
+		WHERE id = ?
+	`
+
+	_, err := r.db.ExecContext(
+		ctx,
+		query,
+		u.FullName,
+		u.Phone,
+		u.Email,
+		u.EmailVerifiedAt,
+		u.UpdatedAt,
+		u.DeletedAt,
+		u.ID,
+	)
+	if err != nil {
+		return mapError(err)
+	}
+
+	return nil
+}
+
+// SaveUserAttendanceSummary uses SQLite's UPSERT clause (3.24+), the equivalent of
+// Postgres `ON CONFLICT ... DO UPDATE`.
+func (r *UserRepository) SaveUserAttendanceSummary(ctx context.Context, userID int64) error {
+	query := `
+		INSERT INTO user_attendance_summaries (user_id, total_login)
+		VALUES (?, 1)
+		ON CONFLICT (user_id)
+		DO UPDATE SET total_login = user_attendance_summaries.total_login + excluded.total_login
+	`
+
+	_, err := r.db.ExecContext(ctx, query, userID)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,159 @@
+package sqliterepositories_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	_ "modernc.org/sqlite"
+
+	"waizlytest/migration"
+	"waizlytest/repositories"
+	sqliterepositories "waizlytest/repositories/sqlite"
+)
+
+func newDB(t *testing.T) *sql.DB {
+	db, err := sql.Open("sqlite", ":memory:")
+	if err != nil {
+		t.Fatalf("failed open DB: %v", err)
+	}
+
+	// every connection of `:memory:` has its own database
+	db.SetMaxOpenConns(1)
+	t.Cleanup(func() { db.Close() })
+
+	ddl, err := os.ReadFile("../../migration/sqlite/" + migration.Baseline)
+	if err != nil {
+		t.Fatalf("failed read baseline: %v", err)
+	}
+
+	_, err = db.Exec(string(ddl))
+	if err != nil {
+		t.Fatalf("failed apply baseline: %v", err)
+	}
+
+	_, err = migration.Up(context.TODO(), db, migration.DialectSQLite)
+	if err != nil {
+		t.Fatalf("failed migrate: %v", err)
+	}
+
+	return db
+}
+
+func TestUserRepository_User(t *testing.T) {
+	ctx := context.TODO()
+	db := newDB(t)
+	repo := sqliterepositories.NewUserRepository(db)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	usr := &repositories.User{
+		FullName:  "Teest",
+		Password:  "hashed",
+		Phone:     "123456789",
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	id, err := repo.CreateUser(ctx, usr)
+	if err != nil {
+		t.Fatalf("failed create user: %v", err)
+	}
+
+	usr.ID = id
+
+	got, err := repo.FindUserByPhone(ctx, "123456789")
+	if err != nil {
+		t.Fatalf("failed find user: %v", err)
+	}
+
+	diff := cmp.Diff(usr, got)
+	if diff != "" {
+		t.Errorf("user mismatch (-want +got):\n%s", diff)
+	}
+
+	usr.Phone = "909090"
+	usr.DeletedAt = &now
+	err = repo.UpdateUser(ctx, usr)
+	if err != nil {
+		t.Fatalf("failed update user: %v", err)
+	}
+
+	got, err = repo.FindUserByID(ctx, id)
+	if err != nil {
+		t.Fatalf("failed find user: %v", err)
+	}
+
+	diff = cmp.Diff(usr, got)
+	if diff != "" {
+		t.Errorf("user mismatch (-want +got):\n%s", diff)
+	}
+
+	got, err = repo.FindUserByPhone(ctx, "123456789")
+	if err != nil || got != nil {
+		t.Errorf("expected no user, got %v, %v", got, err)
+	}
+}
+
+func TestUserRepository_SaveUserAttendanceSummary(t *testing.T) {
+	ctx := context.TODO()
+	db := newDB(t)
+	repo := sqliterepositories.NewUserRepository(db)
+
+	for i := 0; i < 3; i++ {
+		err := repo.CreateUserAttendance(ctx, &repositories.UserAttendance{UserID: 1, LoginAt: time.Now()})
+		if err != nil {
+			t.Fatalf("failed create attendance: %v", err)
+		}
+
+		err = repo.SaveUserAttendanceSummary(ctx, 1)
+		if err != nil {
+			t.Fatalf("failed save summary: %v", err)
+		}
+	}
+
+	var total int
+	err := db.QueryRow(`SELECT total_login FROM user_attendance_summaries WHERE user_id = 1`).Scan(&total)
+	if err != nil {
+		t.Fatalf("failed read summary: %v", err)
+	}
+
+	if total != 3 {
+		t.Errorf("total login mismatch: want 3, got %d", total)
+	}
+}
+
+func TestUserRepository_DuplicatePhone(t *testing.T) {
+	ctx := context.TODO()
+	db := newDB(t)
+	repo := sqliterepositories.NewUserRepository(db)
+
+	now := time.Now().UTC().Truncate(time.Second)
+	usr := &repositories.User{FullName: "Teest", Password: "hashed", Phone: "+6281234567890", CreatedAt: now, UpdatedAt: now}
+
+	id, err := repo.CreateUser(ctx, usr)
+	if err != nil {
+		t.Fatalf("failed create user: %v", err)
+	}
+
+	_, err = repo.CreateUser(ctx, usr)
+	if !errors.Is(err, repositories.ErrDuplicate) {
+		t.Fatalf("expected duplicate, got %v", err)
+	}
+
+	// soft-deleted user frees the phone
+	usr.ID = id
+	usr.DeletedAt = &now
+	err = repo.UpdateUser(ctx, usr)
+	if err != nil {
+		t.Fatalf("failed delete user: %v", err)
+	}
+
+	_, err = repo.CreateUser(ctx, usr)
+	if err != nil {
+		t.Errorf("expected phone of deleted user is reusable, got %v", err)
+	}
+}
@@ -3,13 +3,16 @@ package repositories
 import "time"
 
 type User struct {
-	ID        int64
-	FullName  string
-	Password  string
-	Phone     string
-	CreatedAt time.Time
-	UpdatedAt time.Time
-	DeletedAt *time.Time
+	ID       int64
+	FullName string
+	Password string
+	Phone    string
+	// Email is optional, only verified email identifies the user
+	Email           string
+	EmailVerifiedAt *time.Time
+	CreatedAt       time.Time
+	UpdatedAt       time.Time
+	DeletedAt       *time.Time
 }
 type UserAttendance struct {
 	UserID  int64
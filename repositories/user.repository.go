@@ -12,5 +12,7 @@ type UserWriter interface {
 
 type UserReader interface {
 	FindUserByPhone(ctx context.Context, phone string) (*User, error)
+	// FindUserByEmail only finds user whose email is verified
+	FindUserByEmail(ctx context.Context, email string) (*User, error)
 	FindUserByID(ctx context.Context, id int64) (*User, error)
 }
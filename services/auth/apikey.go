@@ -0,0 +1,54 @@
+package authservice
+
+import (
+	"context"
+	"time"
+)
+
+// Scopes an API key may be granted, each guards a group of routes.
+// Requests by login token are not limited by scope.
+const (
+	ScopeProfileRead  = "profile:read"
+	ScopeProfileWrite = "profile:write"
+)
+
+// Scopes lists every known scope
+var Scopes = []string{ScopeProfileRead, ScopeProfileWrite}
+
+type APIKeyCreateRequest struct {
+	Name   string   `json:"name" validate:"required,max=64"`
+	Scopes []string `json:"scopes" validate:"required"`
+	// ExpiresAt is optional, key without it never expires
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+type APIKey struct {
+	ID         int64      `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+}
+
+// APIKeyCreated carries the key itself, it is shown only once
+type APIKeyCreated struct {
+	APIKey
+	Key string `json:"key"`
+}
+
+// APIKeys manages API keys of the logged in user
+type APIKeys interface {
+	CreateAPIKey(ctx context.Context, userID int64, params APIKeyCreateRequest) (APIKeyCreated, error)
+	ListAPIKeys(ctx context.Context, userID int64) ([]APIKey, error)
+	// DeleteAPIKey revokes the key right away
+	DeleteAPIKey(ctx context.Context, userID, id int64) error
+}
+
+// APIKeyAuthz is counterpart of Authz for API keys
+type APIKeyAuthz interface {
+	// ValidateAPIKey validates the key sent by `Authorization: ApiKey <key>`,
+	// unknown, deleted & expired keys are not valid.
+	ValidateAPIKey(ctx context.Context, key string) (Principal, error)
+}
@@ -2,16 +2,25 @@ package authservice
 
 import (
 	"context"
+	"time"
 )
 
+// LoginRequest identifies the user by either phone or verified email
 type LoginRequest struct {
-	Password string `json:"password"`
-	Phone    string `json:"phone"`
+	Password string `json:"password" validate:"required,max=72"`
+	Phone    string `json:"phone" validate:"omitempty,max=20"`
+	Email    string `json:"email" validate:"omitempty,max=255"`
+	// DeviceName is optional name of the device, shown on the session
+	DeviceName string `json:"device_name" validate:"omitempty,max=64"`
+	// UserAgent & IP of the client, recorded on the session
+	UserAgent string `json:"-"`
+	IP        string `json:"-"`
 }
 
 type LoginResponse struct {
-	ID    int64  `json:"-"`
-	Token string `json:"token"`
+	ID        int64     `json:"-"`
+	Token     string    `json:"token"`
+	ExpiresAt time.Time `json:"expires_at"`
 }
 
 type Authn interface {
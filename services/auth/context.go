@@ -0,0 +1,18 @@
+package authservice
+
+import (
+	"context"
+
+	"waizlytest/common/contextkey"
+)
+
+// WithPrincipal returns ctx carrying p, it is set by the auth middleware
+func WithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, contextkey.PrincipalContextKey, p)
+}
+
+// PrincipalFrom returns principal of ctx, it reports false when the request is not authenticated
+func PrincipalFrom(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(contextkey.PrincipalContextKey).(Principal)
+	return p, ok
+}
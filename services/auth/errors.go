@@ -0,0 +1,209 @@
+package authservice
+
+import (
+	commonerr "waizlytest/common/errors"
+)
+
+// Codes of auth domain, 1xx are bad requests, 80x authentication, 82x not found & forbidden
+const (
+	CodeWrongEmailCredential  commonerr.Code = 110
+	CodeOTPInvalid            commonerr.Code = 111
+	CodePasskeyInvalid        commonerr.Code = 112
+	CodeAPIKeyScopeInvalid    commonerr.Code = 113
+	CodeAPIKeyExpiryInvalid   commonerr.Code = 114
+	CodeSessionRevoked        commonerr.Code = 806
+	CodeAPIKeyInvalid         commonerr.Code = 807
+	CodeClientInvalid         commonerr.Code = 808
+	CodeTokenInvalid          commonerr.Code = 809
+	CodeTokenSignatureInvalid commonerr.Code = 810
+	CodeTokenExpired          commonerr.Code = 811
+	CodeTokenNotYetValid      commonerr.Code = 812
+	CodeTokenIssuerInvalid    commonerr.Code = 813
+	CodeTokenAudienceInvalid  commonerr.Code = 814
+	CodeTokenTooOld           commonerr.Code = 815
+	CodeReauthRequired        commonerr.Code = 816
+	CodePasskeyNotFound       commonerr.Code = 823
+	CodeSessionNotFound       commonerr.Code = 824
+	CodeAPIKeyNotFound        commonerr.Code = 826
+	CodeScopeMissing          commonerr.Code = 827
+	CodeCSRFInvalid           commonerr.Code = 828
+)
+
+// Error definitions of auth domain,
+// wrong credential predates the catalog so it keeps the shared bad request code.
+var (
+	ErrWrongCredential = commonerr.DefBadRequest.Variant(commonerr.Definition{
+		Domain:      "auth",
+		Key:         "auth.wrong_credential",
+		Message:     "phone or password is wrong",
+		Description: "Login failed, either phone is not registered or password does not match.",
+	})
+	ErrWrongEmailCredential = commonerr.Define(commonerr.Definition{
+		Domain:      "auth",
+		Type:        commonerr.TypeBadRequestError,
+		Code:        CodeWrongEmailCredential,
+		Key:         "auth.wrong_email_credential",
+		Message:     "email or password is wrong",
+		Description: "Login failed, either email is not registered, not verified yet or password does not match.",
+	})
+	ErrOTPInvalid = commonerr.Define(commonerr.Definition{
+		Domain:      "auth",
+		Type:        commonerr.TypeBadRequestError,
+		Code:        CodeOTPInvalid,
+		Key:         "auth.otp_invalid",
+		Message:     "code is wrong or expired",
+		Description: "One-time code or its link is wrong, expired, used already or tried too many times, start again.",
+	})
+	ErrPasskeyInvalid = commonerr.Define(commonerr.Definition{
+		Domain:      "auth",
+		Type:        commonerr.TypeBadRequestError,
+		Code:        CodePasskeyInvalid,
+		Key:         "auth.passkey_invalid",
+		Message:     "passkey is not valid",
+		Description: "Passkey ceremony failed, either it expired, the response does not match the challenge or the passkey is not registered.",
+	})
+	ErrPasskeyNotFound = commonerr.Define(commonerr.Definition{
+		Domain:      "auth",
+		Type:        commonerr.TypeNotFoundError,
+		Code:        CodePasskeyNotFound,
+		Key:         "auth.passkey_not_found",
+		Message:     "passkey is not found",
+		Description: "Current user has no passkey of the given ID.",
+	})
+	ErrSessionRevoked = commonerr.Define(commonerr.Definition{
+		Domain:      "auth",
+		Type:        commonerr.TypeAuthenticationError,
+		Code:        CodeSessionRevoked,
+		Key:         "auth.session_revoked",
+		Message:     "session is revoked, please login again",
+		Description: "Token belongs to a session which is logged out or no longer exists.",
+	})
+	ErrSessionNotFound = commonerr.Define(commonerr.Definition{
+		Domain:      "auth",
+		Type:        commonerr.TypeNotFoundError,
+		Code:        CodeSessionNotFound,
+		Key:         "auth.session_not_found",
+		Message:     "session is not found",
+		Description: "Current user has no active session of the given ID.",
+	})
+	ErrAPIKeyScopeInvalid = commonerr.Define(commonerr.Definition{
+		Domain:      "auth",
+		Type:        commonerr.TypeBadRequestError,
+		Code:        CodeAPIKeyScopeInvalid,
+		Key:         "auth.api_key_scope_invalid",
+		Message:     "scopes are not valid",
+		Description: "API key needs at least one scope, every scope must be a known one.",
+	})
+	ErrAPIKeyExpiryInvalid = commonerr.Define(commonerr.Definition{
+		Domain:      "auth",
+		Type:        commonerr.TypeBadRequestError,
+		Code:        CodeAPIKeyExpiryInvalid,
+		Key:         "auth.api_key_expiry_invalid",
+		Message:     "expiry must be in the future",
+		Description: "Expiry of the API key is in the past.",
+	})
+	ErrAPIKeyInvalid = commonerr.Define(commonerr.Definition{
+		Domain:      "auth",
+		Type:        commonerr.TypeAuthenticationError,
+		Code:        CodeAPIKeyInvalid,
+		Key:         "auth.api_key_invalid",
+		Message:     "API key is not valid",
+		Description: "API key is malformed, deleted or expired.",
+	})
+	ErrAPIKeyNotFound = commonerr.Define(commonerr.Definition{
+		Domain:      "auth",
+		Type:        commonerr.TypeNotFoundError,
+		Code:        CodeAPIKeyNotFound,
+		Key:         "auth.api_key_not_found",
+		Message:     "API key is not found",
+		Description: "Current user has no API key of the given ID.",
+	})
+	ErrScopeMissing = commonerr.Define(commonerr.Definition{
+		Domain:      "auth",
+		Type:        commonerr.TypeForbiddenError,
+		Code:        CodeScopeMissing,
+		Key:         "auth.scope_missing",
+		Message:     "API key is not allowed to do this",
+		Description: "API key lacks the scope of the route, or the route needs a login token.",
+	})
+	ErrClientInvalid = commonerr.Define(commonerr.Definition{
+		Domain:      "auth",
+		Type:        commonerr.TypeAuthenticationError,
+		Code:        CodeClientInvalid,
+		Key:         "auth.client_invalid",
+		Message:     "client is not valid",
+		Description: "Client credential of the introspection request is missing or wrong.",
+	})
+	ErrTokenInvalid = commonerr.Define(commonerr.Definition{
+		Domain:      "auth",
+		Type:        commonerr.TypeAuthenticationError,
+		Code:        CodeTokenInvalid,
+		Key:         "auth.token_invalid",
+		Message:     "token is not valid",
+		Description: "Access token is malformed or lacks required claims.",
+	})
+	ErrTokenSignatureInvalid = commonerr.Define(commonerr.Definition{
+		Domain:      "auth",
+		Type:        commonerr.TypeAuthenticationError,
+		Code:        CodeTokenSignatureInvalid,
+		Key:         "auth.token_signature_invalid",
+		Message:     "token signature is not valid",
+		Description: "Access token is not signed by this app, or signed by an unexpected algorithm.",
+	})
+	ErrTokenExpired = commonerr.Define(commonerr.Definition{
+		Domain:      "auth",
+		Type:        commonerr.TypeAuthenticationError,
+		Code:        CodeTokenExpired,
+		Key:         "auth.token_expired",
+		Message:     "token is expired, please login again",
+		Description: "Access token is past its `exp`, beyond the allowed clock skew.",
+	})
+	ErrTokenNotYetValid = commonerr.Define(commonerr.Definition{
+		Domain:      "auth",
+		Type:        commonerr.TypeAuthenticationError,
+		Code:        CodeTokenNotYetValid,
+		Key:         "auth.token_not_yet_valid",
+		Message:     "token is not valid yet",
+		Description: "Access token `nbf` or `iat` is in the future, beyond the allowed clock skew.",
+	})
+	ErrTokenIssuerInvalid = commonerr.Define(commonerr.Definition{
+		Domain:      "auth",
+		Type:        commonerr.TypeAuthenticationError,
+		Code:        CodeTokenIssuerInvalid,
+		Key:         "auth.token_issuer_invalid",
+		Message:     "token issuer is not valid",
+		Description: "Access token `iss` is not the configured issuer.",
+	})
+	ErrTokenAudienceInvalid = commonerr.Define(commonerr.Definition{
+		Domain:      "auth",
+		Type:        commonerr.TypeAuthenticationError,
+		Code:        CodeTokenAudienceInvalid,
+		Key:         "auth.token_audience_invalid",
+		Message:     "token audience is not valid",
+		Description: "Access token `aud` does not include the configured audience.",
+	})
+	ErrTokenTooOld = commonerr.Define(commonerr.Definition{
+		Domain:      "auth",
+		Type:        commonerr.TypeAuthenticationError,
+		Code:        CodeTokenTooOld,
+		Key:         "auth.token_too_old",
+		Message:     "token is too old, please login again",
+		Description: "Access token is issued longer ago than the configured maximum age.",
+	})
+	ErrReauthRequired = commonerr.Define(commonerr.Definition{
+		Domain:      "auth",
+		Type:        commonerr.TypeAuthenticationError,
+		Code:        CodeReauthRequired,
+		Key:         "auth.reauth_required",
+		Message:     "please login again to continue",
+		Description: "Sensitive route needs a recent login, or one by a stronger method, the client should prompt for credentials and retry with the new token.",
+	})
+	ErrCSRFInvalid = commonerr.Define(commonerr.Definition{
+		Domain:      "auth",
+		Type:        commonerr.TypeForbiddenError,
+		Code:        CodeCSRFInvalid,
+		Key:         "auth.csrf_invalid",
+		Message:     "CSRF token is missing or wrong",
+		Description: "Request authenticated by cookie changes state without X-CSRF-Token header matching the CSRF cookie.",
+	})
+)
@@ -0,0 +1,110 @@
+package v1authhttphandler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	commonerr "waizlytest/common/errors"
+
+	commonhttpdec "waizlytest/common/http/decoder"
+	commonhttpenc "waizlytest/common/http/encoder"
+	commonhttpresp "waizlytest/common/http/response"
+
+	authservice "waizlytest/services/auth"
+)
+
+// APIKeyHandler manages API keys of the logged in user, it must be behind the auth middleware
+type APIKeyHandler struct {
+	keys authservice.APIKeys
+
+	enc *commonhttpenc.ErrorEncoder
+}
+
+func NewAPIKeyHandler(keys authservice.APIKeys, enc *commonhttpenc.ErrorEncoder) *APIKeyHandler {
+	return &APIKeyHandler{
+		keys: keys,
+		enc:  enc,
+	}
+}
+
+func (hn *APIKeyHandler) Create() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		// principal is set by the auth middleware
+		principal, ok := authservice.PrincipalFrom(ctx)
+		if !ok {
+			e := commonerr.ErrorForbidden
+			hn.enc.Encode(ctx, w, e)
+			return
+		}
+
+		p, err := commonhttpdec.DecodeJSON[authservice.APIKeyCreateRequest](r)
+		if err != nil {
+			hn.enc.Encode(ctx, w, err)
+			return
+		}
+
+		resp, err := hn.keys.CreateAPIKey(ctx, principal.UserID, p)
+		if err != nil {
+			hn.enc.Encode(ctx, w, err)
+			return
+		}
+
+		commonhttpenc.JSONResponseEncoder(ctx, w, http.StatusCreated, commonhttpresp.NewResponse(resp, nil))
+	}
+}
+
+func (hn *APIKeyHandler) List() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		// principal is set by the auth middleware
+		principal, ok := authservice.PrincipalFrom(ctx)
+		if !ok {
+			e := commonerr.ErrorForbidden
+			hn.enc.Encode(ctx, w, e)
+			return
+		}
+
+		resp, err := hn.keys.ListAPIKeys(ctx, principal.UserID)
+		if err != nil {
+			hn.enc.Encode(ctx, w, err)
+			return
+		}
+
+		commonhttpenc.JSONResponseEncoder(ctx, w, http.StatusOK, commonhttpresp.NewResponse(resp, nil))
+	}
+}
+
+// Delete expects `{id}` URL param
+func (hn *APIKeyHandler) Delete() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		// principal is set by the auth middleware
+		principal, ok := authservice.PrincipalFrom(ctx)
+		if !ok {
+			e := commonerr.ErrorForbidden
+			hn.enc.Encode(ctx, w, e)
+			return
+		}
+
+		id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+		if err != nil {
+			e := authservice.ErrAPIKeyNotFound.New()
+			hn.enc.Encode(ctx, w, e)
+			return
+		}
+
+		err = hn.keys.DeleteAPIKey(ctx, principal.UserID, id)
+		if err != nil {
+			hn.enc.Encode(ctx, w, err)
+			return
+		}
+
+		commonhttpenc.JSONResponseEncoder(ctx, w, http.StatusOK, commonhttpresp.NewResponse(nil, nil))
+	}
+}
@@ -1,12 +1,10 @@
 package v1authhttphandler
 
 import (
-	"encoding/json"
-	"io"
 	"net/http"
 
-	commonerr "waizlytest/common/errors"
-
+	commonhttpcookie "waizlytest/common/http/cookie"
+	commonhttpdec "waizlytest/common/http/decoder"
 	commonhttpenc "waizlytest/common/http/encoder"
 	commonhttpresp "waizlytest/common/http/response"
 
@@ -15,11 +13,17 @@ import (
 
 type AuthnHandler struct {
 	authn authservice.Authn
+	// cookies is nil when cookie mode is disabled
+	cookies *commonhttpcookie.Jar
+
+	enc *commonhttpenc.ErrorEncoder
 }
 
-func NewAuthnHandler(authn authservice.Authn) *AuthnHandler {
+func NewAuthnHandler(authn authservice.Authn, cookies *commonhttpcookie.Jar, enc *commonhttpenc.ErrorEncoder) *AuthnHandler {
 	return &AuthnHandler{
-		authn: authn,
+		authn:   authn,
+		cookies: cookies,
+		enc:     enc,
 	}
 }
 
@@ -27,25 +31,36 @@ func (hn *AuthnHandler) Login() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
 
-		var p *authservice.LoginRequest
-		err := json.NewDecoder(r.Body).Decode(&p)
+		p, err := commonhttpdec.DecodeJSON[authservice.LoginRequest](r)
 		if err != nil {
-			if err == io.EOF {
-				e := commonerr.New(commonerr.TypeBadRequestError, commonerr.Code101, "request can not be empty")
-				commonhttpenc.JSONErrorEncoder(ctx, w, e)
-				return
-			}
+			hn.enc.Encode(ctx, w, err)
+			return
+		}
+
+		p.UserAgent = r.UserAgent()
+		p.IP = clientIP(r)
 
-			commonhttpenc.JSONErrorEncoder(ctx, w, err)
+		lr, err := hn.authn.Login(ctx, p)
+		if err != nil {
+			hn.enc.Encode(ctx, w, err)
 			return
 		}
 
-		lr, err := hn.authn.Login(ctx, *p)
+		err = setSessionCookie(hn.cookies, w, lr)
 		if err != nil {
-			commonhttpenc.JSONErrorEncoder(ctx, w, err)
+			hn.enc.Encode(ctx, w, err)
 			return
 		}
 
 		commonhttpenc.JSONResponseEncoder(ctx, w, http.StatusOK, commonhttpresp.NewResponse(lr, nil))
 	}
 }
+
+// setSessionCookie lets browser clients keep the token in HttpOnly cookie, when cookie mode is enabled
+func setSessionCookie(cookies *commonhttpcookie.Jar, w http.ResponseWriter, lr authservice.LoginResponse) error {
+	if cookies == nil {
+		return nil
+	}
+
+	return cookies.SetSession(w, lr.Token, lr.ExpiresAt)
+}
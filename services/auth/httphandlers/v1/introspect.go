@@ -0,0 +1,56 @@
+package v1authhttphandler
+
+import (
+	"net/http"
+
+	commonhttpenc "waizlytest/common/http/encoder"
+	commonvalidation "waizlytest/common/validation"
+
+	authservice "waizlytest/services/auth"
+)
+
+// IntrospectHandler serves token introspection of RFC 7662 to resource servers
+type IntrospectHandler struct {
+	introspector authservice.Introspector
+
+	enc *commonhttpenc.ErrorEncoder
+}
+
+func NewIntrospectHandler(introspector authservice.Introspector, enc *commonhttpenc.ErrorEncoder) *IntrospectHandler {
+	return &IntrospectHandler{
+		introspector: introspector,
+		enc:          enc,
+	}
+}
+
+// Introspect expects the client credential by HTTP Basic auth & form encoded `token`
+func (hn *IntrospectHandler) Introspect() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		clientID, secret, _ := r.BasicAuth()
+		err := hn.introspector.AuthenticateClient(ctx, clientID, secret)
+		if err != nil {
+			w.Header().Set("WWW-Authenticate", `Basic realm="introspect"`)
+			hn.enc.Encode(ctx, w, err)
+			return
+		}
+
+		p := authservice.IntrospectRequest{Token: r.PostFormValue("token")}
+		err = commonvalidation.Validate(p)
+		if err != nil {
+			hn.enc.Encode(ctx, w, err)
+			return
+		}
+
+		resp, err := hn.introspector.Introspect(ctx, p.Token)
+		if err != nil {
+			hn.enc.Encode(ctx, w, err)
+			return
+		}
+
+		// response carries user info, HTTP caches in between must not keep it
+		w.Header().Set("Cache-Control", "no-store")
+		commonhttpenc.JSONEncoder(ctx, w, http.StatusOK, resp)
+	}
+}
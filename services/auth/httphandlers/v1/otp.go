@@ -0,0 +1,90 @@
+package v1authhttphandler
+
+import (
+	"net"
+	"net/http"
+
+	commonhttpcookie "waizlytest/common/http/cookie"
+	commonhttpdec "waizlytest/common/http/decoder"
+	commonhttpenc "waizlytest/common/http/encoder"
+	commonhttpresp "waizlytest/common/http/response"
+
+	authservice "waizlytest/services/auth"
+)
+
+type OTPHandler struct {
+	passwordless authservice.Passwordless
+	// cookies is nil when cookie mode is disabled
+	cookies *commonhttpcookie.Jar
+
+	enc *commonhttpenc.ErrorEncoder
+}
+
+func NewOTPHandler(passwordless authservice.Passwordless, cookies *commonhttpcookie.Jar, enc *commonhttpenc.ErrorEncoder) *OTPHandler {
+	return &OTPHandler{
+		passwordless: passwordless,
+		cookies:      cookies,
+		enc:          enc,
+	}
+}
+
+func (hn *OTPHandler) Start() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		p, err := commonhttpdec.DecodeJSON[authservice.OTPStartRequest](r)
+		if err != nil {
+			hn.enc.Encode(ctx, w, err)
+			return
+		}
+
+		p.IP = clientIP(r)
+
+		resp, err := hn.passwordless.StartOTP(ctx, p)
+		if err != nil {
+			hn.enc.Encode(ctx, w, err)
+			return
+		}
+
+		commonhttpenc.JSONResponseEncoder(ctx, w, http.StatusAccepted, commonhttpresp.NewResponse(resp, nil))
+	}
+}
+
+func (hn *OTPHandler) Complete() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		p, err := commonhttpdec.DecodeJSON[authservice.OTPCompleteRequest](r)
+		if err != nil {
+			hn.enc.Encode(ctx, w, err)
+			return
+		}
+
+		p.UserAgent = r.UserAgent()
+		p.IP = clientIP(r)
+
+		lr, err := hn.passwordless.CompleteOTP(ctx, p)
+		if err != nil {
+			hn.enc.Encode(ctx, w, err)
+			return
+		}
+
+		err = setSessionCookie(hn.cookies, w, lr)
+		if err != nil {
+			hn.enc.Encode(ctx, w, err)
+			return
+		}
+
+		commonhttpenc.JSONResponseEncoder(ctx, w, http.StatusOK, commonhttpresp.NewResponse(lr, nil))
+	}
+}
+
+// clientIP is peer address, or the one set by RealIP middleware when proxy is trusted, which may or may not have port
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
@@ -0,0 +1,236 @@
+package v1authhttphandler
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+
+	commonerr "waizlytest/common/errors"
+
+	commonhttpcookie "waizlytest/common/http/cookie"
+	commonhttpdec "waizlytest/common/http/decoder"
+	commonhttpenc "waizlytest/common/http/encoder"
+	commonhttpresp "waizlytest/common/http/response"
+
+	authservice "waizlytest/services/auth"
+)
+
+// PasskeyHandler manages passkeys of the logged in user, it must be behind the auth middleware
+type PasskeyHandler struct {
+	registrar authservice.PasskeyRegistrar
+
+	enc *commonhttpenc.ErrorEncoder
+}
+
+func NewPasskeyHandler(registrar authservice.PasskeyRegistrar, enc *commonhttpenc.ErrorEncoder) *PasskeyHandler {
+	return &PasskeyHandler{
+		registrar: registrar,
+		enc:       enc,
+	}
+}
+
+func (hn *PasskeyHandler) BeginRegistration() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		// principal is set by the auth middleware
+		principal, ok := authservice.PrincipalFrom(ctx)
+		if !ok {
+			e := commonerr.ErrorForbidden
+			hn.enc.Encode(ctx, w, e)
+			return
+		}
+
+		resp, err := hn.registrar.BeginPasskeyRegistration(ctx, principal.UserID)
+		if err != nil {
+			hn.enc.Encode(ctx, w, err)
+			return
+		}
+
+		commonhttpenc.JSONResponseEncoder(ctx, w, http.StatusOK, commonhttpresp.NewResponse(resp, nil))
+	}
+}
+
+func (hn *PasskeyHandler) FinishRegistration() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		// principal is set by the auth middleware
+		principal, ok := authservice.PrincipalFrom(ctx)
+		if !ok {
+			e := commonerr.ErrorForbidden
+			hn.enc.Encode(ctx, w, e)
+			return
+		}
+
+		p, err := commonhttpdec.DecodeJSON[authservice.PasskeyRegisterRequest](r)
+		if err != nil {
+			hn.enc.Encode(ctx, w, err)
+			return
+		}
+
+		resp, err := hn.registrar.FinishPasskeyRegistration(ctx, principal.UserID, p)
+		if err != nil {
+			hn.enc.Encode(ctx, w, err)
+			return
+		}
+
+		commonhttpenc.JSONResponseEncoder(ctx, w, http.StatusCreated, commonhttpresp.NewResponse(resp, nil))
+	}
+}
+
+func (hn *PasskeyHandler) List() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		// principal is set by the auth middleware
+		principal, ok := authservice.PrincipalFrom(ctx)
+		if !ok {
+			e := commonerr.ErrorForbidden
+			hn.enc.Encode(ctx, w, e)
+			return
+		}
+
+		resp, err := hn.registrar.ListPasskeys(ctx, principal.UserID)
+		if err != nil {
+			hn.enc.Encode(ctx, w, err)
+			return
+		}
+
+		commonhttpenc.JSONResponseEncoder(ctx, w, http.StatusOK, commonhttpresp.NewResponse(resp, nil))
+	}
+}
+
+// Rename expects `{id}` URL param
+func (hn *PasskeyHandler) Rename() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		// principal is set by the auth middleware
+		principal, ok := authservice.PrincipalFrom(ctx)
+		if !ok {
+			e := commonerr.ErrorForbidden
+			hn.enc.Encode(ctx, w, e)
+			return
+		}
+
+		id, err := passkeyID(r)
+		if err != nil {
+			hn.enc.Encode(ctx, w, err)
+			return
+		}
+
+		p, err := commonhttpdec.DecodeJSON[authservice.PasskeyRenameRequest](r)
+		if err != nil {
+			hn.enc.Encode(ctx, w, err)
+			return
+		}
+
+		err = hn.registrar.RenamePasskey(ctx, principal.UserID, id, p)
+		if err != nil {
+			hn.enc.Encode(ctx, w, err)
+			return
+		}
+
+		commonhttpenc.JSONResponseEncoder(ctx, w, http.StatusOK, commonhttpresp.NewResponse(nil, nil))
+	}
+}
+
+// Delete expects `{id}` URL param
+func (hn *PasskeyHandler) Delete() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		// principal is set by the auth middleware
+		principal, ok := authservice.PrincipalFrom(ctx)
+		if !ok {
+			e := commonerr.ErrorForbidden
+			hn.enc.Encode(ctx, w, e)
+			return
+		}
+
+		id, err := passkeyID(r)
+		if err != nil {
+			hn.enc.Encode(ctx, w, err)
+			return
+		}
+
+		err = hn.registrar.DeletePasskey(ctx, principal.UserID, id)
+		if err != nil {
+			hn.enc.Encode(ctx, w, err)
+			return
+		}
+
+		commonhttpenc.JSONResponseEncoder(ctx, w, http.StatusOK, commonhttpresp.NewResponse(nil, nil))
+	}
+}
+
+func passkeyID(r *http.Request) (int64, error) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		e := authservice.ErrPasskeyNotFound.New()
+		return 0, e
+	}
+
+	return id, nil
+}
+
+type PasskeyLoginHandler struct {
+	authn authservice.PasskeyAuthn
+	// cookies is nil when cookie mode is disabled
+	cookies *commonhttpcookie.Jar
+
+	enc *commonhttpenc.ErrorEncoder
+}
+
+func NewPasskeyLoginHandler(authn authservice.PasskeyAuthn, cookies *commonhttpcookie.Jar, enc *commonhttpenc.ErrorEncoder) *PasskeyLoginHandler {
+	return &PasskeyLoginHandler{
+		authn:   authn,
+		cookies: cookies,
+		enc:     enc,
+	}
+}
+
+func (hn *PasskeyLoginHandler) Begin() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		resp, err := hn.authn.BeginPasskeyLogin(ctx)
+		if err != nil {
+			hn.enc.Encode(ctx, w, err)
+			return
+		}
+
+		commonhttpenc.JSONResponseEncoder(ctx, w, http.StatusOK, commonhttpresp.NewResponse(resp, nil))
+	}
+}
+
+func (hn *PasskeyLoginHandler) Finish() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		p, err := commonhttpdec.DecodeJSON[authservice.PasskeyLoginRequest](r)
+		if err != nil {
+			hn.enc.Encode(ctx, w, err)
+			return
+		}
+
+		p.UserAgent = r.UserAgent()
+		p.IP = clientIP(r)
+
+		lr, err := hn.authn.FinishPasskeyLogin(ctx, p)
+		if err != nil {
+			hn.enc.Encode(ctx, w, err)
+			return
+		}
+
+		err = setSessionCookie(hn.cookies, w, lr)
+		if err != nil {
+			hn.enc.Encode(ctx, w, err)
+			return
+		}
+
+		commonhttpenc.JSONResponseEncoder(ctx, w, http.StatusOK, commonhttpresp.NewResponse(lr, nil))
+	}
+}
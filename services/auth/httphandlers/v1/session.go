@@ -0,0 +1,73 @@
+package v1authhttphandler
+
+import (
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+
+	commonerr "waizlytest/common/errors"
+
+	commonhttpenc "waizlytest/common/http/encoder"
+	commonhttpresp "waizlytest/common/http/response"
+
+	authservice "waizlytest/services/auth"
+)
+
+// SessionHandler manages login sessions of the logged in user, it must be behind the auth middleware
+type SessionHandler struct {
+	sessions authservice.Sessions
+
+	enc *commonhttpenc.ErrorEncoder
+}
+
+func NewSessionHandler(sessions authservice.Sessions, enc *commonhttpenc.ErrorEncoder) *SessionHandler {
+	return &SessionHandler{
+		sessions: sessions,
+		enc:      enc,
+	}
+}
+
+func (hn *SessionHandler) List() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		// principal is set by the auth middleware
+		principal, ok := authservice.PrincipalFrom(ctx)
+		if !ok {
+			e := commonerr.ErrorForbidden
+			hn.enc.Encode(ctx, w, e)
+			return
+		}
+
+		resp, err := hn.sessions.ListSessions(ctx, principal.UserID)
+		if err != nil {
+			hn.enc.Encode(ctx, w, err)
+			return
+		}
+
+		commonhttpenc.JSONResponseEncoder(ctx, w, http.StatusOK, commonhttpresp.NewResponse(resp, nil))
+	}
+}
+
+// Revoke expects `{id}` URL param
+func (hn *SessionHandler) Revoke() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		// principal is set by the auth middleware
+		principal, ok := authservice.PrincipalFrom(ctx)
+		if !ok {
+			e := commonerr.ErrorForbidden
+			hn.enc.Encode(ctx, w, e)
+			return
+		}
+
+		err := hn.sessions.RevokeSession(ctx, principal.UserID, chi.URLParam(r, "id"))
+		if err != nil {
+			hn.enc.Encode(ctx, w, err)
+			return
+		}
+
+		commonhttpenc.JSONResponseEncoder(ctx, w, http.StatusOK, commonhttpresp.NewResponse(nil, nil))
+	}
+}
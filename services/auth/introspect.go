@@ -0,0 +1,51 @@
+package authservice
+
+import (
+	"context"
+	"time"
+)
+
+// IntrospectRequest is form of the introspection request, `token_type_hint` is not needed as
+// login token & API key are told apart by their shape.
+type IntrospectRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+// Introspection is state of a token as of RFC 7662, inactive token has only `active` false
+type Introspection struct {
+	Active bool `json:"active"`
+	// TokenType is scheme of `Authorization` header the token is sent with, `Bearer` or `ApiKey`
+	TokenType string `json:"token_type,omitempty"`
+	// Sub is ID of the user
+	Sub string `json:"sub,omitempty"`
+	Exp int64  `json:"exp,omitempty"`
+	Iat int64  `json:"iat,omitempty"`
+	// Iss & Aud are only of login token, when configured
+	Iss string `json:"iss,omitempty"`
+	Aud string `json:"aud,omitempty"`
+	// Scope is space separated scopes of API key, login token is not limited by scope
+	Scope string `json:"scope,omitempty"`
+	// Sid & Session are only of login token
+	Sid     string                `json:"sid,omitempty"`
+	Session *IntrospectionSession `json:"session,omitempty"`
+	// AuthTime & AMR are when & how the user logged in, of login token only
+	AuthTime int64    `json:"auth_time,omitempty"`
+	AMR      []string `json:"amr,omitempty"`
+}
+
+type IntrospectionSession struct {
+	Method     string    `json:"method"`
+	DeviceName string    `json:"device_name"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+}
+
+// Introspector lets resource servers check tokens they receive, seeing revocations as well
+type Introspector interface {
+	// AuthenticateClient checks credential of the resource server
+	AuthenticateClient(ctx context.Context, clientID, secret string) error
+	// Introspect tells whether token, either login token or API key, is active.
+	// Token which is not valid is inactive, not an error. It is read-only, last seen of the
+	// session & last use of the API key are left for the requests of the token itself.
+	Introspect(ctx context.Context, token string) (Introspection, error)
+}
@@ -0,0 +1,239 @@
+package jwtauthservice
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"waizlytest/repositories"
+	authservice "waizlytest/services/auth"
+)
+
+var _ (authservice.APIKeys) = (*APIKeyAuth)(nil)
+var _ (authservice.APIKeyAuthz) = (*APIKeyAuth)(nil)
+
+// apiKeyMark starts every key, so leaked keys are easy to spot, e.g by secret scanners.
+// Key is `wz_<prefix>_<secret>`, prefix finds the key & secret proves it.
+const apiKeyMark = "wz"
+
+const (
+	apiKeyPrefixSize = 6
+	apiKeySecretSize = 32
+)
+
+// apiKeyTouchInterval bounds writes of last used time, it is updated at most once per interval
+const apiKeyTouchInterval = time.Minute
+
+// APIKeyAuth is personal API keys of the users, an alternative to login token for scripts
+type APIKeyAuth struct {
+	apiKeyWriter repositories.APIKeyWriter
+	apiKeyReader repositories.APIKeyReader
+
+	logger *slog.Logger
+}
+
+func NewAPIKeyAuth(
+	apiKeyWriter repositories.APIKeyWriter,
+	apiKeyReader repositories.APIKeyReader,
+	logger *slog.Logger,
+) *APIKeyAuth {
+	return &APIKeyAuth{
+		apiKeyWriter: apiKeyWriter,
+		apiKeyReader: apiKeyReader,
+		logger:       logger,
+	}
+}
+
+func (s *APIKeyAuth) CreateAPIKey(ctx context.Context, userID int64, params authservice.APIKeyCreateRequest) (authservice.APIKeyCreated, error) {
+	resp := authservice.APIKeyCreated{}
+
+	if len(params.Scopes) == 0 {
+		e := authservice.ErrAPIKeyScopeInvalid.New()
+		return resp, e
+	}
+
+	scopes := []string{}
+	for _, scope := range params.Scopes {
+		if !slices.Contains(authservice.Scopes, scope) {
+			e := authservice.ErrAPIKeyScopeInvalid.New()
+			return resp, e
+		}
+
+		if !slices.Contains(scopes, scope) {
+			scopes = append(scopes, scope)
+		}
+	}
+
+	now := time.Now()
+	if params.ExpiresAt != nil && !params.ExpiresAt.After(now) {
+		e := authservice.ErrAPIKeyExpiryInvalid.New()
+		return resp, e
+	}
+
+	prefix, err := randomHex(apiKeyPrefixSize)
+	if err != nil {
+		return resp, err
+	}
+
+	secret, err := randomHex(apiKeySecretSize)
+	if err != nil {
+		return resp, err
+	}
+
+	k := &repositories.APIKey{
+		UserID:     userID,
+		Name:       params.Name,
+		Prefix:     prefix,
+		SecretHash: hashSecret(secret),
+		Scopes:     scopes,
+		ExpiresAt:  params.ExpiresAt,
+		CreatedAt:  now,
+	}
+
+	k.ID, err = s.apiKeyWriter.CreateAPIKey(ctx, k)
+	if err != nil {
+		return resp, err
+	}
+
+	s.logger.InfoContext(ctx, "API key created", slog.Int64("user_id", userID), slog.Int64("api_key_id", k.ID))
+
+	resp.APIKey = toAPIKey(k)
+	resp.Key = apiKeyMark + "_" + prefix + "_" + secret
+	return resp, nil
+}
+
+func (s *APIKeyAuth) ListAPIKeys(ctx context.Context, userID int64) ([]authservice.APIKey, error) {
+	keys, err := s.apiKeyReader.FindAPIKeysByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := make([]authservice.APIKey, 0, len(keys))
+	for _, k := range keys {
+		resp = append(resp, toAPIKey(k))
+	}
+
+	return resp, nil
+}
+
+func (s *APIKeyAuth) DeleteAPIKey(ctx context.Context, userID, id int64) error {
+	ok, err := s.apiKeyWriter.DeleteAPIKey(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		e := authservice.ErrAPIKeyNotFound.New()
+		return e
+	}
+
+	s.logger.InfoContext(ctx, "API key deleted", slog.Int64("user_id", userID), slog.Int64("api_key_id", id))
+	return nil
+}
+
+func (s *APIKeyAuth) ValidateAPIKey(ctx context.Context, key string) (authservice.Principal, error) {
+	k, err := s.validate(ctx, key)
+	if err != nil {
+		return authservice.Principal{}, err
+	}
+
+	p := authservice.Principal{
+		SubjectType: authservice.SubjectAPIKey,
+		UserID:      k.UserID,
+		Roles:       []string{authservice.RoleUser},
+		Scopes:      k.Scopes,
+		AuthTime:    k.CreatedAt,
+	}
+	if p.Scopes == nil {
+		// nil scopes would read as unlimited
+		p.Scopes = []string{}
+	}
+
+	return p, nil
+}
+
+// isAPIKey tells key apart from a login token by its shape
+func isAPIKey(key string) bool {
+	return strings.HasPrefix(key, apiKeyMark+"_")
+}
+
+// validate checks the key by find & touches its last use
+func (s *APIKeyAuth) validate(ctx context.Context, key string) (*repositories.APIKey, error) {
+	k, err := s.find(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if k.LastUsedAt == nil || now.Sub(*k.LastUsedAt) >= apiKeyTouchInterval {
+		// last used is informative, the request goes on anyway
+		err = s.apiKeyWriter.TouchAPIKey(ctx, k.ID, now)
+		if err != nil {
+			s.logger.ErrorContext(ctx, "failed touch API key", slog.Any("error", err))
+		}
+	}
+
+	return k, nil
+}
+
+// find finds the key & checks its secret & expiry, it returns `ErrAPIKeyInvalid` when the key is not valid.
+// It writes nothing, so it suits read-only checks such as introspection.
+func (s *APIKeyAuth) find(ctx context.Context, key string) (*repositories.APIKey, error) {
+	parts := strings.Split(key, "_")
+	if len(parts) != 3 || parts[0] != apiKeyMark || parts[1] == "" || parts[2] == "" {
+		e := authservice.ErrAPIKeyInvalid.New()
+		return nil, e
+	}
+
+	// replica may miss a key just created or deleted
+	k, err := s.apiKeyReader.FindAPIKeyByPrefix(repositories.WithPrimary(ctx), parts[1])
+	if err != nil {
+		return nil, err
+	}
+
+	if k == nil || subtle.ConstantTimeCompare([]byte(hashSecret(parts[2])), []byte(k.SecretHash)) != 1 {
+		e := authservice.ErrAPIKeyInvalid.New()
+		return nil, e
+	}
+
+	if k.ExpiresAt != nil && !k.ExpiresAt.After(time.Now()) {
+		e := authservice.ErrAPIKeyInvalid.New()
+		return nil, e
+	}
+
+	return k, nil
+}
+
+func toAPIKey(k *repositories.APIKey) authservice.APIKey {
+	return authservice.APIKey{
+		ID:         k.ID,
+		Name:       k.Name,
+		Prefix:     apiKeyMark + "_" + k.Prefix,
+		Scopes:     k.Scopes,
+		ExpiresAt:  k.ExpiresAt,
+		CreatedAt:  k.CreatedAt,
+		LastUsedAt: k.LastUsedAt,
+	}
+}
+
+// hashSecret is SHA-256 hex of the secret, the secret is random enough to not need a slow hash
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,129 @@
+package jwtauthservice_test
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/mock"
+
+	commonerr "waizlytest/common/errors"
+
+	"waizlytest/repositories"
+	mockrepositories "waizlytest/repositories/mock"
+
+	authservice "waizlytest/services/auth"
+	jwtauthservice "waizlytest/services/auth/jwt"
+)
+
+func TestAPIKeyAuth_ValidateAPIKey(t *testing.T) {
+	type scenario struct {
+		name    string
+		key     func(created string) string
+		modify  func(k *repositories.APIKey)
+		errCode commonerr.Code
+	}
+
+	scenarios := []scenario{
+		{
+			name: "[OK] Created key",
+			key:  func(created string) string { return created },
+		},
+		{
+			name:    "[Failed] Wrong secret",
+			key:     func(created string) string { return created[:len(created)-1] + "x" },
+			errCode: authservice.ErrAPIKeyInvalid.Code,
+		},
+		{
+			name:    "[Failed] Malformed",
+			key:     func(created string) string { return strings.TrimPrefix(created, "wz_") },
+			errCode: authservice.ErrAPIKeyInvalid.Code,
+		},
+		{
+			name: "[Failed] Expired",
+			key:  func(created string) string { return created },
+			modify: func(k *repositories.APIKey) {
+				at := time.Now().Add(-time.Second)
+				k.ExpiresAt = &at
+			},
+			errCode: authservice.ErrAPIKeyInvalid.Code,
+		},
+	}
+
+	for _, scn := range scenarios {
+		t.Run(scn.name, func(t *testing.T) {
+			stored := &repositories.APIKey{}
+			keyStorage := &mockrepositories.MockAPIKeyRepository{}
+			keyStorage.On("CreateAPIKey", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+				*stored = *args.Get(1).(*repositories.APIKey)
+			}).Return(int64(7), nil)
+			keyStorage.On("FindAPIKeyByPrefix", mock.MatchedBy(repositories.PrimaryPinned), mock.Anything).Return(stored, nil)
+			keyStorage.On("TouchAPIKey", mock.Anything, int64(7), mock.Anything).Return(nil)
+
+			svc := jwtauthservice.NewAPIKeyAuth(keyStorage, keyStorage, slog.Default())
+
+			created, err := svc.CreateAPIKey(context.Background(), 1, authservice.APIKeyCreateRequest{
+				Name:   "CI",
+				Scopes: []string{authservice.ScopeProfileRead, authservice.ScopeProfileRead},
+			})
+			if err != nil {
+				t.Fatalf("failed create: %v", err)
+			}
+
+			if strings.Contains(created.Key, stored.SecretHash) || !strings.HasPrefix(created.Key, created.Prefix+"_") {
+				t.Fatalf("unexpected key %q of stored %+v", created.Key, stored)
+			}
+
+			stored.ID = 7
+			if scn.modify != nil {
+				scn.modify(stored)
+			}
+
+			p, err := svc.ValidateAPIKey(context.Background(), scn.key(created.Key))
+
+			if scn.errCode == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+
+				want := authservice.Principal{
+					SubjectType: authservice.SubjectAPIKey,
+					UserID:      1,
+					Roles:       []string{authservice.RoleUser},
+					Scopes:      []string{authservice.ScopeProfileRead},
+					AuthTime:    stored.CreatedAt,
+				}
+				if diff := cmp.Diff(want, p); diff != "" {
+					t.Errorf("principal mismatch (-want +got):\n%s", diff)
+				}
+
+				keyStorage.AssertCalled(t, "TouchAPIKey", mock.Anything, int64(7), mock.Anything)
+				return
+			}
+
+			e, ok := err.(*commonerr.Error)
+			if !ok {
+				t.Fatalf("expected *commonerr.Error, got %v", err)
+			}
+
+			if e.Code != scn.errCode {
+				t.Errorf("error code mismatch: want %d, got %d", scn.errCode, e.Code)
+			}
+		})
+	}
+}
+
+func TestAPIKeyAuth_CreateAPIKey_UnknownScope(t *testing.T) {
+	keyStorage := &mockrepositories.MockAPIKeyRepository{}
+	svc := jwtauthservice.NewAPIKeyAuth(keyStorage, keyStorage, slog.Default())
+
+	_, err := svc.CreateAPIKey(context.Background(), 1, authservice.APIKeyCreateRequest{Name: "CI", Scopes: []string{"admin"}})
+	if !authservice.ErrAPIKeyScopeInvalid.Is(err) {
+		t.Errorf("expected invalid scope, got %v", err)
+	}
+
+	keyStorage.AssertNotCalled(t, "CreateAPIKey", mock.Anything, mock.Anything)
+}
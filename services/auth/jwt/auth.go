@@ -3,34 +3,74 @@ package jwtauthservice
 import (
 	"context"
 	"crypto/rsa"
+	"errors"
 	"fmt"
+	"log/slog"
 	"strconv"
 	"time"
+	"unicode/utf8"
 
 	"github.com/golang-jwt/jwt/v5"
+	"go.opentelemetry.io/otel"
 	"golang.org/x/crypto/bcrypt"
 
 	commonerr "waizlytest/common/errors"
-
+	commonmail "waizlytest/common/mail"
+	commonphone "waizlytest/common/phone"
 	"waizlytest/repositories"
 	authservice "waizlytest/services/auth"
 )
 
+var tracer = otel.Tracer("waizlytest/services/auth/jwt")
+
 var _ (authservice.Authn) = (*JWTAuth)(nil)
 var _ (authservice.Authz) = (*JWTAuth)(nil)
+var _ (authservice.Sessions) = (*JWTAuth)(nil)
+
+const tokenTTL = time.Hour
+
+// sessionTouchInterval bounds writes of last seen time, it is updated at most once per interval
+const sessionTouchInterval = time.Minute
+
+// TokenConfig sets claims of issued tokens, which are required on validation as well
+type TokenConfig struct {
+	// Issuer is `iss` claim, empty value is neither issued nor validated
+	Issuer string
+	// Audience is `aud` claim, empty value is neither issued nor validated
+	Audience string
+	// Leeway tolerates clock skew between instances on time claims
+	Leeway time.Duration
+	// MaxAge rejects tokens issued longer ago regardless of their expiry, zero means no limit
+	MaxAge time.Duration
+}
 
 type JWTAuth struct {
 	userWriter repositories.UserWriter
 	userReader repositories.UserReader
 
+	sessionWriter repositories.SessionWriter
+	sessionReader repositories.SessionReader
+
 	privateKey *rsa.PrivateKey
 	publicKey  *rsa.PublicKey
+
+	tokenCfg TokenConfig
+	parser   *jwt.Parser
+
+	phones *commonphone.Normalizer
+
+	logger *slog.Logger
 }
 
 func NewJWTAuth(
 	userWriter repositories.UserWriter,
 	userReader repositories.UserReader,
+	sessionWriter repositories.SessionWriter,
+	sessionReader repositories.SessionReader,
 	privateKey, publicKey string,
+	tokenCfg TokenConfig,
+	phones *commonphone.Normalizer,
+	logger *slog.Logger,
 ) (*JWTAuth, error) {
 	pem, err := jwt.ParseRSAPrivateKeyFromPEM([]byte(privateKey))
 	if err != nil {
@@ -43,21 +83,68 @@ func NewJWTAuth(
 	}
 
 	instance := &JWTAuth{
-		userWriter: userWriter,
-		userReader: userReader,
-		privateKey: pem,
-		publicKey:  cert,
+		userWriter:    userWriter,
+		userReader:    userReader,
+		sessionWriter: sessionWriter,
+		sessionReader: sessionReader,
+		privateKey:    pem,
+		publicKey:     cert,
+		tokenCfg:      tokenCfg,
+		parser:        newParser(tokenCfg),
+		phones:        phones,
+		logger:        logger,
 	}
 
 	return instance, nil
 }
 
-func (s *JWTAuth) createToken(usr *repositories.User, now time.Time) (string, error) {
+func newParser(cfg TokenConfig) *jwt.Parser {
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg()}),
+		jwt.WithLeeway(cfg.Leeway),
+		jwt.WithIssuedAt(),
+		jwt.WithExpirationRequired(),
+	}
+
+	if cfg.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(cfg.Issuer))
+	}
+
+	if cfg.Audience != "" {
+		opts = append(opts, jwt.WithAudience(cfg.Audience))
+	}
+
+	return jwt.NewParser(opts...)
+}
+
+// authMethods are `amr` claim of each login method
+var authMethods = map[string][]string{
+	"password": {authservice.AMRPassword},
+	"otp":      {authservice.AMROTP},
+	"passkey":  {authservice.AMRHardware, authservice.AMRMultiFactor},
+}
+
+func (s *JWTAuth) createToken(usr *repositories.User, sid, method string, now time.Time) (string, error) {
 	claims := jwt.MapClaims{
-		"sub":  strconv.FormatInt(usr.ID, 10),
-		"name": usr.FullName,
-		"iat":  now.Unix(),
-		"exp":  now.Add(time.Hour * 1).Unix(),
+		"sub":       strconv.FormatInt(usr.ID, 10),
+		"sid":       sid,
+		"name":      usr.FullName,
+		"iat":       now.Unix(),
+		"nbf":       now.Unix(),
+		"exp":       now.Add(tokenTTL).Unix(),
+		"auth_time": now.Unix(),
+	}
+
+	if amr, ok := authMethods[method]; ok {
+		claims["amr"] = amr
+	}
+
+	if s.tokenCfg.Issuer != "" {
+		claims["iss"] = s.tokenCfg.Issuer
+	}
+
+	if s.tokenCfg.Audience != "" {
+		claims["aud"] = s.tokenCfg.Audience
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
@@ -69,45 +156,178 @@ func (s *JWTAuth) createToken(usr *repositories.User, now time.Time) (string, er
 	return signedToken, nil
 }
 
-func (s *JWTAuth) ValidateToken(token string) (int64, error) {
+// CheckSigningKeys makes sure loaded key pair is usable, by signing and verifying a probe token.
+func (s *JWTAuth) CheckSigningKeys(ctx context.Context) error {
+	token, err := s.createToken(&repositories.User{}, "", "", time.Now())
+	if err != nil {
+		return err
+	}
+
+	_, err = s.getJWT(token)
+	return err
+}
+
+func (s *JWTAuth) ValidateToken(ctx context.Context, token string) (authservice.Principal, error) {
+	jot, id, sid, err := s.parseToken(token)
+	if err != nil {
+		return authservice.Principal{}, err
+	}
+
+	ss, err := s.checkSession(ctx, id, sid)
+	if err != nil {
+		return authservice.Principal{}, err
+	}
+
+	return authservice.Principal{
+		SubjectType: authservice.SubjectUser,
+		UserID:      id,
+		Roles:       []string{authservice.RoleUser},
+		SessionID:   sid,
+		AuthTime:    getAuthTime(jot, ss),
+		AuthMethods: getAuthMethods(jot),
+	}, nil
+}
+
+// parseToken verifies signature & expiry of the token, returning it along with claimed user ID & session ID
+func (s *JWTAuth) parseToken(token string) (*jwt.Token, int64, string, error) {
 	jot, err := s.getJWT(token)
 	if err != nil {
-		return 0, err
+		return nil, 0, "", err
+	}
+
+	if jot == nil || !jot.Valid {
+		e := authservice.ErrTokenInvalid.New()
+		return nil, 0, "", e
+	}
+
+	id, sid, err := s.getClaims(jot)
+	if err != nil {
+		return nil, 0, "", err
+	}
+
+	return jot, id, sid, nil
+}
+
+// checkSession ensures session sid of user id is still active by findSession & touches its last seen
+func (s *JWTAuth) checkSession(ctx context.Context, id int64, sid string) (*repositories.Session, error) {
+	ss, err := s.findSession(ctx, id, sid)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	if now.Sub(ss.LastSeenAt) >= sessionTouchInterval {
+		// last seen is informative, the request goes on anyway
+		err = s.sessionWriter.TouchSession(ctx, sid, now)
+		if err != nil {
+			s.logger.ErrorContext(ctx, "failed touch session", slog.Any("error", err))
+		}
+	}
+
+	return ss, nil
+}
+
+// findSession returns session sid of user id when it is still active, it returns `ErrSessionRevoked` otherwise.
+// It writes nothing, so it suits read-only checks such as introspection.
+func (s *JWTAuth) findSession(ctx context.Context, id int64, sid string) (*repositories.Session, error) {
+	// replica may miss a session just created or revoked
+	ss, err := s.sessionReader.FindSession(repositories.WithPrimary(ctx), sid)
+	if err != nil {
+		return nil, err
 	}
 
-	if jot == nil {
-		return 0, fmt.Errorf("token is not valid")
+	if ss == nil || ss.UserID != id || ss.RevokedAt != nil {
+		e := authservice.ErrSessionRevoked.New()
+		return nil, e
 	}
 
-	if !jot.Valid {
-		return 0, fmt.Errorf("token is not valid")
+	return ss, nil
+}
+
+func (s *JWTAuth) ListSessions(ctx context.Context, userID int64) ([]authservice.Session, error) {
+	sessions, err := s.sessionReader.FindActiveSessionsByUser(ctx, userID, time.Now())
+	if err != nil {
+		return nil, err
 	}
 
-	return s.getClaimedID(jot)
+	resp := make([]authservice.Session, 0, len(sessions))
+	for _, ss := range sessions {
+		resp = append(resp, authservice.Session{
+			ID:         ss.ID,
+			Method:     ss.Method,
+			DeviceName: ss.DeviceName,
+			UserAgent:  ss.UserAgent,
+			IP:         ss.IP,
+			CreatedAt:  ss.CreatedAt,
+			LastSeenAt: ss.LastSeenAt,
+			ExpiresAt:  ss.ExpiresAt,
+		})
+	}
+
+	return resp, nil
+}
+
+func (s *JWTAuth) RevokeSession(ctx context.Context, userID int64, id string) error {
+	ok, err := s.sessionWriter.RevokeSession(ctx, userID, id, time.Now())
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		e := authservice.ErrSessionNotFound.New()
+		return e
+	}
+
+	s.logger.InfoContext(ctx, "session revoked", slog.Int64("user_id", userID), slog.String("session_id", id))
+	return nil
 }
 
 func (s *JWTAuth) Login(ctx context.Context, params authservice.LoginRequest) (authservice.LoginResponse, error) {
+	ctx, span := tracer.Start(ctx, "JWTAuth.Login")
+	defer span.End()
+
 	lr := authservice.LoginResponse{}
 
-	usr, err := s.userReader.FindUserByPhone(ctx, params.Phone)
+	// user may log in right after registering or changing phone, replica could be behind
+	ctx = repositories.WithPrimary(ctx)
+
+	usr, wrongCredential, err := s.findUser(ctx, params)
 	if err != nil {
 		return lr, err
 	}
 
 	if usr == nil {
-		e := commonerr.BadRequest("phone or password is wrong")
+		s.logger.InfoContext(ctx, "login failed", slog.String("reason", "user not found"))
+
+		e := wrongCredential.New()
 		return lr, e
 	}
 
 	// compare
+	_, bcryptSpan := tracer.Start(ctx, "bcrypt.CompareHashAndPassword")
 	err = bcrypt.CompareHashAndPassword([]byte(usr.Password), []byte(params.Password))
+	bcryptSpan.End()
 	if err != nil {
-		e := commonerr.BadRequest("phone or password is wrong")
+		s.logger.InfoContext(ctx, "login failed", slog.String("reason", "wrong password"), slog.Int64("user_id", usr.ID))
+
+		e := wrongCredential.New()
 		return lr, e
 	}
 
+	return s.issue(ctx, usr, "password", authservice.Device{
+		Name:      params.DeviceName,
+		UserAgent: params.UserAgent,
+		IP:        params.IP,
+	})
+}
+
+// issue records attendance of usr, starts the session on device and creates its token,
+// it is the end of every login method.
+func (s *JWTAuth) issue(ctx context.Context, usr *repositories.User, method string, device authservice.Device) (authservice.LoginResponse, error) {
+	lr := authservice.LoginResponse{}
+
 	now := time.Now()
-	err = s.userWriter.CreateUserAttendance(ctx, &repositories.UserAttendance{
+	err := s.userWriter.CreateUserAttendance(ctx, &repositories.UserAttendance{
 		UserID:  usr.ID,
 		LoginAt: now,
 	})
@@ -120,18 +340,66 @@ func (s *JWTAuth) Login(ctx context.Context, params authservice.LoginRequest) (a
 		return lr, err
 	}
 
-	token, err := s.createToken(usr, now)
+	sid, err := randomID()
+	if err != nil {
+		return lr, err
+	}
+
+	err = s.sessionWriter.CreateSession(ctx, &repositories.Session{
+		ID:         sid,
+		UserID:     usr.ID,
+		Method:     method,
+		DeviceName: device.Name,
+		UserAgent:  truncate(device.UserAgent, maxUserAgent),
+		IP:         device.IP,
+		CreatedAt:  now,
+		LastSeenAt: now,
+		ExpiresAt:  now.Add(tokenTTL),
+	})
+	if err != nil {
+		return lr, err
+	}
+
+	_, signSpan := tracer.Start(ctx, "JWTAuth.createToken")
+	token, err := s.createToken(usr, sid, method, now)
+	signSpan.End()
 	if err != nil {
 		return lr, err
 	}
 
+	s.logger.InfoContext(ctx, "user logged in", slog.Int64("user_id", usr.ID), slog.String("method", method), slog.String("session_id", sid))
+
 	lr.ID = usr.ID
 	lr.Token = token
+	lr.ExpiresAt = now.Add(tokenTTL)
 	return lr, nil
 }
 
+// findUser looks the user up by email when it is given, by phone otherwise.
+// It also returns the error of wrong credential, telling which identifier is wrong.
+func (s *JWTAuth) findUser(ctx context.Context, params authservice.LoginRequest) (*repositories.User, *commonerr.Definition, error) {
+	if params.Email != "" {
+		email, err := commonmail.NormalizeAddress(params.Email)
+		if err != nil {
+			return nil, authservice.ErrWrongEmailCredential, nil
+		}
+
+		usr, err := s.userReader.FindUserByEmail(ctx, email)
+		return usr, authservice.ErrWrongEmailCredential, err
+	}
+
+	phone, err := s.phones.Normalize(params.Phone)
+	if err != nil {
+		return nil, authservice.ErrWrongCredential, nil
+	}
+
+	usr, err := s.userReader.FindUserByPhone(ctx, phone)
+	return usr, authservice.ErrWrongCredential, err
+}
+
+// getJWT verifies signature & registered claims of the token, failures are told apart by error code
 func (s *JWTAuth) getJWT(token string) (*jwt.Token, error) {
-	jot, err := jwt.Parse(token, func(token *jwt.Token) (interface{}, error) {
+	jot, err := s.parser.Parse(token, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodRSA); !ok {
 			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
 		}
@@ -139,28 +407,129 @@ func (s *JWTAuth) getJWT(token string) (*jwt.Token, error) {
 		return s.publicKey, nil
 	})
 	if err != nil {
-		return nil, err
+		return nil, tokenError(err)
+	}
+
+	if s.tokenCfg.MaxAge > 0 {
+		// iat is required by the parser, so it is there
+		iat, err := jot.Claims.GetIssuedAt()
+		if err != nil || iat == nil {
+			e := authservice.ErrTokenInvalid.New()
+			return nil, e
+		}
+
+		if time.Since(iat.Time) > s.tokenCfg.MaxAge+s.tokenCfg.Leeway {
+			e := authservice.ErrTokenTooOld.New()
+			return nil, e
+		}
 	}
 
 	return jot, nil
 }
 
-func (s *JWTAuth) getClaimedID(jot *jwt.Token) (int64, error) {
-	var id int64 = 0
+// tokenError maps failure of jwt parser to error of auth domain, the first matching one
+// when more claims fail.
+func tokenError(err error) error {
+	var def *commonerr.Definition
+	switch {
+	case errors.Is(err, jwt.ErrTokenSignatureInvalid), errors.Is(err, jwt.ErrTokenUnverifiable):
+		def = authservice.ErrTokenSignatureInvalid
+	case errors.Is(err, jwt.ErrTokenExpired):
+		def = authservice.ErrTokenExpired
+	case errors.Is(err, jwt.ErrTokenNotValidYet), errors.Is(err, jwt.ErrTokenUsedBeforeIssued):
+		def = authservice.ErrTokenNotYetValid
+	case errors.Is(err, jwt.ErrTokenInvalidIssuer):
+		def = authservice.ErrTokenIssuerInvalid
+	case errors.Is(err, jwt.ErrTokenInvalidAudience):
+		def = authservice.ErrTokenAudienceInvalid
+	default:
+		// malformed token & missing or mistyped claims
+		def = authservice.ErrTokenInvalid
+	}
+
+	e := def.New()
+	return e
+}
+
+// getClaims returns user ID & session ID claimed by the token
+func (s *JWTAuth) getClaims(jot *jwt.Token) (int64, string, error) {
 	claims, ok := jot.Claims.(jwt.MapClaims)
 	if !ok {
-		return 0, fmt.Errorf("token is not valid")
+		e := authservice.ErrTokenInvalid.New()
+		return 0, "", e
 	}
 
 	sub, ok := claims["sub"].(string)
 	if !ok {
-		return 0, fmt.Errorf("token is not valid")
+		e := authservice.ErrTokenInvalid.New()
+		return 0, "", e
 	}
 
 	id, err := strconv.ParseInt(sub, 10, 64)
 	if err != nil {
-		return 0, err
+		e := authservice.ErrTokenInvalid.New()
+		return 0, "", e
+	}
+
+	// token issued before sessions has no sid, it is treated as revoked
+	sid, _ := claims["sid"].(string)
+	if sid == "" {
+		e := authservice.ErrSessionRevoked.New()
+		return 0, "", e
+	}
+
+	return id, sid, nil
+}
+
+// getAuthTime reads `auth_time` claim, token issued before it falls back to login of its session
+func getAuthTime(jot *jwt.Token, ss *repositories.Session) time.Time {
+	claims, ok := jot.Claims.(jwt.MapClaims)
+	if !ok {
+		return ss.CreatedAt
+	}
+
+	at, ok := claims["auth_time"].(float64)
+	if !ok || at <= 0 {
+		return ss.CreatedAt
+	}
+
+	return time.Unix(int64(at), 0)
+}
+
+// getAuthMethods reads `amr` claim, token issued before it has none
+func getAuthMethods(jot *jwt.Token) []string {
+	claims, ok := jot.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil
+	}
+
+	values, _ := claims["amr"].([]interface{})
+	amr := make([]string, 0, len(values))
+	for _, v := range values {
+		if m, ok := v.(string); ok {
+			amr = append(amr, m)
+		}
+	}
+
+	if len(amr) == 0 {
+		return nil
+	}
+
+	return amr
+}
+
+// maxUserAgent is size of the session user agent column
+const maxUserAgent = 255
+
+// truncate cuts s to at most n bytes without splitting a character
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
 	}
 
-	return id, nil
+	return s[:n]
 }
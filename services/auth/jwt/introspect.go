@@ -0,0 +1,169 @@
+package jwtauthservice
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+
+	commoncache "waizlytest/common/cache"
+
+	authservice "waizlytest/services/auth"
+)
+
+var _ (authservice.Introspector) = (*IntrospectAuth)(nil)
+
+// minClientSecret is the least size of client secret in bytes
+const minClientSecret = 16
+
+type IntrospectionConfig struct {
+	// Clients maps ID of the resource servers to their secret, at least 16 bytes
+	Clients map[string]string
+	// CacheTTL is how long a result is reused, so revocation is seen after it at the latest
+	CacheTTL time.Duration
+}
+
+// IntrospectAuth introspects both login token of JWTAuth & API key of APIKeyAuth,
+// with the same checks of their validation.
+type IntrospectAuth struct {
+	auth    *JWTAuth
+	apiKeys *APIKeyAuth
+
+	clients map[string]string
+	cache   *commoncache.TTL[authservice.Introspection]
+
+	logger *slog.Logger
+}
+
+func NewIntrospectAuth(auth *JWTAuth, apiKeys *APIKeyAuth, cfg IntrospectionConfig, logger *slog.Logger) (*IntrospectAuth, error) {
+	for id, secret := range cfg.Clients {
+		if len(secret) < minClientSecret {
+			return nil, fmt.Errorf("secret of client %q must be at least %d bytes", id, minClientSecret)
+		}
+	}
+
+	return &IntrospectAuth{
+		auth:    auth,
+		apiKeys: apiKeys,
+		clients: cfg.Clients,
+		cache:   commoncache.New[authservice.Introspection](cfg.CacheTTL),
+		logger:  logger,
+	}, nil
+}
+
+func (s *IntrospectAuth) AuthenticateClient(ctx context.Context, clientID, secret string) error {
+	want, ok := s.clients[clientID]
+
+	// unknown client takes as long as a known one
+	if subtle.ConstantTimeCompare([]byte(secret), []byte(want)) != 1 || !ok {
+		s.logger.WarnContext(ctx, "introspection client rejected", slog.String("client_id", clientID))
+		e := authservice.ErrClientInvalid.New()
+		return e
+	}
+
+	return nil
+}
+
+func (s *IntrospectAuth) Introspect(ctx context.Context, token string) (authservice.Introspection, error) {
+	// tokens are not kept in memory as is
+	key := hashSecret(token)
+
+	resp, ok := s.cache.Get(key)
+	if ok {
+		return resp, nil
+	}
+
+	var until time.Time
+	var err error
+	if isAPIKey(token) {
+		resp, until, err = s.introspectAPIKey(ctx, token)
+	} else {
+		resp, until, err = s.introspectToken(ctx, token)
+	}
+
+	if err != nil {
+		return resp, err
+	}
+
+	s.cache.Set(key, resp, until)
+	return resp, nil
+}
+
+// introspectToken returns introspection of login token, along with its expiry
+func (s *IntrospectAuth) introspectToken(ctx context.Context, token string) (authservice.Introspection, time.Time, error) {
+	resp := authservice.Introspection{}
+
+	jot, id, sid, err := s.auth.parseToken(token)
+	if err != nil {
+		return resp, time.Time{}, nil
+	}
+
+	ss, err := s.auth.findSession(ctx, id, sid)
+	if err != nil {
+		if authservice.ErrSessionRevoked.Is(err) {
+			return resp, time.Time{}, nil
+		}
+
+		return resp, time.Time{}, err
+	}
+
+	resp.Active = true
+	resp.TokenType = "Bearer"
+	resp.Sub = strconv.FormatInt(id, 10)
+	resp.Iss = s.auth.tokenCfg.Issuer
+	resp.Aud = s.auth.tokenCfg.Audience
+	resp.Sid = sid
+	resp.Session = &authservice.IntrospectionSession{
+		Method:     ss.Method,
+		DeviceName: ss.DeviceName,
+		CreatedAt:  ss.CreatedAt,
+		LastSeenAt: ss.LastSeenAt,
+	}
+	resp.AuthTime = getAuthTime(jot, ss).Unix()
+	resp.AMR = getAuthMethods(jot)
+
+	var until time.Time
+	exp, err := jot.Claims.GetExpirationTime()
+	if err == nil && exp != nil {
+		resp.Exp = exp.Unix()
+		until = exp.Time
+	}
+
+	iat, err := jot.Claims.GetIssuedAt()
+	if err == nil && iat != nil {
+		resp.Iat = iat.Unix()
+	}
+
+	return resp, until, nil
+}
+
+// introspectAPIKey returns introspection of API key, along with its expiry if any
+func (s *IntrospectAuth) introspectAPIKey(ctx context.Context, key string) (authservice.Introspection, time.Time, error) {
+	resp := authservice.Introspection{}
+
+	k, err := s.apiKeys.find(ctx, key)
+	if err != nil {
+		if authservice.ErrAPIKeyInvalid.Is(err) {
+			return resp, time.Time{}, nil
+		}
+
+		return resp, time.Time{}, err
+	}
+
+	resp.Active = true
+	resp.TokenType = "ApiKey"
+	resp.Sub = strconv.FormatInt(k.UserID, 10)
+	resp.Iat = k.CreatedAt.Unix()
+	resp.Scope = strings.Join(k.Scopes, " ")
+
+	var until time.Time
+	if k.ExpiresAt != nil {
+		resp.Exp = k.ExpiresAt.Unix()
+		until = *k.ExpiresAt
+	}
+
+	return resp, until, nil
+}
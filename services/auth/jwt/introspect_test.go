@@ -0,0 +1,157 @@
+package jwtauthservice_test
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/mock"
+	"golang.org/x/crypto/bcrypt"
+
+	"waizlytest/repositories"
+	mockrepositories "waizlytest/repositories/mock"
+
+	authservice "waizlytest/services/auth"
+	jwtauthservice "waizlytest/services/auth/jwt"
+)
+
+func TestIntrospectAuth_Introspect(t *testing.T) {
+	pass, _ := bcrypt.GenerateFromPassword([]byte(`123123`), 6)
+	usr := &repositories.User{ID: 1, Phone: "+6281234567890", Password: string(pass)}
+
+	usrStorage := &mockrepositories.MockUserRepository{}
+	usrStorage.On("FindUserByPhone", mock.Anything, mock.Anything).Return(usr, nil)
+	usrStorage.On("CreateUserAttendance", mock.Anything, mock.AnythingOfType("*repositories.UserAttendance")).Return(nil)
+	usrStorage.On("SaveUserAttendanceSummary", mock.Anything, mock.Anything).Return(nil)
+
+	ss := &repositories.Session{}
+	sessionStorage := &mockrepositories.MockSessionRepository{}
+	sessionStorage.On("CreateSession", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		*ss = *args.Get(1).(*repositories.Session)
+	}).Return(nil)
+	sessionStorage.On("FindSession", mock.Anything, mock.Anything).Return(ss, nil)
+
+	auth, err := jwtauthservice.NewJWTAuth(usrStorage, usrStorage, sessionStorage, sessionStorage, privateCert, publicCert, tokenConfig, newPhones(t), slog.Default())
+	if err != nil {
+		t.Fatalf("failed instantiate auth: %v", err)
+	}
+
+	keyStorage := &mockrepositories.MockAPIKeyRepository{}
+	apiKeys := jwtauthservice.NewAPIKeyAuth(keyStorage, keyStorage, slog.Default())
+
+	newIntrospect := func() *jwtauthservice.IntrospectAuth {
+		svc, err := jwtauthservice.NewIntrospectAuth(auth, apiKeys, jwtauthservice.IntrospectionConfig{
+			Clients:  map[string]string{"rs": "0123456789abcdef"},
+			CacheTTL: time.Minute,
+		}, slog.Default())
+		if err != nil {
+			t.Fatalf("failed instantiate introspection: %v", err)
+		}
+
+		return svc
+	}
+
+	svc := newIntrospect()
+	ctx := context.Background()
+
+	if err := svc.AuthenticateClient(ctx, "rs", "wrong"); !authservice.ErrClientInvalid.Is(err) {
+		t.Errorf("expected invalid client, got %v", err)
+	}
+
+	if err := svc.AuthenticateClient(ctx, "rs", "0123456789abcdef"); err != nil {
+		t.Errorf("unexpected client error: %v", err)
+	}
+
+	lr, err := auth.Login(ctx, authservice.LoginRequest{Phone: "081234567890", Password: "123123", DeviceName: "Laptop"})
+	if err != nil {
+		t.Fatalf("failed login: %v", err)
+	}
+
+	// session seen long ago is not touched by introspection
+	ss.LastSeenAt = time.Now().Add(-time.Hour)
+
+	got, err := svc.Introspect(ctx, lr.Token)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !got.Active || got.Sub != "1" || got.Sid != ss.ID || got.Exp != ss.ExpiresAt.Unix() || got.Session == nil || got.Session.DeviceName != "Laptop" || got.AuthTime != ss.CreatedAt.Unix() || !cmp.Equal(got.AMR, []string{authservice.AMRPassword}) {
+		t.Errorf("unexpected introspection: %+v", got)
+	}
+
+	at := time.Now()
+	ss.RevokedAt = &at
+
+	// result is cached for a while
+	got, _ = svc.Introspect(ctx, lr.Token)
+	if !got.Active {
+		t.Errorf("expected cached active token, got %+v", got)
+	}
+
+	sessionStorage.AssertNumberOfCalls(t, "FindSession", 1)
+
+	got, err = newIntrospect().Introspect(ctx, lr.Token)
+	if err != nil || got.Active {
+		t.Errorf("expected inactive revoked token, got %+v, %v", got, err)
+	}
+
+	sessionStorage.AssertNotCalled(t, "TouchSession", mock.Anything, mock.Anything, mock.Anything)
+
+	got, err = svc.Introspect(ctx, "garbage")
+	if err != nil || !cmp.Equal(got, authservice.Introspection{}) {
+		t.Errorf("expected bare inactive token, got %+v, %v", got, err)
+	}
+}
+
+func TestIntrospectAuth_Introspect_APIKey(t *testing.T) {
+	secret := "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+	sum := sha256.Sum256([]byte(secret))
+
+	k := &repositories.APIKey{
+		ID:         7,
+		UserID:     1,
+		Prefix:     "0123456789ab",
+		SecretHash: hex.EncodeToString(sum[:]),
+		Scopes:     []string{authservice.ScopeProfileRead, authservice.ScopeProfileWrite},
+		CreatedAt:  time.Now().Add(-time.Hour),
+	}
+
+	keyStorage := &mockrepositories.MockAPIKeyRepository{}
+	keyStorage.On("FindAPIKeyByPrefix", mock.Anything, k.Prefix).Return(k, nil)
+
+	auth, err := jwtauthservice.NewJWTAuth(&mockrepositories.MockUserRepository{}, &mockrepositories.MockUserRepository{}, &mockrepositories.MockSessionRepository{}, &mockrepositories.MockSessionRepository{}, privateCert, publicCert, tokenConfig, newPhones(t), slog.Default())
+	if err != nil {
+		t.Fatalf("failed instantiate auth: %v", err)
+	}
+
+	svc, err := jwtauthservice.NewIntrospectAuth(auth, jwtauthservice.NewAPIKeyAuth(keyStorage, keyStorage, slog.Default()), jwtauthservice.IntrospectionConfig{
+		Clients:  map[string]string{"rs": "0123456789abcdef"},
+		CacheTTL: time.Minute,
+	}, slog.Default())
+	if err != nil {
+		t.Fatalf("failed instantiate introspection: %v", err)
+	}
+
+	ctx := context.Background()
+
+	got, err := svc.Introspect(ctx, "wz_0123456789ab_"+secret)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !got.Active || got.TokenType != "ApiKey" || got.Sub != "1" || got.Scope != "profile:read profile:write" {
+		t.Errorf("unexpected introspection: %+v", got)
+	}
+
+	// never used key is not touched by introspection
+	keyStorage.AssertNotCalled(t, "TouchAPIKey", mock.Anything, mock.Anything, mock.Anything)
+
+	got, err = svc.Introspect(ctx, "wz_0123456789ab_wrong")
+	if err != nil || got.Active {
+		t.Errorf("expected inactive wrong key, got %+v, %v", got, err)
+	}
+}
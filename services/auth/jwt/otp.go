@@ -0,0 +1,337 @@
+package jwtauthservice
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"net/url"
+	"strings"
+	"time"
+
+	commonerr "waizlytest/common/errors"
+	commoni18n "waizlytest/common/i18n"
+	commonmail "waizlytest/common/mail"
+	commonratelimit "waizlytest/common/ratelimit"
+	commonsignedlink "waizlytest/common/signedlink"
+	commonsms "waizlytest/common/sms"
+	"waizlytest/repositories"
+	authservice "waizlytest/services/auth"
+)
+
+var _ (authservice.Passwordless) = (*OTPAuth)(nil)
+
+// purposeLoginOTP keeps other signed links from logging in
+const purposeLoginOTP = "login-otp"
+
+const (
+	channelSMS   = "sms"
+	channelEmail = "email"
+)
+
+type OTPConfig struct {
+	CodeTTL time.Duration
+	// MaxAttempts of wrong code before the challenge is dead
+	MaxAttempts int
+	// LinkURL is page of the magic link, token is added as `token` query
+	LinkURL string
+	// Secret keys hash of the codes & signs the magic links, at least 16 bytes
+	Secret string
+}
+
+// OTPAuth is passwordless login of JWTAuth, it issues the same token as Login.
+type OTPAuth struct {
+	auth *JWTAuth
+
+	challengeWriter repositories.LoginChallengeWriter
+	challengeReader repositories.LoginChallengeReader
+
+	mailer commonmail.Mailer
+	sms    commonsms.Sender
+	links  *commonsignedlink.Signer
+
+	// startLimiter is keyed by the identifier, ipLimiter by client IP
+	startLimiter *commonratelimit.Limiter
+	ipLimiter    *commonratelimit.Limiter
+
+	cfg    OTPConfig
+	logger *slog.Logger
+}
+
+func NewOTPAuth(
+	auth *JWTAuth,
+	challengeWriter repositories.LoginChallengeWriter,
+	challengeReader repositories.LoginChallengeReader,
+	mailer commonmail.Mailer,
+	sms commonsms.Sender,
+	startLimiter, ipLimiter *commonratelimit.Limiter,
+	cfg OTPConfig,
+	logger *slog.Logger,
+) (*OTPAuth, error) {
+	links, err := commonsignedlink.New(cfg.Secret, cfg.CodeTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	return &OTPAuth{
+		auth:            auth,
+		challengeWriter: challengeWriter,
+		challengeReader: challengeReader,
+		mailer:          mailer,
+		sms:             sms,
+		links:           links,
+		startLimiter:    startLimiter,
+		ipLimiter:       ipLimiter,
+		cfg:             cfg,
+		logger:          logger,
+	}, nil
+}
+
+func (s *OTPAuth) StartOTP(ctx context.Context, params authservice.OTPStartRequest) (authservice.OTPStartResponse, error) {
+	resp := authservice.OTPStartResponse{}
+
+	if !s.ipLimiter.Allow(params.IP) {
+		s.logger.InfoContext(ctx, "otp rate limited", slog.String("by", "ip"))
+
+		e := commonerr.DefApplicationLimit.New()
+		return resp, e
+	}
+
+	// user may start right after registering, replica could be behind
+	ctx = repositories.WithPrimary(ctx)
+
+	var usr *repositories.User
+	var channel, identifier string
+	var err error
+
+	switch {
+	case params.Email != "":
+		channel = channelEmail
+		identifier, err = commonmail.NormalizeAddress(params.Email)
+		if err != nil {
+			e := commonerr.DefInvalidRequest.New()
+			e.AddTranslatableField("email", "must be a valid email address", "validation.email")
+			return resp, e
+		}
+
+		usr, err = s.auth.userReader.FindUserByEmail(ctx, identifier)
+	case params.Phone != "":
+		channel = channelSMS
+		identifier, err = s.auth.phones.Normalize(params.Phone)
+		if err != nil {
+			e := commonerr.DefInvalidRequest.New()
+			e.AddTranslatableField("phone", "must be a valid phone number", "validation.phone")
+			return resp, e
+		}
+
+		usr, err = s.auth.userReader.FindUserByPhone(ctx, identifier)
+	default:
+		e := commonerr.DefInvalidRequest.New()
+		e.AddTranslatableField("phone", "phone or email is required", "validation.phone_or_email")
+		e.AddTranslatableField("email", "phone or email is required", "validation.phone_or_email")
+		return resp, e
+	}
+
+	if err != nil {
+		return resp, err
+	}
+
+	if !s.startLimiter.Allow(identifier) {
+		s.logger.InfoContext(ctx, "otp rate limited", slog.String("by", "identifier"))
+
+		e := commonerr.DefApplicationLimit.New()
+		return resp, e
+	}
+
+	id, err := randomID()
+	if err != nil {
+		return resp, err
+	}
+
+	resp.ChallengeID = id
+	resp.ExpiresIn = int(s.cfg.CodeTTL.Seconds())
+
+	// same response for unknown user, so it can not be used to find registered ones
+	if usr == nil {
+		s.logger.InfoContext(ctx, "otp not sent", slog.String("reason", "user not found"))
+		return resp, nil
+	}
+
+	code, err := randomCode()
+	if err != nil {
+		return resp, err
+	}
+
+	now := time.Now()
+	err = s.challengeWriter.CreateLoginChallenge(ctx, &repositories.LoginChallenge{
+		ID:        id,
+		UserID:    usr.ID,
+		CodeHash:  s.hash(id, code),
+		Channel:   channel,
+		ExpiresAt: now.Add(s.cfg.CodeTTL),
+		CreatedAt: now,
+	})
+	if err != nil {
+		return resp, err
+	}
+
+	err = s.deliver(ctx, channel, identifier, id, code)
+	if err != nil {
+		return resp, err
+	}
+
+	s.logger.InfoContext(ctx, "otp sent", slog.Int64("user_id", usr.ID), slog.String("channel", channel))
+	return resp, nil
+}
+
+func (s *OTPAuth) CompleteOTP(ctx context.Context, params authservice.OTPCompleteRequest) (authservice.LoginResponse, error) {
+	lr := authservice.LoginResponse{}
+
+	if !s.ipLimiter.Allow(params.IP) {
+		s.logger.InfoContext(ctx, "otp rate limited", slog.String("by", "ip"))
+
+		e := commonerr.DefApplicationLimit.New()
+		return lr, e
+	}
+
+	id, code := params.ChallengeID, params.Code
+	if params.Token != "" {
+		subject, err := s.links.Verify(purposeLoginOTP, params.Token)
+		if err != nil {
+			s.logger.InfoContext(ctx, "otp failed", slog.String("reason", err.Error()))
+
+			e := authservice.ErrOTPInvalid.New()
+			return lr, e
+		}
+
+		id, code, _ = strings.Cut(subject, ":")
+	}
+
+	if id == "" || code == "" {
+		e := authservice.ErrOTPInvalid.New()
+		return lr, e
+	}
+
+	// consuming must see the latest attempts
+	ctx = repositories.WithPrimary(ctx)
+
+	c, err := s.challengeReader.FindLoginChallenge(ctx, id)
+	if err != nil {
+		return lr, err
+	}
+
+	now := time.Now()
+	if c == nil || c.ConsumedAt != nil || !now.Before(c.ExpiresAt) || c.Attempts >= s.cfg.MaxAttempts {
+		s.logger.InfoContext(ctx, "otp failed", slog.String("reason", "challenge is not usable"))
+
+		e := authservice.ErrOTPInvalid.New()
+		return lr, e
+	}
+
+	// attempt is counted before comparing, so concurrent guesses can not go past MaxAttempts
+	counted, err := s.challengeWriter.CountLoginChallengeAttempt(ctx, id, s.cfg.MaxAttempts)
+	if err != nil {
+		return lr, err
+	}
+
+	if !counted {
+		s.logger.InfoContext(ctx, "otp failed", slog.String("reason", "no attempt left"), slog.Int64("user_id", c.UserID))
+
+		e := authservice.ErrOTPInvalid.New()
+		return lr, e
+	}
+
+	if !hmac.Equal([]byte(s.hash(id, code)), []byte(c.CodeHash)) {
+		s.logger.InfoContext(ctx, "otp failed", slog.String("reason", "wrong code"), slog.Int64("user_id", c.UserID))
+
+		e := authservice.ErrOTPInvalid.New()
+		return lr, e
+	}
+
+	consumed, err := s.challengeWriter.ConsumeLoginChallenge(ctx, id, s.cfg.MaxAttempts, now)
+	if err != nil {
+		return lr, err
+	}
+
+	if !consumed {
+		e := authservice.ErrOTPInvalid.New()
+		return lr, e
+	}
+
+	usr, err := s.auth.userReader.FindUserByID(ctx, c.UserID)
+	if err != nil {
+		return lr, err
+	}
+
+	if usr == nil || usr.DeletedAt != nil {
+		e := authservice.ErrOTPInvalid.New()
+		return lr, e
+	}
+
+	return s.auth.issue(ctx, usr, "otp", authservice.Device{
+		Name:      params.DeviceName,
+		UserAgent: params.UserAgent,
+		IP:        params.IP,
+	})
+}
+
+// deliver sends the code in language of the request, email also gets the magic link.
+func (s *OTPAuth) deliver(ctx context.Context, channel, to, id, code string) error {
+	bundle := commoni18n.Default()
+	tag := bundle.Match(commoni18n.Preferences(ctx)...)
+	ttl := s.cfg.CodeTTL.String()
+
+	if channel == channelSMS {
+		text, _ := bundle.Message(tag, "auth.otp.sms", code, ttl)
+		return s.sms.Send(ctx, to, text)
+	}
+
+	link, err := url.Parse(s.cfg.LinkURL)
+	if err != nil {
+		return err
+	}
+
+	q := link.Query()
+	q.Set("token", s.links.Sign(purposeLoginOTP, id+":"+code))
+	link.RawQuery = q.Encode()
+
+	subject, _ := bundle.Message(tag, "auth.otp.email.subject")
+	body, _ := bundle.Message(tag, "auth.otp.email.body", code, link.String(), ttl)
+
+	return s.mailer.Send(ctx, commonmail.Message{
+		To:      to,
+		Subject: subject,
+		Body:    body,
+	})
+}
+
+// hash keys the code with secret & challenge ID, so a leaked table can not be brute forced offline
+func (s *OTPAuth) hash(id, code string) string {
+	mac := hmac.New(sha256.New, []byte(s.cfg.Secret))
+	mac.Write([]byte(id + ":" + code))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func randomID() (string, error) {
+	b := make([]byte, 16)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
+// randomCode is 6 digits code
+func randomCode() (string, error) {
+	n, err := rand.Int(rand.Reader, big.NewInt(1_000_000))
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%06d", n.Int64()), nil
+}
@@ -0,0 +1,229 @@
+package jwtauthservice_test
+
+import (
+	"context"
+	"log/slog"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+
+	commonerr "waizlytest/common/errors"
+	commonratelimit "waizlytest/common/ratelimit"
+
+	"waizlytest/repositories"
+	mockrepositories "waizlytest/repositories/mock"
+
+	authservice "waizlytest/services/auth"
+	jwtauthservice "waizlytest/services/auth/jwt"
+)
+
+// smsRecorder keeps the last sent text instead of sending it
+type smsRecorder struct {
+	to, text string
+}
+
+func (s *smsRecorder) Send(ctx context.Context, to, text string) error {
+	s.to, s.text = to, text
+	return nil
+}
+
+var codePattern = regexp.MustCompile(`\d{6}`)
+
+func TestOTPAuth_CompleteOTP(t *testing.T) {
+	type scenario struct {
+		name   string
+		code   func(sent string) string
+		modify func(c *repositories.LoginChallenge)
+		// noAttemptLeft is when concurrent attempts used the last ones after the challenge is read
+		noAttemptLeft bool
+		errCode       commonerr.Code
+	}
+
+	scenarios := []scenario{
+		{
+			name: "[OK] Sent code",
+			code: func(sent string) string { return sent },
+		},
+		{
+			name:    "[Failed] Wrong code",
+			code:    func(sent string) string { return "x" + sent },
+			errCode: authservice.ErrOTPInvalid.Code,
+		},
+		{
+			name:    "[Failed] Expired",
+			code:    func(sent string) string { return sent },
+			modify:  func(c *repositories.LoginChallenge) { c.ExpiresAt = time.Now().Add(-time.Second) },
+			errCode: authservice.ErrOTPInvalid.Code,
+		},
+		{
+			name:    "[Failed] Too many attempts",
+			code:    func(sent string) string { return sent },
+			modify:  func(c *repositories.LoginChallenge) { c.Attempts = 3 },
+			errCode: authservice.ErrOTPInvalid.Code,
+		},
+		{
+			name:          "[Failed] Attempts used up concurrently",
+			code:          func(sent string) string { return sent },
+			noAttemptLeft: true,
+			errCode:       authservice.ErrOTPInvalid.Code,
+		},
+		{
+			name: "[Failed] Consumed",
+			code: func(sent string) string { return sent },
+			modify: func(c *repositories.LoginChallenge) {
+				at := time.Now()
+				c.ConsumedAt = &at
+			},
+			errCode: authservice.ErrOTPInvalid.Code,
+		},
+	}
+
+	for _, scn := range scenarios {
+		t.Run(scn.name, func(t *testing.T) {
+			usr := &repositories.User{ID: 1, FullName: "Test", Phone: "+6281234567890"}
+
+			usrStorage := &mockrepositories.MockUserRepository{}
+			usrStorage.On("FindUserByPhone", mock.Anything, "+6281234567890").Return(usr, nil)
+			usrStorage.On("FindUserByID", mock.Anything, int64(1)).Return(usr, nil)
+			usrStorage.On("CreateUserAttendance", mock.Anything, mock.AnythingOfType("*repositories.UserAttendance")).Return(nil)
+			usrStorage.On("SaveUserAttendanceSummary", mock.Anything, int64(1)).Return(nil)
+
+			challenge := &repositories.LoginChallenge{}
+			challengeStorage := &mockrepositories.MockLoginChallengeRepository{}
+			challengeStorage.On("CreateLoginChallenge", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+				*challenge = *args.Get(1).(*repositories.LoginChallenge)
+			}).Return(nil)
+			challengeStorage.On("FindLoginChallenge", mock.Anything, mock.Anything).Return(challenge, nil)
+			challengeStorage.On("CountLoginChallengeAttempt", mock.Anything, mock.Anything, 3).Return(!scn.noAttemptLeft, nil)
+			challengeStorage.On("ConsumeLoginChallenge", mock.Anything, mock.Anything, 3, mock.Anything).Return(true, nil)
+
+			sessionStorage := newSessionStorage()
+			auth, err := jwtauthservice.NewJWTAuth(usrStorage, usrStorage, sessionStorage, sessionStorage, privateCert, publicCert, tokenConfig, newPhones(t), slog.Default())
+			if err != nil {
+				t.Fatalf("failed instantiate auth: %v", err)
+			}
+
+			sms := &smsRecorder{}
+			svc, err := jwtauthservice.NewOTPAuth(
+				auth,
+				challengeStorage,
+				challengeStorage,
+				nil,
+				sms,
+				commonratelimit.New(10, time.Minute),
+				commonratelimit.New(10, time.Minute),
+				jwtauthservice.OTPConfig{
+					CodeTTL:     time.Minute,
+					MaxAttempts: 3,
+					LinkURL:     "http://localhost/login/otp",
+					Secret:      "0123456789abcdef",
+				},
+				slog.Default(),
+			)
+			if err != nil {
+				t.Fatalf("failed instantiate OTP: %v", err)
+			}
+
+			started, err := svc.StartOTP(context.Background(), authservice.OTPStartRequest{Phone: "081234567890", IP: "127.0.0.1"})
+			if err != nil {
+				t.Fatalf("failed start: %v", err)
+			}
+
+			if sms.to != "+6281234567890" {
+				t.Fatalf("SMS recipient mismatch: got %q", sms.to)
+			}
+
+			sent := codePattern.FindString(sms.text)
+			if sent == "" {
+				t.Fatalf("no code in SMS: %q", sms.text)
+			}
+
+			if challenge.CodeHash == sent {
+				t.Fatalf("code is stored in plain")
+			}
+
+			if scn.modify != nil {
+				scn.modify(challenge)
+			}
+
+			resp, err := svc.CompleteOTP(context.Background(), authservice.OTPCompleteRequest{
+				ChallengeID: started.ChallengeID,
+				Code:        scn.code(sent),
+				IP:          "127.0.0.1",
+			})
+
+			if scn.errCode == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+
+				if resp.ID != 1 || resp.Token == "" {
+					t.Errorf("unexpected response: %+v", resp)
+				}
+
+				challengeStorage.AssertCalled(t, "CountLoginChallengeAttempt", mock.Anything, started.ChallengeID, 3)
+				challengeStorage.AssertCalled(t, "ConsumeLoginChallenge", mock.Anything, started.ChallengeID, 3, mock.Anything)
+				return
+			}
+
+			e, ok := err.(*commonerr.Error)
+			if !ok {
+				t.Fatalf("expected *commonerr.Error, got %v", err)
+			}
+
+			if e.Code != scn.errCode {
+				t.Errorf("error code mismatch: want %d, got %d", scn.errCode, e.Code)
+			}
+
+			challengeStorage.AssertNotCalled(t, "ConsumeLoginChallenge", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestOTPAuth_StartOTP_UnknownUser(t *testing.T) {
+	usrStorage := &mockrepositories.MockUserRepository{}
+	usrStorage.On("FindUserByPhone", mock.Anything, mock.Anything).Return((*repositories.User)(nil), nil)
+
+	challengeStorage := &mockrepositories.MockLoginChallengeRepository{}
+
+	sessionStorage := newSessionStorage()
+	auth, err := jwtauthservice.NewJWTAuth(usrStorage, usrStorage, sessionStorage, sessionStorage, privateCert, publicCert, tokenConfig, newPhones(t), slog.Default())
+	if err != nil {
+		t.Fatalf("failed instantiate auth: %v", err)
+	}
+
+	sms := &smsRecorder{}
+	svc, err := jwtauthservice.NewOTPAuth(
+		auth,
+		challengeStorage,
+		challengeStorage,
+		nil,
+		sms,
+		commonratelimit.New(1, time.Minute),
+		commonratelimit.New(10, time.Minute),
+		jwtauthservice.OTPConfig{CodeTTL: time.Minute, MaxAttempts: 3, Secret: "0123456789abcdef"},
+		slog.Default(),
+	)
+	if err != nil {
+		t.Fatalf("failed instantiate OTP: %v", err)
+	}
+
+	resp, err := svc.StartOTP(context.Background(), authservice.OTPStartRequest{Phone: "081234567890", IP: "127.0.0.1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if resp.ChallengeID == "" || sms.text != "" {
+		t.Errorf("unknown user must look like a sent code without sending it: %+v, %q", resp, sms.text)
+	}
+
+	challengeStorage.AssertNotCalled(t, "CreateLoginChallenge", mock.Anything, mock.Anything)
+
+	// identifier limit applies to unknown user as well
+	_, err = svc.StartOTP(context.Background(), authservice.OTPStartRequest{Phone: "081234567890", IP: "127.0.0.1"})
+	if !commonerr.DefApplicationLimit.Is(err) {
+		t.Errorf("expected application limit, got %v", err)
+	}
+}
@@ -0,0 +1,480 @@
+package jwtauthservice
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+
+	commonsignedlink "waizlytest/common/signedlink"
+	"waizlytest/repositories"
+	authservice "waizlytest/services/auth"
+)
+
+var _ (authservice.PasskeyRegistrar) = (*PasskeyAuth)(nil)
+var _ (authservice.PasskeyAuthn) = (*PasskeyAuth)(nil)
+
+// purposes of the signed ceremony sessions, so one can not be used for the other
+const (
+	purposePasskeyRegistration = "passkey-registration"
+	purposePasskeyLogin        = "passkey-login"
+)
+
+const defaultPasskeyName = "Passkey"
+
+type PasskeyConfig struct {
+	// RPID is domain of the app, e.g `example.com`, passkeys are bound to it
+	RPID          string
+	RPDisplayName string
+	// RPOrigins are origins of the pages running the ceremonies, e.g `https://example.com`
+	RPOrigins []string
+	// CeremonyTTL is how long the browser has to answer the challenge
+	CeremonyTTL time.Duration
+	// Secret signs the ceremony sessions, at least 16 bytes
+	Secret string
+}
+
+// PasskeyAuth is WebAuthn login of JWTAuth, it issues the same token as Login.
+// Ceremony state is kept by the client as a signed session, so it works on any instance of the app,
+// while challenges of finished ceremonies are recorded so each session is used only once.
+type PasskeyAuth struct {
+	auth *JWTAuth
+
+	passkeyWriter repositories.PasskeyWriter
+	passkeyReader repositories.PasskeyReader
+
+	webauthn *webauthn.WebAuthn
+	sessions *commonsignedlink.Signer
+
+	logger *slog.Logger
+}
+
+func NewPasskeyAuth(
+	auth *JWTAuth,
+	passkeyWriter repositories.PasskeyWriter,
+	passkeyReader repositories.PasskeyReader,
+	cfg PasskeyConfig,
+	logger *slog.Logger,
+) (*PasskeyAuth, error) {
+	sessions, err := commonsignedlink.New(cfg.Secret, cfg.CeremonyTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	w, err := webauthn.New(&webauthn.Config{
+		RPID:          cfg.RPID,
+		RPDisplayName: cfg.RPDisplayName,
+		RPOrigins:     cfg.RPOrigins,
+		// discoverable credential, so login does not ask for phone or email first
+		AuthenticatorSelection: protocol.AuthenticatorSelection{
+			ResidentKey:      protocol.ResidentKeyRequirementRequired,
+			UserVerification: protocol.VerificationRequired,
+		},
+		Timeouts: webauthn.TimeoutsConfig{
+			Login:        webauthn.TimeoutConfig{Timeout: cfg.CeremonyTTL, TimeoutUVD: cfg.CeremonyTTL},
+			Registration: webauthn.TimeoutConfig{Timeout: cfg.CeremonyTTL, TimeoutUVD: cfg.CeremonyTTL},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &PasskeyAuth{
+		auth:          auth,
+		passkeyWriter: passkeyWriter,
+		passkeyReader: passkeyReader,
+		webauthn:      w,
+		sessions:      sessions,
+		logger:        logger,
+	}, nil
+}
+
+func (s *PasskeyAuth) BeginPasskeyRegistration(ctx context.Context, userID int64) (authservice.PasskeyCeremony, error) {
+	resp := authservice.PasskeyCeremony{}
+
+	usr, err := s.findUser(ctx, userID)
+	if err != nil {
+		return resp, err
+	}
+
+	if usr == nil {
+		e := authservice.ErrPasskeyInvalid.New()
+		return resp, e
+	}
+
+	exclusions := make([]protocol.CredentialDescriptor, 0, len(usr.passkeys))
+	for _, c := range usr.WebAuthnCredentials() {
+		exclusions = append(exclusions, c.Descriptor())
+	}
+
+	creation, session, err := s.webauthn.BeginRegistration(usr, webauthn.WithExclusions(exclusions))
+	if err != nil {
+		return resp, err
+	}
+
+	resp.Session, err = s.signSession(purposePasskeyRegistration, session)
+	if err != nil {
+		return resp, err
+	}
+
+	resp.Options = creation
+	return resp, nil
+}
+
+func (s *PasskeyAuth) FinishPasskeyRegistration(ctx context.Context, userID int64, params authservice.PasskeyRegisterRequest) (authservice.Passkey, error) {
+	resp := authservice.Passkey{}
+
+	session, err := s.verifySession(ctx, purposePasskeyRegistration, params.Session)
+	if err != nil {
+		return resp, err
+	}
+
+	parsed, err := protocol.ParseCredentialCreationResponseBody(bytes.NewReader(params.Credential))
+	if err != nil {
+		return resp, s.invalid(ctx, "malformed credential", err)
+	}
+
+	usr, err := s.findUser(ctx, userID)
+	if err != nil {
+		return resp, err
+	}
+
+	if usr == nil {
+		e := authservice.ErrPasskeyInvalid.New()
+		return resp, e
+	}
+
+	// session of another user fails here as well, its user ID does not match
+	cred, err := s.webauthn.CreateCredential(usr, session, parsed)
+	if err != nil {
+		return resp, s.invalid(ctx, "credential not verified", err)
+	}
+
+	err = s.consumeSession(ctx, session)
+	if err != nil {
+		return resp, err
+	}
+
+	name := params.Name
+	if name == "" {
+		name = defaultPasskeyName
+	}
+
+	pk := &repositories.Passkey{
+		UserID:          userID,
+		CredentialID:    cred.ID,
+		PublicKey:       cred.PublicKey,
+		AttestationType: cred.AttestationType,
+		AAGUID:          cred.Authenticator.AAGUID,
+		SignCount:       cred.Authenticator.SignCount,
+		BackupEligible:  cred.Flags.BackupEligible,
+		BackupState:     cred.Flags.BackupState,
+		Name:            name,
+		CreatedAt:       time.Now(),
+	}
+	for _, t := range cred.Transport {
+		pk.Transports = append(pk.Transports, string(t))
+	}
+
+	pk.ID, err = s.passkeyWriter.CreatePasskey(ctx, pk)
+	if err != nil {
+		if errors.Is(err, repositories.ErrDuplicate) {
+			return resp, s.invalid(ctx, "credential registered already", err)
+		}
+
+		return resp, err
+	}
+
+	s.logger.InfoContext(ctx, "passkey registered", slog.Int64("user_id", userID), slog.Int64("passkey_id", pk.ID))
+	return toPasskey(pk), nil
+}
+
+func (s *PasskeyAuth) ListPasskeys(ctx context.Context, userID int64) ([]authservice.Passkey, error) {
+	passkeys, err := s.passkeyReader.FindPasskeysByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := make([]authservice.Passkey, 0, len(passkeys))
+	for _, p := range passkeys {
+		resp = append(resp, toPasskey(p))
+	}
+
+	return resp, nil
+}
+
+func (s *PasskeyAuth) RenamePasskey(ctx context.Context, userID, id int64, params authservice.PasskeyRenameRequest) error {
+	ok, err := s.passkeyWriter.RenamePasskey(ctx, userID, id, params.Name)
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		e := authservice.ErrPasskeyNotFound.New()
+		return e
+	}
+
+	return nil
+}
+
+func (s *PasskeyAuth) DeletePasskey(ctx context.Context, userID, id int64) error {
+	ok, err := s.passkeyWriter.DeletePasskey(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		e := authservice.ErrPasskeyNotFound.New()
+		return e
+	}
+
+	s.logger.InfoContext(ctx, "passkey deleted", slog.Int64("user_id", userID), slog.Int64("passkey_id", id))
+	return nil
+}
+
+func (s *PasskeyAuth) BeginPasskeyLogin(ctx context.Context) (authservice.PasskeyCeremony, error) {
+	resp := authservice.PasskeyCeremony{}
+
+	assertion, session, err := s.webauthn.BeginDiscoverableLogin()
+	if err != nil {
+		return resp, err
+	}
+
+	resp.Session, err = s.signSession(purposePasskeyLogin, session)
+	if err != nil {
+		return resp, err
+	}
+
+	resp.Options = assertion
+	return resp, nil
+}
+
+func (s *PasskeyAuth) FinishPasskeyLogin(ctx context.Context, params authservice.PasskeyLoginRequest) (authservice.LoginResponse, error) {
+	lr := authservice.LoginResponse{}
+
+	session, err := s.verifySession(ctx, purposePasskeyLogin, params.Session)
+	if err != nil {
+		return lr, err
+	}
+
+	parsed, err := protocol.ParseCredentialRequestResponseBody(bytes.NewReader(params.Credential))
+	if err != nil {
+		return lr, s.invalid(ctx, "malformed assertion", err)
+	}
+
+	var usr *passkeyUser
+	handler := func(rawID, userHandle []byte) (webauthn.User, error) {
+		id, err := strconv.ParseInt(string(userHandle), 10, 64)
+		if err != nil {
+			return nil, err
+		}
+
+		usr, err = s.findUser(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+
+		if usr == nil {
+			return nil, errors.New("user not found")
+		}
+
+		return usr, nil
+	}
+
+	cred, err := s.webauthn.ValidateDiscoverableLogin(handler, session, parsed)
+	if err != nil {
+		return lr, s.invalid(ctx, "assertion not verified", err)
+	}
+
+	pk := usr.passkey(cred.ID)
+
+	// counter going backward means the private key is used somewhere else as well
+	if cred.Authenticator.CloneWarning {
+		s.logger.WarnContext(ctx, "passkey login failed", slog.String("reason", "sign counter not increased"), slog.Int64("user_id", usr.ID), slog.Int64("passkey_id", pk.ID))
+
+		e := authservice.ErrPasskeyInvalid.New()
+		return lr, e
+	}
+
+	// synced passkeys keep the counter at 0, so only the recorded challenge stops a replay
+	err = s.consumeSession(ctx, session)
+	if err != nil {
+		return lr, err
+	}
+
+	err = s.passkeyWriter.UpdatePasskeyUsage(ctx, pk.ID, cred.Authenticator.SignCount, cred.Flags.BackupState, time.Now())
+	if err != nil {
+		return lr, err
+	}
+
+	return s.auth.issue(ctx, usr.User, "passkey", authservice.Device{
+		Name:      params.DeviceName,
+		UserAgent: params.UserAgent,
+		IP:        params.IP,
+	})
+}
+
+// findUser loads active user along with the passkeys, nil when there is no such user
+func (s *PasskeyAuth) findUser(ctx context.Context, id int64) (*passkeyUser, error) {
+	// passkey may be used right after registered, replica could be behind
+	ctx = repositories.WithPrimary(ctx)
+
+	usr, err := s.auth.userReader.FindUserByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if usr == nil || usr.DeletedAt != nil {
+		return nil, nil
+	}
+
+	passkeys, err := s.passkeyReader.FindPasskeysByUser(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &passkeyUser{User: usr, passkeys: passkeys}, nil
+}
+
+func (s *PasskeyAuth) signSession(purpose string, session *webauthn.SessionData) (string, error) {
+	b, err := json.Marshal(session)
+	if err != nil {
+		return "", err
+	}
+
+	return s.sessions.Sign(purpose, base64.RawURLEncoding.EncodeToString(b)), nil
+}
+
+func (s *PasskeyAuth) verifySession(ctx context.Context, purpose, token string) (webauthn.SessionData, error) {
+	session := webauthn.SessionData{}
+
+	subject, err := s.sessions.Verify(purpose, token)
+	if err != nil {
+		return session, s.invalid(ctx, "session not verified", err)
+	}
+
+	b, err := base64.RawURLEncoding.DecodeString(subject)
+	if err != nil {
+		return session, s.invalid(ctx, "malformed session", err)
+	}
+
+	err = json.Unmarshal(b, &session)
+	if err != nil {
+		return session, s.invalid(ctx, "malformed session", err)
+	}
+
+	return session, nil
+}
+
+// consumeSession records challenge of the verified ceremony, it fails with ErrPasskeyInvalid
+// when the session is used already, e.g an answer captured along with its session is replayed.
+func (s *PasskeyAuth) consumeSession(ctx context.Context, session webauthn.SessionData) error {
+	ok, err := s.passkeyWriter.ConsumePasskeyChallenge(ctx, session.Challenge, session.Expires)
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		return s.invalid(ctx, "session used already", nil)
+	}
+
+	return nil
+}
+
+// invalid logs why the ceremony failed, the client only gets ErrPasskeyInvalid
+func (s *PasskeyAuth) invalid(ctx context.Context, reason string, err error) error {
+	attrs := []any{slog.String("reason", reason), slog.Any("error", err)}
+
+	var perr *protocol.Error
+	if errors.As(err, &perr) {
+		attrs = append(attrs, slog.String("details", perr.Details), slog.String("debug", perr.DevInfo))
+	}
+
+	s.logger.InfoContext(ctx, "passkey ceremony failed", attrs...)
+
+	e := authservice.ErrPasskeyInvalid.New()
+	return e
+}
+
+func toPasskey(p *repositories.Passkey) authservice.Passkey {
+	return authservice.Passkey{
+		ID:         p.ID,
+		Name:       p.Name,
+		Synced:     p.BackupState,
+		CreatedAt:  p.CreatedAt,
+		LastUsedAt: p.LastUsedAt,
+	}
+}
+
+var _ (webauthn.User) = (*passkeyUser)(nil)
+
+// passkeyUser adapts the user & the passkeys to `webauthn.User`
+type passkeyUser struct {
+	*repositories.User
+	passkeys []*repositories.Passkey
+}
+
+// WebAuthnID is user handle kept by the authenticator, it is decimal user ID
+func (u *passkeyUser) WebAuthnID() []byte {
+	return []byte(strconv.FormatInt(u.ID, 10))
+}
+
+// WebAuthnName is shown by the authenticator to pick the account
+func (u *passkeyUser) WebAuthnName() string {
+	if u.Email != "" {
+		return u.Email
+	}
+
+	return u.Phone
+}
+
+func (u *passkeyUser) WebAuthnDisplayName() string {
+	return u.FullName
+}
+
+func (u *passkeyUser) WebAuthnIcon() string {
+	return ""
+}
+
+func (u *passkeyUser) WebAuthnCredentials() []webauthn.Credential {
+	creds := make([]webauthn.Credential, 0, len(u.passkeys))
+	for _, p := range u.passkeys {
+		cred := webauthn.Credential{
+			ID:              p.CredentialID,
+			PublicKey:       p.PublicKey,
+			AttestationType: p.AttestationType,
+			Flags: webauthn.CredentialFlags{
+				BackupEligible: p.BackupEligible,
+				BackupState:    p.BackupState,
+			},
+			Authenticator: webauthn.Authenticator{
+				AAGUID:    p.AAGUID,
+				SignCount: p.SignCount,
+			},
+		}
+		for _, t := range p.Transports {
+			cred.Transport = append(cred.Transport, protocol.AuthenticatorTransport(t))
+		}
+
+		creds = append(creds, cred)
+	}
+
+	return creds
+}
+
+func (u *passkeyUser) passkey(credentialID []byte) *repositories.Passkey {
+	for _, p := range u.passkeys {
+		if bytes.Equal(p.CredentialID, credentialID) {
+			return p
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,387 @@
+package jwtauthservice_test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"log/slog"
+	"testing"
+	"time"
+
+	"github.com/go-webauthn/webauthn/protocol/webauthncbor"
+	"github.com/stretchr/testify/mock"
+
+	commonerr "waizlytest/common/errors"
+
+	"waizlytest/repositories"
+	mockrepositories "waizlytest/repositories/mock"
+
+	authservice "waizlytest/services/auth"
+	jwtauthservice "waizlytest/services/auth/jwt"
+)
+
+const passkeyOrigin = "https://example.com"
+
+// softAuthenticator is a software WebAuthn authenticator with a single P-256 credential,
+// it answers the ceremonies the way a browser passes them to the server.
+type softAuthenticator struct {
+	key          *ecdsa.PrivateKey
+	credentialID []byte
+	userHandle   []byte
+	counter      uint32
+	origin       string
+}
+
+func newSoftAuthenticator(t *testing.T) *softAuthenticator {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed generate key: %v", err)
+	}
+
+	credentialID := make([]byte, 16)
+	_, _ = rand.Read(credentialID)
+
+	return &softAuthenticator{key: key, credentialID: credentialID, origin: passkeyOrigin}
+}
+
+// ceremonyOptions is the part of the options the authenticator needs
+type ceremonyOptions struct {
+	PublicKey struct {
+		Challenge string `json:"challenge"`
+		RPID      string `json:"rpId"`
+		RP        struct {
+			ID string `json:"id"`
+		} `json:"rp"`
+		User struct {
+			ID string `json:"id"`
+		} `json:"user"`
+	} `json:"publicKey"`
+}
+
+func (a *softAuthenticator) options(t *testing.T, ceremony authservice.PasskeyCeremony) ceremonyOptions {
+	b, err := json.Marshal(ceremony.Options)
+	if err != nil {
+		t.Fatalf("failed marshal options: %v", err)
+	}
+
+	var opts ceremonyOptions
+	err = json.Unmarshal(b, &opts)
+	if err != nil {
+		t.Fatalf("failed unmarshal options: %v", err)
+	}
+
+	return opts
+}
+
+func (a *softAuthenticator) authData(rpID string, flags byte, attested []byte) []byte {
+	rpIDHash := sha256.Sum256([]byte(rpID))
+
+	data := append([]byte{}, rpIDHash[:]...)
+	data = append(data, flags)
+	data = binary.BigEndian.AppendUint32(data, a.counter)
+	return append(data, attested...)
+}
+
+func (a *softAuthenticator) clientData(typ, challenge string) []byte {
+	b, _ := json.Marshal(map[string]string{
+		"type":      typ,
+		"challenge": challenge,
+		"origin":    a.origin,
+	})
+
+	return b
+}
+
+// create answers `navigator.credentials.create` with `none` attestation
+func (a *softAuthenticator) create(t *testing.T, ceremony authservice.PasskeyCeremony) json.RawMessage {
+	opts := a.options(t, ceremony)
+	enc := base64.RawURLEncoding
+
+	var err error
+	a.userHandle, err = enc.DecodeString(opts.PublicKey.User.ID)
+	if err != nil {
+		t.Fatalf("failed decode user handle: %v", err)
+	}
+
+	coseKey, err := webauthncbor.Marshal(map[int]any{
+		1:  2,  // EC2
+		3:  -7, // ES256
+		-1: 1,  // P-256
+		-2: a.key.X.FillBytes(make([]byte, 32)),
+		-3: a.key.Y.FillBytes(make([]byte, 32)),
+	})
+	if err != nil {
+		t.Fatalf("failed marshal key: %v", err)
+	}
+
+	attested := make([]byte, 16) // zero AAGUID
+	attested = binary.BigEndian.AppendUint16(attested, uint16(len(a.credentialID)))
+	attested = append(attested, a.credentialID...)
+	attested = append(attested, coseKey...)
+
+	// user present, user verified, attested credential data
+	authData := a.authData(opts.PublicKey.RP.ID, 0x01|0x04|0x40, attested)
+
+	attestation, err := webauthncbor.Marshal(map[string]any{
+		"fmt":      "none",
+		"attStmt":  map[string]any{},
+		"authData": authData,
+	})
+	if err != nil {
+		t.Fatalf("failed marshal attestation: %v", err)
+	}
+
+	b, _ := json.Marshal(map[string]any{
+		"id":    enc.EncodeToString(a.credentialID),
+		"rawId": enc.EncodeToString(a.credentialID),
+		"type":  "public-key",
+		"response": map[string]string{
+			"clientDataJSON":    enc.EncodeToString(a.clientData("webauthn.create", opts.PublicKey.Challenge)),
+			"attestationObject": enc.EncodeToString(attestation),
+		},
+	})
+
+	return b
+}
+
+// get answers `navigator.credentials.get`, counting the signature
+func (a *softAuthenticator) get(t *testing.T, ceremony authservice.PasskeyCeremony) json.RawMessage {
+	opts := a.options(t, ceremony)
+	enc := base64.RawURLEncoding
+
+	a.counter++
+	authData := a.authData(opts.PublicKey.RPID, 0x01|0x04, nil)
+	clientData := a.clientData("webauthn.get", opts.PublicKey.Challenge)
+
+	clientDataHash := sha256.Sum256(clientData)
+	digest := sha256.Sum256(append(append([]byte{}, authData...), clientDataHash[:]...))
+
+	sig, err := ecdsa.SignASN1(rand.Reader, a.key, digest[:])
+	if err != nil {
+		t.Fatalf("failed sign: %v", err)
+	}
+
+	b, _ := json.Marshal(map[string]any{
+		"id":    enc.EncodeToString(a.credentialID),
+		"rawId": enc.EncodeToString(a.credentialID),
+		"type":  "public-key",
+		"response": map[string]string{
+			"clientDataJSON":    enc.EncodeToString(clientData),
+			"authenticatorData": enc.EncodeToString(authData),
+			"signature":         enc.EncodeToString(sig),
+			"userHandle":        enc.EncodeToString(a.userHandle),
+		},
+	})
+
+	return b
+}
+
+func newPasskeyAuth(t *testing.T, usrStorage *mockrepositories.MockUserRepository, passkeyStorage *mockrepositories.MockPasskeyRepository) *jwtauthservice.PasskeyAuth {
+	sessionStorage := newSessionStorage()
+	auth, err := jwtauthservice.NewJWTAuth(usrStorage, usrStorage, sessionStorage, sessionStorage, privateCert, publicCert, tokenConfig, newPhones(t), slog.Default())
+	if err != nil {
+		t.Fatalf("failed instantiate auth: %v", err)
+	}
+
+	svc, err := jwtauthservice.NewPasskeyAuth(auth, passkeyStorage, passkeyStorage, jwtauthservice.PasskeyConfig{
+		RPID:          "example.com",
+		RPDisplayName: "Example",
+		RPOrigins:     []string{passkeyOrigin},
+		CeremonyTTL:   time.Minute,
+		Secret:        "0123456789abcdef",
+	}, slog.Default())
+	if err != nil {
+		t.Fatalf("failed instantiate passkey: %v", err)
+	}
+
+	return svc
+}
+
+func TestPasskeyAuth_Ceremonies(t *testing.T) {
+	type scenario struct {
+		name string
+		// tamper changes the authenticator or the ceremony before login
+		tamper func(a *softAuthenticator, c *authservice.PasskeyCeremony, registration authservice.PasskeyCeremony)
+		// replay sends the login of a successful one again
+		replay  bool
+		errCode commonerr.Code
+	}
+
+	scenarios := []scenario{
+		{
+			name: "[OK] Login",
+		},
+		{
+			name: "[Failed] Other origin",
+			tamper: func(a *softAuthenticator, c *authservice.PasskeyCeremony, _ authservice.PasskeyCeremony) {
+				a.origin = "https://evil.example"
+			},
+			errCode: authservice.ErrPasskeyInvalid.Code,
+		},
+		{
+			name: "[Failed] Cloned authenticator",
+			tamper: func(a *softAuthenticator, c *authservice.PasskeyCeremony, _ authservice.PasskeyCeremony) {
+				a.counter = 0
+			},
+			errCode: authservice.ErrPasskeyInvalid.Code,
+		},
+		{
+			name: "[Failed] Registration session",
+			tamper: func(a *softAuthenticator, c *authservice.PasskeyCeremony, registration authservice.PasskeyCeremony) {
+				c.Session = registration.Session
+			},
+			errCode: authservice.ErrPasskeyInvalid.Code,
+		},
+		{
+			name:    "[Failed] Replayed login",
+			replay:  true,
+			errCode: authservice.ErrPasskeyInvalid.Code,
+		},
+		{
+			name: "[Failed] Other key",
+			tamper: func(a *softAuthenticator, c *authservice.PasskeyCeremony, _ authservice.PasskeyCeremony) {
+				a.key, _ = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+			},
+			errCode: authservice.ErrPasskeyInvalid.Code,
+		},
+	}
+
+	for _, scn := range scenarios {
+		t.Run(scn.name, func(t *testing.T) {
+			ctx := context.Background()
+			usr := &repositories.User{ID: 7, FullName: "Admin", Phone: "+6281234567890"}
+
+			usrStorage := &mockrepositories.MockUserRepository{}
+			usrStorage.On("FindUserByID", mock.Anything, int64(7)).Return(usr, nil)
+			usrStorage.On("CreateUserAttendance", mock.Anything, mock.AnythingOfType("*repositories.UserAttendance")).Return(nil)
+			usrStorage.On("SaveUserAttendanceSummary", mock.Anything, int64(7)).Return(nil)
+
+			var registered *repositories.Passkey
+			passkeyStorage := &mockrepositories.MockPasskeyRepository{}
+			passkeyStorage.On("FindPasskeysByUser", mock.Anything, int64(7)).Return([]*repositories.Passkey{}, nil).Times(2)
+			passkeyStorage.On("CreatePasskey", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+				registered = args.Get(1).(*repositories.Passkey)
+			}).Return(int64(1), nil)
+
+			// challenges of registration & the first login are new, any other is a replay
+			passkeyStorage.On("ConsumePasskeyChallenge", mock.Anything, mock.Anything, mock.Anything).Return(true, nil).Times(2)
+			passkeyStorage.On("ConsumePasskeyChallenge", mock.Anything, mock.Anything, mock.Anything).Return(false, nil)
+
+			svc := newPasskeyAuth(t, usrStorage, passkeyStorage)
+			authenticator := newSoftAuthenticator(t)
+
+			registration, err := svc.BeginPasskeyRegistration(ctx, 7)
+			if err != nil {
+				t.Fatalf("failed begin registration: %v", err)
+			}
+
+			pk, err := svc.FinishPasskeyRegistration(ctx, 7, authservice.PasskeyRegisterRequest{
+				Session:    registration.Session,
+				Name:       "YubiKey",
+				Credential: authenticator.create(t, registration),
+			})
+			if err != nil {
+				t.Fatalf("failed finish registration: %v", err)
+			}
+
+			if pk.ID != 1 || pk.Name != "YubiKey" || string(registered.CredentialID) != string(authenticator.credentialID) {
+				t.Fatalf("unexpected passkey: %+v", pk)
+			}
+
+			registered.ID = pk.ID
+			passkeyStorage.On("FindPasskeysByUser", mock.Anything, int64(7)).Return([]*repositories.Passkey{registered}, nil)
+			passkeyStorage.On("UpdatePasskeyUsage", mock.Anything, int64(1), uint32(1), false, mock.Anything).Return(nil)
+
+			login, err := svc.BeginPasskeyLogin(ctx)
+			if err != nil {
+				t.Fatalf("failed begin login: %v", err)
+			}
+
+			if scn.tamper != nil {
+				// a login went through already, so the counter is known
+				registered.SignCount = 1
+				authenticator.counter = 1
+				scn.tamper(authenticator, &login, registration)
+			}
+
+			req := authservice.PasskeyLoginRequest{
+				Session:    login.Session,
+				Credential: authenticator.get(t, login),
+			}
+
+			lr, err := svc.FinishPasskeyLogin(ctx, req)
+			if scn.replay {
+				if err != nil {
+					t.Fatalf("failed first login: %v", err)
+				}
+
+				// the stored counter stays 0 like of a synced passkey, so it does not stop the replay
+				lr, err = svc.FinishPasskeyLogin(ctx, req)
+			}
+
+			if scn.errCode == 0 {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+
+				if lr.ID != 7 || lr.Token == "" {
+					t.Errorf("unexpected response: %+v", lr)
+				}
+
+				passkeyStorage.AssertCalled(t, "UpdatePasskeyUsage", mock.Anything, int64(1), uint32(1), false, mock.Anything)
+				return
+			}
+
+			e, ok := err.(*commonerr.Error)
+			if !ok {
+				t.Fatalf("expected *commonerr.Error, got %v", err)
+			}
+
+			if e.Code != scn.errCode {
+				t.Errorf("error code mismatch: want %d, got %d", scn.errCode, e.Code)
+			}
+
+			// only the first login of a replay goes through
+			if scn.replay {
+				passkeyStorage.AssertNumberOfCalls(t, "UpdatePasskeyUsage", 1)
+				return
+			}
+
+			passkeyStorage.AssertNotCalled(t, "UpdatePasskeyUsage", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestPasskeyAuth_FinishPasskeyRegistration_OtherUser(t *testing.T) {
+	ctx := context.Background()
+
+	usrStorage := &mockrepositories.MockUserRepository{}
+	usrStorage.On("FindUserByID", mock.Anything, int64(7)).Return(&repositories.User{ID: 7, FullName: "Admin"}, nil)
+	usrStorage.On("FindUserByID", mock.Anything, int64(8)).Return(&repositories.User{ID: 8, FullName: "Other"}, nil)
+
+	passkeyStorage := &mockrepositories.MockPasskeyRepository{}
+	passkeyStorage.On("FindPasskeysByUser", mock.Anything, mock.Anything).Return([]*repositories.Passkey{}, nil)
+
+	svc := newPasskeyAuth(t, usrStorage, passkeyStorage)
+
+	registration, err := svc.BeginPasskeyRegistration(ctx, 7)
+	if err != nil {
+		t.Fatalf("failed begin registration: %v", err)
+	}
+
+	// session of user 7 must not register passkey on user 8
+	_, err = svc.FinishPasskeyRegistration(ctx, 8, authservice.PasskeyRegisterRequest{
+		Session:    registration.Session,
+		Credential: newSoftAuthenticator(t).create(t, registration),
+	})
+	if !authservice.ErrPasskeyInvalid.Is(err) {
+		t.Errorf("expected invalid passkey, got %v", err)
+	}
+
+	passkeyStorage.AssertNotCalled(t, "CreatePasskey", mock.Anything, mock.Anything)
+}
@@ -0,0 +1,37 @@
+package authservice
+
+import "context"
+
+// OTPStartRequest identifies the user by either phone or verified email,
+// the code is sent by SMS or email respectively.
+type OTPStartRequest struct {
+	Phone string `json:"phone" validate:"omitempty,max=20"`
+	Email string `json:"email" validate:"omitempty,max=255"`
+	// IP of the client, for rate limiting
+	IP string `json:"-"`
+}
+
+type OTPStartResponse struct {
+	ChallengeID string `json:"challenge_id"`
+	// ExpiresIn is lifetime of the code in seconds
+	ExpiresIn int `json:"expires_in"`
+}
+
+// OTPCompleteRequest has either challenge ID & code, or token of the magic link
+type OTPCompleteRequest struct {
+	ChallengeID string `json:"challenge_id" validate:"omitempty,max=64"`
+	Code        string `json:"code" validate:"omitempty,max=16"`
+	Token       string `json:"token" validate:"omitempty,max=512"`
+	DeviceName  string `json:"device_name" validate:"omitempty,max=64"`
+	// UserAgent & IP of the client, recorded on the session, IP is for rate limiting as well
+	UserAgent string `json:"-"`
+	IP        string `json:"-"`
+}
+
+// Passwordless is login by one-time code, as alternative of Authn
+type Passwordless interface {
+	// StartOTP sends the code. The response does not tell whether the user exists.
+	StartOTP(ctx context.Context, params OTPStartRequest) (OTPStartResponse, error)
+	// CompleteOTP exchanges the code for the same token as `Authn.Login`
+	CompleteOTP(ctx context.Context, params OTPCompleteRequest) (LoginResponse, error)
+}
@@ -0,0 +1,60 @@
+package authservice
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// PasskeyCeremony is challenge of WebAuthn ceremony. Options are passed to
+// `navigator.credentials.create` or `navigator.credentials.get`, Session is sent back
+// along with the resulting credential.
+type PasskeyCeremony struct {
+	Session string `json:"session"`
+	Options any    `json:"options"`
+}
+
+type PasskeyRegisterRequest struct {
+	Session string `json:"session" validate:"required,max=4096"`
+	Name    string `json:"name" validate:"omitempty,max=64"`
+	// Credential is PublicKeyCredential JSON as returned by the browser
+	Credential json.RawMessage `json:"credential" validate:"required"`
+}
+
+type PasskeyRenameRequest struct {
+	Name string `json:"name" validate:"required,max=64"`
+}
+
+type PasskeyLoginRequest struct {
+	Session    string          `json:"session" validate:"required,max=4096"`
+	Credential json.RawMessage `json:"credential" validate:"required"`
+	DeviceName string          `json:"device_name" validate:"omitempty,max=64"`
+	// UserAgent & IP of the client, recorded on the session
+	UserAgent string `json:"-"`
+	IP        string `json:"-"`
+}
+
+type Passkey struct {
+	ID         int64      `json:"id"`
+	Name       string     `json:"name"`
+	Synced     bool       `json:"synced"`
+	CreatedAt  time.Time  `json:"created_at"`
+	LastUsedAt *time.Time `json:"last_used_at"`
+}
+
+// PasskeyRegistrar manages passkeys of the logged in user
+type PasskeyRegistrar interface {
+	BeginPasskeyRegistration(ctx context.Context, userID int64) (PasskeyCeremony, error)
+	FinishPasskeyRegistration(ctx context.Context, userID int64, params PasskeyRegisterRequest) (Passkey, error)
+	ListPasskeys(ctx context.Context, userID int64) ([]Passkey, error)
+	RenamePasskey(ctx context.Context, userID, id int64, params PasskeyRenameRequest) error
+	DeletePasskey(ctx context.Context, userID, id int64) error
+}
+
+// PasskeyAuthn is login by passkey, as alternative of Authn
+type PasskeyAuthn interface {
+	// BeginPasskeyLogin does not need the user, the authenticator tells which passkey is used
+	BeginPasskeyLogin(ctx context.Context) (PasskeyCeremony, error)
+	// FinishPasskeyLogin exchanges the assertion for the same token as `Authn.Login`
+	FinishPasskeyLogin(ctx context.Context, params PasskeyLoginRequest) (LoginResponse, error)
+}
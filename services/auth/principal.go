@@ -0,0 +1,82 @@
+package authservice
+
+import (
+	"slices"
+	"time"
+)
+
+// Subject types of Principal, telling which credential the request is authenticated by
+const (
+	SubjectUser   = "user"
+	SubjectAPIKey = "api_key"
+)
+
+// Roles of Principal, every user has RoleUser until other roles are introduced
+const (
+	RoleUser = "user"
+)
+
+// Authentication methods of Principal, as `amr` claim of RFC 8176
+const (
+	AMRPassword = "pwd"
+	AMROTP      = "otp"
+	AMRHardware = "hwk"
+	// AMRMultiFactor is with passkey, which verifies the user on top of possession of the key
+	AMRMultiFactor = "mfa"
+)
+
+// Principal is who makes the request & what they may do
+type Principal struct {
+	SubjectType string
+	UserID      int64
+	Roles       []string
+	// Scopes limit API key, they are nil for login token which is not limited
+	Scopes []string
+	// SessionID is only of login token
+	SessionID string
+	// AuthTime is when the user logged in, or the API key is created
+	AuthTime time.Time
+	// AuthMethods are how the user logged in, of login token only
+	AuthMethods []string
+}
+
+// Allows reports whether principal may access a route guarded by one of scopes.
+// Login token is allowed anything, API key only what its scopes grant, so no scopes
+// means login token only.
+func (p Principal) Allows(scopes ...string) bool {
+	if p.SubjectType != SubjectAPIKey {
+		return true
+	}
+
+	for _, scope := range scopes {
+		if slices.Contains(p.Scopes, scope) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// AuthenticatedWithin reports whether the user logged in no longer than maxAge before now, by one of
+// methods when given. API key never passes, it is not a login the user may be prompted to repeat.
+func (p Principal) AuthenticatedWithin(now time.Time, maxAge time.Duration, methods ...string) bool {
+	if p.SubjectType == SubjectAPIKey {
+		return false
+	}
+
+	if p.AuthTime.IsZero() || now.Sub(p.AuthTime) > maxAge {
+		return false
+	}
+
+	if len(methods) == 0 {
+		return true
+	}
+
+	for _, m := range methods {
+		if slices.Contains(p.AuthMethods, m) {
+			return true
+		}
+	}
+
+	return false
+}
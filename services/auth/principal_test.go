@@ -0,0 +1,67 @@
+package authservice_test
+
+import (
+	"testing"
+	"time"
+
+	authservice "waizlytest/services/auth"
+)
+
+func TestPrincipal_AuthenticatedWithin(t *testing.T) {
+	now := time.Now()
+
+	scenarios := []struct {
+		name      string
+		principal authservice.Principal
+		methods   []string
+		expected  bool
+	}{
+		{
+			name:      "[OK] Recent login",
+			principal: authservice.Principal{SubjectType: authservice.SubjectUser, AuthTime: now.Add(-time.Minute), AuthMethods: []string{authservice.AMRPassword}},
+			expected:  true,
+		},
+		{
+			name:      "[OK] Recent login by required method",
+			principal: authservice.Principal{SubjectType: authservice.SubjectUser, AuthTime: now.Add(-time.Minute), AuthMethods: []string{authservice.AMRHardware, authservice.AMRMultiFactor}},
+			methods:   []string{authservice.AMRMultiFactor},
+			expected:  true,
+		},
+		{
+			name:      "[Failed] Old login",
+			principal: authservice.Principal{SubjectType: authservice.SubjectUser, AuthTime: now.Add(-10 * time.Minute), AuthMethods: []string{authservice.AMRPassword}},
+		},
+		{
+			name:      "[Failed] Recent login by other method",
+			principal: authservice.Principal{SubjectType: authservice.SubjectUser, AuthTime: now.Add(-time.Minute), AuthMethods: []string{authservice.AMRPassword}},
+			methods:   []string{authservice.AMRMultiFactor},
+		},
+		{
+			name:      "[Failed] Token without amr",
+			principal: authservice.Principal{SubjectType: authservice.SubjectUser, AuthTime: now.Add(-time.Minute)},
+			methods:   []string{authservice.AMROTP},
+		},
+		{
+			name:      "[Failed] Old API key",
+			principal: authservice.Principal{SubjectType: authservice.SubjectAPIKey, AuthTime: now.Add(-24 * time.Hour)},
+			methods:   []string{authservice.AMRMultiFactor},
+		},
+		{
+			name:      "[Failed] Fresh API key",
+			principal: authservice.Principal{SubjectType: authservice.SubjectAPIKey, AuthTime: now.Add(-time.Second)},
+		},
+		{
+			name:      "[Failed] No auth time",
+			principal: authservice.Principal{SubjectType: authservice.SubjectUser},
+		},
+	}
+
+	for _, scn := range scenarios {
+		t.Run(scn.name, func(t *testing.T) {
+			got := scn.principal.AuthenticatedWithin(now, 5*time.Minute, scn.methods...)
+			if got != scn.expected {
+				t.Errorf("expected %v, got %v", scn.expected, got)
+			}
+		})
+	}
+}
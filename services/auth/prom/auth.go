@@ -0,0 +1,202 @@
+package promauthservice
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	commonerr "waizlytest/common/errors"
+
+	authservice "waizlytest/services/auth"
+)
+
+var _ (authservice.Authn) = (*Authn)(nil)
+var _ (authservice.Authz) = (*Authz)(nil)
+var _ (authservice.APIKeyAuthz) = (*APIKeyAuthz)(nil)
+var _ (authservice.Passwordless) = (*Passwordless)(nil)
+var _ (authservice.PasskeyAuthn) = (*PasskeyAuthn)(nil)
+
+// Authn decorates `authservice.Authn` with login counters.
+type Authn struct {
+	next authservice.Authn
+
+	logins *prometheus.CounterVec
+}
+
+func NewAuthn(next authservice.Authn, reg prometheus.Registerer) *Authn {
+	a := &Authn{
+		next: next,
+		logins: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "auth_login_total",
+			Help: "Login attempts by result and failure reason.",
+		}, []string{"result", "reason"}),
+	}
+
+	reg.MustRegister(a.logins)
+	return a
+}
+
+func (a *Authn) Login(ctx context.Context, params authservice.LoginRequest) (authservice.LoginResponse, error) {
+	lr, err := a.next.Login(ctx, params)
+	if err != nil {
+		a.logins.WithLabelValues("failure", reason(err)).Inc()
+		return lr, err
+	}
+
+	a.logins.WithLabelValues("success", "").Inc()
+	return lr, nil
+}
+
+// Passwordless decorates `authservice.Passwordless` with counters of sent & exchanged codes.
+type Passwordless struct {
+	next authservice.Passwordless
+
+	otps *prometheus.CounterVec
+}
+
+func NewPasswordless(next authservice.Passwordless, reg prometheus.Registerer) *Passwordless {
+	a := &Passwordless{
+		next: next,
+		otps: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "auth_otp_total",
+			Help: "Passwordless login steps by step, result and failure reason.",
+		}, []string{"step", "result", "reason"}),
+	}
+
+	reg.MustRegister(a.otps)
+	return a
+}
+
+func (a *Passwordless) StartOTP(ctx context.Context, params authservice.OTPStartRequest) (authservice.OTPStartResponse, error) {
+	resp, err := a.next.StartOTP(ctx, params)
+	a.observe("start", err)
+	return resp, err
+}
+
+func (a *Passwordless) CompleteOTP(ctx context.Context, params authservice.OTPCompleteRequest) (authservice.LoginResponse, error) {
+	lr, err := a.next.CompleteOTP(ctx, params)
+	a.observe("complete", err)
+	return lr, err
+}
+
+func (a *Passwordless) observe(step string, err error) {
+	if err != nil {
+		a.otps.WithLabelValues(step, "failure", reason(err)).Inc()
+		return
+	}
+
+	a.otps.WithLabelValues(step, "success", "").Inc()
+}
+
+// PasskeyAuthn decorates `authservice.PasskeyAuthn` with counters of passkey login steps.
+type PasskeyAuthn struct {
+	next authservice.PasskeyAuthn
+
+	steps *prometheus.CounterVec
+}
+
+func NewPasskeyAuthn(next authservice.PasskeyAuthn, reg prometheus.Registerer) *PasskeyAuthn {
+	a := &PasskeyAuthn{
+		next: next,
+		steps: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "auth_passkey_login_total",
+			Help: "Passkey login steps by step, result and failure reason.",
+		}, []string{"step", "result", "reason"}),
+	}
+
+	reg.MustRegister(a.steps)
+	return a
+}
+
+func (a *PasskeyAuthn) BeginPasskeyLogin(ctx context.Context) (authservice.PasskeyCeremony, error) {
+	resp, err := a.next.BeginPasskeyLogin(ctx)
+	a.observe("begin", err)
+	return resp, err
+}
+
+func (a *PasskeyAuthn) FinishPasskeyLogin(ctx context.Context, params authservice.PasskeyLoginRequest) (authservice.LoginResponse, error) {
+	lr, err := a.next.FinishPasskeyLogin(ctx, params)
+	a.observe("finish", err)
+	return lr, err
+}
+
+func (a *PasskeyAuthn) observe(step string, err error) {
+	if err != nil {
+		a.steps.WithLabelValues(step, "failure", reason(err)).Inc()
+		return
+	}
+
+	a.steps.WithLabelValues(step, "success", "").Inc()
+}
+
+// Authz decorates `authservice.Authz` with token validation failure counter.
+type Authz struct {
+	next authservice.Authz
+
+	failures *prometheus.CounterVec
+}
+
+func NewAuthz(next authservice.Authz, reg prometheus.Registerer) *Authz {
+	a := &Authz{
+		next: next,
+		failures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "auth_token_validation_failures_total",
+			Help: "Failed access token validations by reason.",
+		}, []string{"reason"}),
+	}
+
+	reg.MustRegister(a.failures)
+	return a
+}
+
+func (a *Authz) ValidateToken(ctx context.Context, token string) (authservice.Principal, error) {
+	p, err := a.next.ValidateToken(ctx, token)
+	if err != nil {
+		a.failures.WithLabelValues(reason(err)).Inc()
+		return p, err
+	}
+
+	return p, nil
+}
+
+// APIKeyAuthz decorates `authservice.APIKeyAuthz` with API key validation failure counter.
+type APIKeyAuthz struct {
+	next authservice.APIKeyAuthz
+
+	failures *prometheus.CounterVec
+}
+
+func NewAPIKeyAuthz(next authservice.APIKeyAuthz, reg prometheus.Registerer) *APIKeyAuthz {
+	a := &APIKeyAuthz{
+		next: next,
+		failures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "auth_api_key_validation_failures_total",
+			Help: "Failed API key validations by reason.",
+		}, []string{"reason"}),
+	}
+
+	reg.MustRegister(a.failures)
+	return a
+}
+
+func (a *APIKeyAuthz) ValidateAPIKey(ctx context.Context, key string) (authservice.Principal, error) {
+	p, err := a.next.ValidateAPIKey(ctx, key)
+	if err != nil {
+		a.failures.WithLabelValues(reason(err)).Inc()
+		return p, err
+	}
+
+	return p, nil
+}
+
+// reason keeps label cardinality bounded: business errors are labelled by their type & code,
+// everything else is lumped together.
+func reason(err error) string {
+	e, ok := err.(*commonerr.Error)
+	if !ok {
+		return "unknown"
+	}
+
+	return fmt.Sprintf("%s/%d", e.Type, e.Code)
+}
@@ -0,0 +1,220 @@
+package promauthservice_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/prometheus/client_golang/prometheus"
+
+	authservice "waizlytest/services/auth"
+	promauthservice "waizlytest/services/auth/prom"
+)
+
+// next answers every call of every auth service with err
+type next struct {
+	err error
+}
+
+func (n next) Login(ctx context.Context, params authservice.LoginRequest) (authservice.LoginResponse, error) {
+	return authservice.LoginResponse{}, n.err
+}
+
+func (n next) StartOTP(ctx context.Context, params authservice.OTPStartRequest) (authservice.OTPStartResponse, error) {
+	return authservice.OTPStartResponse{}, n.err
+}
+
+func (n next) CompleteOTP(ctx context.Context, params authservice.OTPCompleteRequest) (authservice.LoginResponse, error) {
+	return authservice.LoginResponse{}, n.err
+}
+
+func (n next) BeginPasskeyLogin(ctx context.Context) (authservice.PasskeyCeremony, error) {
+	return authservice.PasskeyCeremony{}, n.err
+}
+
+func (n next) FinishPasskeyLogin(ctx context.Context, params authservice.PasskeyLoginRequest) (authservice.LoginResponse, error) {
+	return authservice.LoginResponse{}, n.err
+}
+
+func (n next) ValidateToken(ctx context.Context, token string) (authservice.Principal, error) {
+	return authservice.Principal{}, n.err
+}
+
+func (n next) ValidateAPIKey(ctx context.Context, key string) (authservice.Principal, error) {
+	return authservice.Principal{}, n.err
+}
+
+// gather returns counters of the metric keyed by its label values joined by "|"
+func gather(t *testing.T, reg *prometheus.Registry, name string) map[string]float64 {
+	t.Helper()
+
+	families, err := reg.Gather()
+	if err != nil {
+		t.Fatalf("failed gather: %v", err)
+	}
+
+	got := map[string]float64{}
+	for _, f := range families {
+		if f.GetName() != name {
+			continue
+		}
+
+		for _, m := range f.GetMetric() {
+			var values []string
+			for _, l := range m.GetLabel() {
+				values = append(values, l.GetValue())
+			}
+
+			got[strings.Join(values, "|")] = m.GetCounter().GetValue()
+		}
+	}
+
+	return got
+}
+
+func TestDecorators(t *testing.T) {
+	wrongCredential := authservice.ErrWrongCredential.New()
+	tokenExpired := authservice.ErrTokenExpired.New()
+	failure := errors.New("conn closed")
+
+	scenarios := []struct {
+		name   string
+		err    error
+		metric string
+		run    func(ctx context.Context, n next, reg *prometheus.Registry) error
+		// expected is keyed by label values in their sorted names order, joined by "|"
+		expected map[string]float64
+	}{
+		{
+			name:   "[OK] Login",
+			metric: "auth_login_total",
+			run: func(ctx context.Context, n next, reg *prometheus.Registry) error {
+				_, err := promauthservice.NewAuthn(n, reg).Login(ctx, authservice.LoginRequest{})
+				return err
+			},
+			expected: map[string]float64{"|success": 1},
+		},
+		{
+			name:   "[Failed] Login business error",
+			err:    wrongCredential,
+			metric: "auth_login_total",
+			run: func(ctx context.Context, n next, reg *prometheus.Registry) error {
+				_, err := promauthservice.NewAuthn(n, reg).Login(ctx, authservice.LoginRequest{})
+				return err
+			},
+			expected: map[string]float64{"BadRequestError/101|failure": 1},
+		},
+		{
+			name:   "[Failed] Login unknown error",
+			err:    failure,
+			metric: "auth_login_total",
+			run: func(ctx context.Context, n next, reg *prometheus.Registry) error {
+				_, err := promauthservice.NewAuthn(n, reg).Login(ctx, authservice.LoginRequest{})
+				return err
+			},
+			expected: map[string]float64{"unknown|failure": 1},
+		},
+		{
+			name:   "[OK] OTP steps",
+			metric: "auth_otp_total",
+			run: func(ctx context.Context, n next, reg *prometheus.Registry) error {
+				a := promauthservice.NewPasswordless(n, reg)
+				a.StartOTP(ctx, authservice.OTPStartRequest{})
+				_, err := a.CompleteOTP(ctx, authservice.OTPCompleteRequest{})
+				return err
+			},
+			expected: map[string]float64{"|success|start": 1, "|success|complete": 1},
+		},
+		{
+			name:   "[Failed] OTP steps",
+			err:    authservice.ErrOTPInvalid.New(),
+			metric: "auth_otp_total",
+			run: func(ctx context.Context, n next, reg *prometheus.Registry) error {
+				a := promauthservice.NewPasswordless(n, reg)
+				a.StartOTP(ctx, authservice.OTPStartRequest{})
+				_, err := a.CompleteOTP(ctx, authservice.OTPCompleteRequest{})
+				return err
+			},
+			expected: map[string]float64{"BadRequestError/111|failure|start": 1, "BadRequestError/111|failure|complete": 1},
+		},
+		{
+			name:   "[OK] Passkey steps",
+			metric: "auth_passkey_login_total",
+			run: func(ctx context.Context, n next, reg *prometheus.Registry) error {
+				a := promauthservice.NewPasskeyAuthn(n, reg)
+				a.BeginPasskeyLogin(ctx)
+				_, err := a.FinishPasskeyLogin(ctx, authservice.PasskeyLoginRequest{})
+				return err
+			},
+			expected: map[string]float64{"|success|begin": 1, "|success|finish": 1},
+		},
+		{
+			name:   "[Failed] Passkey steps",
+			err:    authservice.ErrPasskeyInvalid.New(),
+			metric: "auth_passkey_login_total",
+			run: func(ctx context.Context, n next, reg *prometheus.Registry) error {
+				a := promauthservice.NewPasskeyAuthn(n, reg)
+				a.BeginPasskeyLogin(ctx)
+				_, err := a.FinishPasskeyLogin(ctx, authservice.PasskeyLoginRequest{})
+				return err
+			},
+			expected: map[string]float64{"BadRequestError/112|failure|begin": 1, "BadRequestError/112|failure|finish": 1},
+		},
+		{
+			name:   "[OK] Valid token is not counted",
+			metric: "auth_token_validation_failures_total",
+			run: func(ctx context.Context, n next, reg *prometheus.Registry) error {
+				_, err := promauthservice.NewAuthz(n, reg).ValidateToken(ctx, "token")
+				return err
+			},
+			expected: map[string]float64{},
+		},
+		{
+			name:   "[Failed] Token",
+			err:    tokenExpired,
+			metric: "auth_token_validation_failures_total",
+			run: func(ctx context.Context, n next, reg *prometheus.Registry) error {
+				_, err := promauthservice.NewAuthz(n, reg).ValidateToken(ctx, "token")
+				return err
+			},
+			expected: map[string]float64{"AuthenticationError/811": 1},
+		},
+		{
+			name:   "[OK] Valid API key is not counted",
+			metric: "auth_api_key_validation_failures_total",
+			run: func(ctx context.Context, n next, reg *prometheus.Registry) error {
+				_, err := promauthservice.NewAPIKeyAuthz(n, reg).ValidateAPIKey(ctx, "key")
+				return err
+			},
+			expected: map[string]float64{},
+		},
+		{
+			name:   "[Failed] API key",
+			err:    failure,
+			metric: "auth_api_key_validation_failures_total",
+			run: func(ctx context.Context, n next, reg *prometheus.Registry) error {
+				_, err := promauthservice.NewAPIKeyAuthz(n, reg).ValidateAPIKey(ctx, "key")
+				return err
+			},
+			expected: map[string]float64{"unknown": 1},
+		},
+	}
+
+	for _, scn := range scenarios {
+		t.Run(scn.name, func(t *testing.T) {
+			reg := prometheus.NewRegistry()
+
+			err := scn.run(context.TODO(), next{err: scn.err}, reg)
+			if err != scn.err {
+				t.Errorf("expected error of next passed through, got %v", err)
+			}
+
+			diff := cmp.Diff(scn.expected, gather(t, reg, scn.metric))
+			if diff != "" {
+				t.Errorf("counters mismatch (-want +got):\n%s", diff)
+			}
+		})
+	}
+}
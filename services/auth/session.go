@@ -0,0 +1,32 @@
+package authservice
+
+import (
+	"context"
+	"time"
+)
+
+// Device is where the user logs in from, it is recorded on the session
+type Device struct {
+	Name      string
+	UserAgent string
+	IP        string
+}
+
+type Session struct {
+	ID         string    `json:"id"`
+	Method     string    `json:"method"`
+	DeviceName string    `json:"device_name"`
+	UserAgent  string    `json:"user_agent"`
+	IP         string    `json:"ip"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+}
+
+// Sessions lets the user see where they are logged in & log a device out
+type Sessions interface {
+	// ListSessions lists active sessions, the latest seen first
+	ListSessions(ctx context.Context, userID int64) ([]Session, error)
+	// RevokeSession logs the session out, its token is not valid anymore
+	RevokeSession(ctx context.Context, userID int64, id string) error
+}
@@ -0,0 +1,11 @@
+package userservice
+
+import "context"
+
+// EmailVerifier proves the user owns the email, by signed link sent to it
+type EmailVerifier interface {
+	// SendEmailVerification sends the link to unverified email of user id
+	SendEmailVerification(ctx context.Context, id int64) error
+	// VerifyEmail marks email of the link token as verified
+	VerifyEmail(ctx context.Context, token string) error
+}
@@ -0,0 +1,53 @@
+package userservice
+
+import (
+	commonerr "waizlytest/common/errors"
+)
+
+// Codes of user domain, 1xx are bad requests & conflicts
+const (
+	CodeInvalidVerificationLink commonerr.Code = 108
+	CodeNoEmailToVerify         commonerr.Code = 109
+	CodeEmailRegistered         commonerr.Code = 122
+)
+
+// Error definitions of user domain,
+// user not found & phone registered predate the catalog so they keep the shared bad request & conflicted codes.
+var (
+	ErrUserNotFound = commonerr.DefBadRequest.Variant(commonerr.Definition{
+		Domain:      "user",
+		Key:         "user.not_found",
+		Message:     "user not found",
+		Description: "User of the token no longer exists.",
+	})
+	ErrPhoneRegistered = commonerr.DefConflicted.Variant(commonerr.Definition{
+		Domain:      "user",
+		Key:         "user.phone_registered",
+		Message:     "Conflicted request",
+		Description: "Phone is already registered by another user, see field `phone`.",
+	})
+	ErrEmailRegistered = commonerr.Define(commonerr.Definition{
+		Domain:      "user",
+		Type:        commonerr.TypeConflictedError,
+		Code:        CodeEmailRegistered,
+		Key:         "user.email_registered",
+		Message:     "Conflicted request",
+		Description: "Email is already verified by another user, see field `email`.",
+	})
+	ErrInvalidVerificationLink = commonerr.Define(commonerr.Definition{
+		Domain:      "user",
+		Type:        commonerr.TypeBadRequestError,
+		Code:        CodeInvalidVerificationLink,
+		Key:         "user.verification_link_invalid",
+		Message:     "verification link is invalid or expired",
+		Description: "Email verification link is tampered, expired or its email is no longer the user's, request a new one.",
+	})
+	ErrNoEmailToVerify = commonerr.Define(commonerr.Definition{
+		Domain:      "user",
+		Type:        commonerr.TypeBadRequestError,
+		Code:        CodeNoEmailToVerify,
+		Key:         "user.no_email_to_verify",
+		Message:     "there is no email to verify",
+		Description: "User has no email, or it is already verified.",
+	})
+)
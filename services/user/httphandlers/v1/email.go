@@ -0,0 +1,64 @@
+package v1userhttphandler
+
+import (
+	"net/http"
+
+	commonerr "waizlytest/common/errors"
+
+	commonhttpenc "waizlytest/common/http/encoder"
+	commonhttpresp "waizlytest/common/http/response"
+
+	authservice "waizlytest/services/auth"
+	userservice "waizlytest/services/user"
+)
+
+type EmailVerificationHandler struct {
+	verifier userservice.EmailVerifier
+
+	enc *commonhttpenc.ErrorEncoder
+}
+
+func NewEmailVerificationHandler(verifier userservice.EmailVerifier, enc *commonhttpenc.ErrorEncoder) *EmailVerificationHandler {
+	return &EmailVerificationHandler{
+		verifier: verifier,
+		enc:      enc,
+	}
+}
+
+// Verify is target of the link sent to the email, token comes as `token` query.
+func (hn *EmailVerificationHandler) Verify() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		err := hn.verifier.VerifyEmail(ctx, r.URL.Query().Get("token"))
+		if err != nil {
+			hn.enc.Encode(ctx, w, err)
+			return
+		}
+
+		commonhttpenc.JSONResponseEncoder(ctx, w, http.StatusOK, commonhttpresp.NewResponse(nil, nil))
+	}
+}
+
+// Resend sends the link again to the logged in user.
+func (hn *EmailVerificationHandler) Resend() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+
+		// principal is set by the auth middleware
+		principal, ok := authservice.PrincipalFrom(ctx)
+		if !ok {
+			e := commonerr.ErrorForbidden
+			hn.enc.Encode(ctx, w, e)
+			return
+		}
+
+		err := hn.verifier.SendEmailVerification(ctx, principal.UserID)
+		if err != nil {
+			hn.enc.Encode(ctx, w, err)
+			return
+		}
+
+		commonhttpenc.JSONResponseEncoder(ctx, w, http.StatusAccepted, commonhttpresp.NewResponse(nil, nil))
+	}
+}
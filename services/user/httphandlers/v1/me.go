@@ -1,26 +1,28 @@
 package v1userhttphandler
 
 import (
-	"encoding/json"
-	"io"
 	"net/http"
 
-	"waizlytest/common/contextkey"
 	commonerr "waizlytest/common/errors"
 
+	commonhttpdec "waizlytest/common/http/decoder"
 	commonhttpenc "waizlytest/common/http/encoder"
 	commonhttpresp "waizlytest/common/http/response"
 
+	authservice "waizlytest/services/auth"
 	userservice "waizlytest/services/user"
 )
 
 type MeHandler struct {
 	me userservice.Me
+
+	enc *commonhttpenc.ErrorEncoder
 }
 
-func NewMeHandler(me userservice.Me) *MeHandler {
+func NewMeHandler(me userservice.Me, enc *commonhttpenc.ErrorEncoder) *MeHandler {
 	return &MeHandler{
-		me: me,
+		me:  me,
+		enc: enc,
 	}
 }
 
@@ -28,17 +30,17 @@ func (hn *MeHandler) GetProfile() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
 
-		// this cast is safe, as we controlled the auth middleware
-		id, ok := ctx.Value(contextkey.UserIDContextKey).(int64)
+		// principal is set by the auth middleware
+		principal, ok := authservice.PrincipalFrom(ctx)
 		if !ok {
 			e := commonerr.ErrorForbidden
-			commonhttpenc.JSONErrorEncoder(ctx, w, e)
+			hn.enc.Encode(ctx, w, e)
 			return
 		}
 
-		pr, err := hn.me.GetProfile(ctx, id)
+		pr, err := hn.me.GetProfile(ctx, principal.UserID)
 		if err != nil {
-			commonhttpenc.JSONErrorEncoder(ctx, w, err)
+			hn.enc.Encode(ctx, w, err)
 			return
 		}
 
@@ -50,30 +52,23 @@ func (hn *MeHandler) UpdateProfile() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
 
-		// this cast is safe, as we controlled the auth middleware
-		id, ok := ctx.Value(contextkey.UserIDContextKey).(int64)
+		// principal is set by the auth middleware
+		principal, ok := authservice.PrincipalFrom(ctx)
 		if !ok {
 			e := commonerr.ErrorForbidden
-			commonhttpenc.JSONErrorEncoder(ctx, w, e)
+			hn.enc.Encode(ctx, w, e)
 			return
 		}
 
-		var p *userservice.UpdateRequest
-		err := json.NewDecoder(r.Body).Decode(&p)
+		p, err := commonhttpdec.DecodeJSON[userservice.UpdateRequest](r)
 		if err != nil {
-			if err == io.EOF {
-				e := commonerr.New(commonerr.TypeBadRequestError, commonerr.Code101, "request can not be empty")
-				commonhttpenc.JSONErrorEncoder(ctx, w, e)
-				return
-			}
-
-			commonhttpenc.JSONErrorEncoder(ctx, w, err)
+			hn.enc.Encode(ctx, w, err)
 			return
 		}
 
-		err = hn.me.UpdateProfile(ctx, id, *p)
+		err = hn.me.UpdateProfile(ctx, principal.UserID, p)
 		if err != nil {
-			commonhttpenc.JSONErrorEncoder(ctx, w, err)
+			hn.enc.Encode(ctx, w, err)
 			return
 		}
 
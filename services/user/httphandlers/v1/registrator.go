@@ -1,12 +1,9 @@
 package v1userhttphandler
 
 import (
-	"encoding/json"
-	"io"
 	"net/http"
 
-	commonerr "waizlytest/common/errors"
-
+	commonhttpdec "waizlytest/common/http/decoder"
 	commonhttpenc "waizlytest/common/http/encoder"
 	commonhttpresp "waizlytest/common/http/response"
 
@@ -15,11 +12,14 @@ import (
 
 type RegistratorHandler struct {
 	registrator userservice.Registrator
+
+	enc *commonhttpenc.ErrorEncoder
 }
 
-func NewRegistratorHandler(registrator userservice.Registrator) *RegistratorHandler {
+func NewRegistratorHandler(registrator userservice.Registrator, enc *commonhttpenc.ErrorEncoder) *RegistratorHandler {
 	return &RegistratorHandler{
 		registrator: registrator,
+		enc:         enc,
 	}
 }
 
@@ -27,22 +27,15 @@ func (hn *RegistratorHandler) Register() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		ctx := r.Context()
 
-		var p *userservice.RegisterRequest
-		err := json.NewDecoder(r.Body).Decode(&p)
+		p, err := commonhttpdec.DecodeJSON[userservice.RegisterRequest](r)
 		if err != nil {
-			if err == io.EOF {
-				e := commonerr.New(commonerr.TypeBadRequestError, commonerr.Code101, "request can not be empty")
-				commonhttpenc.JSONErrorEncoder(ctx, w, e)
-				return
-			}
-
-			commonhttpenc.JSONErrorEncoder(ctx, w, err)
+			hn.enc.Encode(ctx, w, err)
 			return
 		}
 
-		_, err = hn.registrator.Register(ctx, *p)
+		_, err = hn.registrator.Register(ctx, p)
 		if err != nil {
-			commonhttpenc.JSONErrorEncoder(ctx, w, err)
+			hn.enc.Encode(ctx, w, err)
 			return
 		}
 
@@ -2,14 +2,19 @@ package userservice
 
 import "context"
 
+// UpdateRequest only updates fields present on the request
 type UpdateRequest struct {
-	Phone    *string `json:"phone"`
-	FullName *string `json:"fullname"`
+	Phone    *string `json:"phone" validate:"phone"`
+	FullName *string `json:"fullname" validate:"notblank,max=255"`
+	// Email changes are unverified until the link sent to the new email is opened
+	Email *string `json:"email" validate:"email,max=255"`
 }
 
 type Profile struct {
-	FullName string `json:"fullname"`
-	Phone    string `json:"phone"`
+	FullName      string `json:"fullname"`
+	Phone         string `json:"phone"`
+	Email         string `json:"email,omitempty"`
+	EmailVerified bool   `json:"email_verified"`
 }
 
 type Me interface {
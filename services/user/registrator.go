@@ -2,10 +2,13 @@ package userservice
 
 import "context"
 
+// RegisterRequest needs either phone or email, email must be verified before used to login
 type RegisterRequest struct {
-	FullName string `json:"fullname"`
-	Password string `json:"password"`
-	Phone    string `json:"phone"`
+	FullName string `json:"fullname" validate:"required,max=255"`
+	// bcrypt only uses the first 72 bytes
+	Password string `json:"password" validate:"required,min=6,max=72"`
+	Phone    string `json:"phone" validate:"omitempty,phone"`
+	Email    string `json:"email" validate:"omitempty,email,max=255"`
 }
 
 type Registrator interface {
@@ -1,6 +1,13 @@
 package stduserservice
 
-import "golang.org/x/crypto/bcrypt"
+import (
+	"fmt"
+
+	"golang.org/x/crypto/bcrypt"
+
+	commonerr "waizlytest/common/errors"
+	userservice "waizlytest/services/user"
+)
 
 func hashed(password string) (string, error) {
 	hashed, err := bcrypt.GenerateFromPassword([]byte(password), 6)
@@ -10,3 +17,34 @@ func hashed(password string) (string, error) {
 
 	return string(hashed), nil
 }
+
+func errInvalidPhone() error {
+	e := commonerr.DefInvalidRequest.New()
+	e.AddTranslatableField("phone", "must be a valid phone number", "validation.phone")
+	return e
+}
+
+func errPhoneRegistered(phone string) error {
+	e := userservice.ErrPhoneRegistered.New()
+	e.AddTranslatableField("phone", fmt.Sprintf("[%s] already registered", phone), "user.phone_registered.field", phone)
+	return e
+}
+
+func errInvalidEmail() error {
+	e := commonerr.DefInvalidRequest.New()
+	e.AddTranslatableField("email", "must be a valid email address", "validation.email")
+	return e
+}
+
+func errIdentifierRequired() error {
+	e := commonerr.DefInvalidRequest.New()
+	e.AddTranslatableField("phone", "phone or email is required", "validation.phone_or_email")
+	e.AddTranslatableField("email", "phone or email is required", "validation.phone_or_email")
+	return e
+}
+
+func errEmailRegistered(email string) error {
+	e := userservice.ErrEmailRegistered.New()
+	e.AddTranslatableField("email", fmt.Sprintf("[%s] already registered", email), "user.email_registered.field", email)
+	return e
+}
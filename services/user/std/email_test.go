@@ -0,0 +1,88 @@
+package stduserservice_test
+
+import (
+	"context"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/mock"
+
+	commonmail "waizlytest/common/mail"
+	commonsignedlink "waizlytest/common/signedlink"
+
+	"waizlytest/repositories"
+	mockrepositories "waizlytest/repositories/mock"
+
+	userservice "waizlytest/services/user"
+	stduserservice "waizlytest/services/user/std"
+)
+
+func TestEmailVerification(t *testing.T) {
+	ctx := context.TODO()
+	dir := t.TempDir()
+
+	mailer, err := commonmail.NewFileMailer(dir, "no-reply@waizly.com")
+	if err != nil {
+		t.Fatalf("failed instantiate mailer: %v", err)
+	}
+
+	signer, err := commonsignedlink.New("0123456789abcdef", time.Hour)
+	if err != nil {
+		t.Fatalf("failed instantiate signer: %v", err)
+	}
+
+	usrStorage := &mockrepositories.MockUserRepository{}
+	usrStorage.On("FindUserByEmail", mock.Anything, "teest@waizly.com").Return((*repositories.User)(nil), nil)
+	usrStorage.On("CreateUser", mock.Anything, mock.Anything).Return(int64(1), nil)
+
+	svc := stduserservice.NewService(usrStorage, usrStorage, newPhones(t), stduserservice.EmailVerification{
+		Mailer: mailer,
+		Signer: signer,
+		URL:    "http://localhost/v1/email/verify",
+		TTL:    time.Hour,
+	}, slog.Default())
+
+	_, err = svc.Register(ctx, userservice.RegisterRequest{FullName: "Teest", Password: "123123", Email: " Teest@Waizly.com "})
+	if err != nil {
+		t.Fatalf("failed register: %v", err)
+	}
+
+	mails, _ := filepath.Glob(filepath.Join(dir, "*.eml"))
+	if len(mails) != 1 {
+		t.Fatalf("expected 1 mail, got %d", len(mails))
+	}
+
+	raw, _ := os.ReadFile(mails[0])
+	match := regexp.MustCompile(`token=([\w.-]+)`).FindSubmatch(raw)
+	if match == nil {
+		t.Fatalf("expected link on mail:\n%s", raw)
+	}
+
+	token := string(match[1])
+
+	usr := &repositories.User{ID: 1, FullName: "Teest", Email: "teest@waizly.com"}
+	usrStorage.On("FindUserByID", mock.Anything, int64(1)).Return(usr, nil)
+	usrStorage.On("UpdateUser", mock.Anything, mock.MatchedBy(func(u *repositories.User) bool {
+		return u.EmailVerifiedAt != nil
+	})).Return(nil).Once()
+
+	err = svc.VerifyEmail(ctx, token)
+	if err != nil {
+		t.Fatalf("failed verify: %v", err)
+	}
+
+	usrStorage.AssertExpectations(t)
+
+	// link dies once email is changed
+	usr.Email = "other@waizly.com"
+	usr.EmailVerifiedAt = nil
+
+	err = svc.VerifyEmail(ctx, token)
+	if !userservice.ErrInvalidVerificationLink.Is(err) {
+		t.Errorf("expected invalid link, got %v", err)
+	}
+}
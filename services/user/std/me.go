@@ -2,9 +2,11 @@ package stduserservice
 
 import (
 	"context"
-	"fmt"
+	"errors"
+	"log/slog"
 
-	commonerr "waizlytest/common/errors"
+	commonmail "waizlytest/common/mail"
+	"waizlytest/repositories"
 	userservice "waizlytest/services/user"
 )
 
@@ -17,46 +19,90 @@ func (s *StdService) GetProfile(ctx context.Context, id int64) (userservice.Prof
 	}
 
 	if usr == nil {
-		e := commonerr.BadRequest("user not found")
+		e := userservice.ErrUserNotFound.New()
 		return resp, e
 	}
 
 	resp.FullName = usr.FullName
 	resp.Phone = usr.Phone
+	resp.Email = usr.Email
+	resp.EmailVerified = usr.EmailVerifiedAt != nil
 	return resp, nil
 }
 
 func (s *StdService) UpdateProfile(ctx context.Context, id int64, params userservice.UpdateRequest) error {
+	// read-modify-write, stay on primary
+	ctx = repositories.WithPrimary(ctx)
+
 	usr, err := s.userReader.FindUserByID(ctx, id)
 	if err != nil {
 		return err
 	}
 
 	if usr == nil {
-		e := commonerr.BadRequest("user not found")
+		e := userservice.ErrUserNotFound.New()
 		return e
 	}
 
+	phoneChanged := false
 	if params.Phone != nil {
-		if usr.Phone != *params.Phone {
-			pUsr, err := s.userReader.FindUserByPhone(ctx, *params.Phone)
+		phone, err := s.phones.Normalize(*params.Phone)
+		if err != nil {
+			return errInvalidPhone()
+		}
+
+		if usr.Phone != phone {
+			phoneChanged = true
+
+			pUsr, err := s.userReader.FindUserByPhone(ctx, phone)
 			if err != nil {
 				return err
 			}
 
 			if pUsr != nil {
-				e := commonerr.Conflicted("")
-				e.AddField("phone", fmt.Sprintf("[%s] already registered", *params.Phone))
-				return e
+				return errPhoneRegistered(phone)
 			}
 		}
 
-		usr.Phone = *params.Phone
+		usr.Phone = phone
 	}
 
 	if params.FullName != nil && *params.FullName != "" {
 		usr.FullName = *params.FullName
 	}
 
-	return s.userWriter.UpdateUser(ctx, usr)
+	emailChanged := false
+	if params.Email != nil {
+		email, err := commonmail.NormalizeAddress(*params.Email)
+		if err != nil {
+			return errInvalidEmail()
+		}
+
+		if usr.Email != email {
+			usr.Email = email
+			usr.EmailVerifiedAt = nil
+			emailChanged = true
+		}
+	}
+
+	err = s.userWriter.UpdateUser(ctx, usr)
+	if err != nil {
+		// only a new phone may collide, verified emails are never taken over here
+		if phoneChanged && errors.Is(err, repositories.ErrDuplicate) {
+			return errPhoneRegistered(usr.Phone)
+		}
+
+		return err
+	}
+
+	s.logger.InfoContext(ctx, "profile updated", slog.Int64("user_id", id), slog.Bool("phone_changed", phoneChanged), slog.Bool("email_changed", emailChanged))
+
+	if emailChanged {
+		err = s.sendEmailVerification(ctx, usr)
+		if err != nil {
+			s.logger.ErrorContext(ctx, "failed send email verification", slog.Int64("user_id", id), slog.Any("error", err))
+		}
+	}
+
+	return nil
 }
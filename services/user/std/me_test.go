@@ -3,13 +3,14 @@ package stduserservice_test
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"testing"
 
 	"github.com/google/go-cmp/cmp"
 	"github.com/stretchr/testify/mock"
 	"golang.org/x/crypto/bcrypt"
 
-	commonerr "waizlytest/common/errors"
+	commonphone "waizlytest/common/phone"
 
 	"waizlytest/repositories"
 	mockrepositories "waizlytest/repositories/mock"
@@ -18,6 +19,31 @@ import (
 	stduserservice "waizlytest/services/user/std"
 )
 
+func newPhones(t *testing.T) *commonphone.Normalizer {
+	phones, err := commonphone.NewNormalizer("ID")
+	if err != nil {
+		t.Fatalf("failed instantiate phone normalizer: %v", err)
+	}
+
+	return phones
+}
+
+// duplicateError is unique violation as reported by the repositories, comparable by cmp
+type duplicateError struct {
+	Constraint string
+}
+
+func (e *duplicateError) Error() string { return "duplicate: " + e.Constraint }
+func (e *duplicateError) Unwrap() error { return repositories.ErrDuplicate }
+
+var errDuplicate error = &duplicateError{Constraint: "users_phone_active_key"}
+
+// ignoreTranslation skips translation arguments, they are covered by the encoder
+var ignoreTranslation = cmp.FilterPath(func(p cmp.Path) bool {
+	name := p.Last().String()
+	return name == ".Args" || name == ".FieldKeys"
+}, cmp.Ignore())
+
 func TestMe_GetProfile(t *testing.T) {
 	type scenario struct {
 		name     string
@@ -58,7 +84,7 @@ func TestMe_GetProfile(t *testing.T) {
 				}
 				usrStorage.On("FindUserByID", mock.Anything, mock.Anything).Return(usr, nil)
 
-				svc := stduserservice.NewService(usrStorage, usrStorage)
+				svc := stduserservice.NewService(usrStorage, usrStorage, newPhones(t), stduserservice.EmailVerification{}, slog.Default())
 
 				return svc
 			},
@@ -73,7 +99,7 @@ func TestMe_GetProfile(t *testing.T) {
 
 			expected: func() (userservice.Profile, error) {
 				prof := userservice.Profile{}
-				return prof, commonerr.BadRequest("user not found")
+				return prof, userservice.ErrUserNotFound.New()
 			},
 
 			service: func() *stduserservice.StdService {
@@ -83,7 +109,7 @@ func TestMe_GetProfile(t *testing.T) {
 				var usr *repositories.User
 				usrStorage.On("FindUserByID", mock.Anything, mock.Anything).Return(usr, nil)
 
-				svc := stduserservice.NewService(usrStorage, usrStorage)
+				svc := stduserservice.NewService(usrStorage, usrStorage, newPhones(t), stduserservice.EmailVerification{}, slog.Default())
 
 				return svc
 			},
@@ -97,14 +123,12 @@ func TestMe_GetProfile(t *testing.T) {
 			svc := scn.service()
 
 			resp, err := svc.GetProfile(scn.req())
-			if err != nil {
-				diff := cmp.Diff(expectedError.Error(), err.Error())
-				if diff != "" {
-					t.Errorf("err mismatch (-want +got):\n%s", diff)
-				}
+			diff := cmp.Diff(expectedError, err, ignoreTranslation)
+			if diff != "" {
+				t.Errorf("err mismatch (-want +got):\n%s", diff)
 			}
 
-			diff := cmp.Diff(expectedResponse, resp)
+			diff = cmp.Diff(expectedResponse, resp)
 			if diff != "" {
 				t.Errorf("resp mismatch (-want +got):\n%s", diff)
 			}
@@ -128,7 +152,7 @@ func TestMe_UpdateProfile(t *testing.T) {
 				ctx := context.TODO()
 				params := userservice.UpdateRequest{}
 
-				phone := "909090"
+				phone := "081298765432"
 				params.Phone = &phone
 
 				return ctx, 1, params
@@ -156,7 +180,7 @@ func TestMe_UpdateProfile(t *testing.T) {
 
 				usrStorage.On("UpdateUser", mock.Anything, mock.Anything).Return(nil)
 
-				svc := stduserservice.NewService(usrStorage, usrStorage)
+				svc := stduserservice.NewService(usrStorage, usrStorage, newPhones(t), stduserservice.EmailVerification{}, slog.Default())
 
 				return svc
 			},
@@ -192,7 +216,7 @@ func TestMe_UpdateProfile(t *testing.T) {
 
 				usrStorage.On("UpdateUser", mock.Anything, mock.Anything).Return(nil)
 
-				svc := stduserservice.NewService(usrStorage, usrStorage)
+				svc := stduserservice.NewService(usrStorage, usrStorage, newPhones(t), stduserservice.EmailVerification{}, slog.Default())
 
 				return svc
 			},
@@ -203,14 +227,14 @@ func TestMe_UpdateProfile(t *testing.T) {
 				ctx := context.TODO()
 				params := userservice.UpdateRequest{}
 
-				phone := "123123"
+				phone := "081211112222"
 				params.Phone = &phone
 
 				return ctx, 1, params
 			},
 
 			expected: func() error {
-				return commonerr.BadRequest("user not found")
+				return userservice.ErrUserNotFound.New()
 			},
 
 			service: func() *stduserservice.StdService {
@@ -220,7 +244,7 @@ func TestMe_UpdateProfile(t *testing.T) {
 				var emptyUsr *repositories.User
 				usrStorage.On("FindUserByID", mock.Anything, mock.Anything).Return(emptyUsr, nil)
 
-				svc := stduserservice.NewService(usrStorage, usrStorage)
+				svc := stduserservice.NewService(usrStorage, usrStorage, newPhones(t), stduserservice.EmailVerification{}, slog.Default())
 
 				return svc
 			},
@@ -231,15 +255,15 @@ func TestMe_UpdateProfile(t *testing.T) {
 				ctx := context.TODO()
 				params := userservice.UpdateRequest{}
 
-				phone := "123123"
+				phone := "081211112222"
 				params.Phone = &phone
 
 				return ctx, 1, params
 			},
 
 			expected: func() error {
-				e := commonerr.Conflicted("")
-				e.AddField("phone", fmt.Sprintf("[%s] already registered", "123123"))
+				e := userservice.ErrPhoneRegistered.New()
+				e.AddField("phone", fmt.Sprintf("[%s] already registered", "+6281211112222"))
 
 				return e
 			},
@@ -265,7 +289,122 @@ func TestMe_UpdateProfile(t *testing.T) {
 				}
 				usrStorage.On("FindUserByPhone", mock.Anything, mock.Anything).Return(usr2, nil)
 
-				svc := stduserservice.NewService(usrStorage, usrStorage)
+				svc := stduserservice.NewService(usrStorage, usrStorage, newPhones(t), stduserservice.EmailVerification{}, slog.Default())
+
+				return svc
+			},
+		},
+		{
+			name: "[OK] Success -- Phone normalized",
+			req: func() (context.Context, int64, userservice.UpdateRequest) {
+				ctx := context.TODO()
+				params := userservice.UpdateRequest{}
+
+				phone := "0812-9876-5432"
+				params.Phone = &phone
+
+				return ctx, 1, params
+			},
+
+			expected: func() error {
+				return nil
+			},
+
+			service: func() *stduserservice.StdService {
+
+				usrStorage := &mockrepositories.MockUserRepository{}
+
+				usr := &repositories.User{
+					ID:       1,
+					Phone:    "123456789",
+					FullName: "Teest",
+				}
+				usrStorage.On("FindUserByID", mock.Anything, mock.Anything).Return(usr, nil)
+
+				var emptyUsr *repositories.User
+				usrStorage.On("FindUserByPhone", mock.Anything, "+6281298765432").Return(emptyUsr, nil)
+
+				usrStorage.On("UpdateUser", mock.Anything, mock.MatchedBy(func(u *repositories.User) bool {
+					return u.Phone == "+6281298765432"
+				})).Return(nil)
+
+				svc := stduserservice.NewService(usrStorage, usrStorage, newPhones(t), stduserservice.EmailVerification{}, slog.Default())
+
+				return svc
+			},
+		},
+		{
+			name: "[Failed] Duplicate of changed phone",
+			req: func() (context.Context, int64, userservice.UpdateRequest) {
+				ctx := context.TODO()
+				params := userservice.UpdateRequest{}
+
+				phone := "+6281298765432"
+				params.Phone = &phone
+
+				return ctx, 1, params
+			},
+
+			expected: func() error {
+				e := userservice.ErrPhoneRegistered.New()
+				e.AddField("phone", fmt.Sprintf("[%s] already registered", "+6281298765432"))
+
+				return e
+			},
+
+			service: func() *stduserservice.StdService {
+
+				usrStorage := &mockrepositories.MockUserRepository{}
+
+				usr := &repositories.User{
+					ID:       1,
+					Phone:    "123456789",
+					FullName: "Teest",
+				}
+				usrStorage.On("FindUserByID", mock.Anything, mock.Anything).Return(usr, nil)
+
+				// registered by someone else between the check and the update
+				var emptyUsr *repositories.User
+				usrStorage.On("FindUserByPhone", mock.Anything, mock.Anything).Return(emptyUsr, nil)
+
+				usrStorage.On("UpdateUser", mock.Anything, mock.Anything).Return(errDuplicate)
+
+				svc := stduserservice.NewService(usrStorage, usrStorage, newPhones(t), stduserservice.EmailVerification{}, slog.Default())
+
+				return svc
+			},
+		},
+		{
+			name: "[Failed] Duplicate of unchanged phone",
+			req: func() (context.Context, int64, userservice.UpdateRequest) {
+				ctx := context.TODO()
+				params := userservice.UpdateRequest{}
+
+				phone := "081298765432"
+				name := "Adawd"
+				params.Phone, params.FullName = &phone, &name
+
+				return ctx, 1, params
+			},
+
+			expected: func() error {
+				return errDuplicate
+			},
+
+			service: func() *stduserservice.StdService {
+
+				usrStorage := &mockrepositories.MockUserRepository{}
+
+				usr := &repositories.User{
+					ID:       1,
+					Phone:    "+6281298765432",
+					FullName: "Teest",
+				}
+				usrStorage.On("FindUserByID", mock.Anything, mock.Anything).Return(usr, nil)
+
+				usrStorage.On("UpdateUser", mock.Anything, mock.Anything).Return(errDuplicate)
+
+				svc := stduserservice.NewService(usrStorage, usrStorage, newPhones(t), stduserservice.EmailVerification{}, slog.Default())
 
 				return svc
 			},
@@ -279,11 +418,9 @@ func TestMe_UpdateProfile(t *testing.T) {
 			svc := scn.service()
 
 			err := svc.UpdateProfile(scn.req())
-			if err != nil {
-				diff := cmp.Diff(expectedError.Error(), err.Error())
-				if diff != "" {
-					t.Errorf("err mismatch (-want +got):\n%s", diff)
-				}
+			diff := cmp.Diff(expectedError, err, ignoreTranslation)
+			if diff != "" {
+				t.Errorf("err mismatch (-want +got):\n%s", diff)
 			}
 		})
 	}
@@ -2,25 +2,56 @@ package stduserservice
 
 import (
 	"context"
-	"fmt"
+	"errors"
+	"log/slog"
 	"time"
 
-	commonerr "waizlytest/common/errors"
-
+	commonmail "waizlytest/common/mail"
 	"waizlytest/repositories"
 	userservice "waizlytest/services/user"
 )
 
 func (s *StdService) Register(ctx context.Context, params userservice.RegisterRequest) (int64, error) {
-	usr, err := s.userReader.FindUserByPhone(ctx, params.Phone)
-	if err != nil {
-		return 0, err
+	// uniqueness check must not read from a lagging replica
+	ctx = repositories.WithPrimary(ctx)
+
+	if params.Phone == "" && params.Email == "" {
+		return 0, errIdentifierRequired()
 	}
 
-	if usr != nil {
-		e := commonerr.Conflicted("")
-		e.AddField("phone", fmt.Sprintf("[%s] already registered", params.Phone))
-		return 0, e
+	var phone, email string
+	var err error
+
+	if params.Phone != "" {
+		phone, err = s.phones.Normalize(params.Phone)
+		if err != nil {
+			return 0, errInvalidPhone()
+		}
+
+		usr, err := s.userReader.FindUserByPhone(ctx, phone)
+		if err != nil {
+			return 0, err
+		}
+
+		if usr != nil {
+			return 0, errPhoneRegistered(phone)
+		}
+	}
+
+	if params.Email != "" {
+		email, err = commonmail.NormalizeAddress(params.Email)
+		if err != nil {
+			return 0, errInvalidEmail()
+		}
+
+		usr, err := s.userReader.FindUserByEmail(ctx, email)
+		if err != nil {
+			return 0, err
+		}
+
+		if usr != nil {
+			return 0, errEmailRegistered(email)
+		}
 	}
 
 	hashed, err := hashed(params.Password)
@@ -29,11 +60,36 @@ func (s *StdService) Register(ctx context.Context, params userservice.RegisterRe
 	}
 
 	now := time.Now()
-	return s.userWriter.CreateUser(ctx, &repositories.User{
+	usr := &repositories.User{
 		FullName:  params.FullName,
 		Password:  hashed,
-		Phone:     params.Phone,
+		Phone:     phone,
+		Email:     email,
 		CreatedAt: now,
 		UpdatedAt: now,
-	})
+	}
+
+	id, err := s.userWriter.CreateUser(ctx, usr)
+	if err != nil {
+		// lost the race against concurrent registration of the same phone
+		if errors.Is(err, repositories.ErrDuplicate) {
+			return 0, errPhoneRegistered(phone)
+		}
+
+		return 0, err
+	}
+
+	s.logger.InfoContext(ctx, "user registered", slog.Int64("user_id", id))
+
+	if email != "" {
+		usr.ID = id
+
+		// user is registered anyway, the link can be sent again later
+		err = s.sendEmailVerification(ctx, usr)
+		if err != nil {
+			s.logger.ErrorContext(ctx, "failed send email verification", slog.Int64("user_id", id), slog.Any("error", err))
+		}
+	}
+
+	return id, nil
 }
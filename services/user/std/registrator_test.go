@@ -0,0 +1,150 @@
+package stduserservice_test
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/stretchr/testify/mock"
+
+	commonerr "waizlytest/common/errors"
+
+	"waizlytest/repositories"
+	mockrepositories "waizlytest/repositories/mock"
+
+	userservice "waizlytest/services/user"
+	stduserservice "waizlytest/services/user/std"
+)
+
+func TestRegistrator_Register(t *testing.T) {
+	type scenario struct {
+		name     string
+		req      userservice.RegisterRequest
+		expected func() (int64, error)
+
+		// storage is nil when no repository call is expected
+		storage func() *mockrepositories.MockUserRepository
+	}
+
+	normalized := mock.MatchedBy(func(u *repositories.User) bool {
+		return u.Phone == "+6281298765432"
+	})
+
+	scenarios := []scenario{
+		{
+			name: "[OK] Local phone",
+			req:  userservice.RegisterRequest{FullName: "Teest", Password: "123123", Phone: "081298765432"},
+
+			expected: func() (int64, error) {
+				return 1, nil
+			},
+
+			storage: func() *mockrepositories.MockUserRepository {
+				usrStorage := &mockrepositories.MockUserRepository{}
+
+				var emptyUsr *repositories.User
+				usrStorage.On("FindUserByPhone", mock.Anything, "+6281298765432").Return(emptyUsr, nil)
+				usrStorage.On("CreateUser", mock.Anything, normalized).Return(int64(1), nil)
+
+				return usrStorage
+			},
+		},
+		{
+			name: "[OK] International phone",
+			req:  userservice.RegisterRequest{FullName: "Teest", Password: "123123", Phone: "+62 812-9876-5432"},
+
+			expected: func() (int64, error) {
+				return 1, nil
+			},
+
+			storage: func() *mockrepositories.MockUserRepository {
+				usrStorage := &mockrepositories.MockUserRepository{}
+
+				var emptyUsr *repositories.User
+				usrStorage.On("FindUserByPhone", mock.Anything, "+6281298765432").Return(emptyUsr, nil)
+				usrStorage.On("CreateUser", mock.Anything, normalized).Return(int64(1), nil)
+
+				return usrStorage
+			},
+		},
+		{
+			name: "[Failed] Invalid phone",
+			req:  userservice.RegisterRequest{FullName: "Teest", Password: "123123", Phone: "0812abc"},
+
+			expected: func() (int64, error) {
+				e := commonerr.DefInvalidRequest.New()
+				e.AddField("phone", "must be a valid phone number")
+
+				return 0, e
+			},
+		},
+		{
+			name: "[Failed] Phone registered",
+			req:  userservice.RegisterRequest{FullName: "Teest", Password: "123123", Phone: "081298765432"},
+
+			expected: func() (int64, error) {
+				e := userservice.ErrPhoneRegistered.New()
+				e.AddField("phone", fmt.Sprintf("[%s] already registered", "+6281298765432"))
+
+				return 0, e
+			},
+
+			storage: func() *mockrepositories.MockUserRepository {
+				usrStorage := &mockrepositories.MockUserRepository{}
+
+				usrStorage.On("FindUserByPhone", mock.Anything, "+6281298765432").Return(&repositories.User{ID: 2}, nil)
+
+				return usrStorage
+			},
+		},
+		{
+			name: "[Failed] Phone registered concurrently",
+			req:  userservice.RegisterRequest{FullName: "Teest", Password: "123123", Phone: "+6281298765432"},
+
+			expected: func() (int64, error) {
+				e := userservice.ErrPhoneRegistered.New()
+				e.AddField("phone", fmt.Sprintf("[%s] already registered", "+6281298765432"))
+
+				return 0, e
+			},
+
+			storage: func() *mockrepositories.MockUserRepository {
+				usrStorage := &mockrepositories.MockUserRepository{}
+
+				var emptyUsr *repositories.User
+				usrStorage.On("FindUserByPhone", mock.Anything, "+6281298765432").Return(emptyUsr, nil)
+				usrStorage.On("CreateUser", mock.Anything, normalized).Return(int64(0), errDuplicate)
+
+				return usrStorage
+			},
+		},
+	}
+
+	for _, scn := range scenarios {
+		t.Run(scn.name, func(t *testing.T) {
+			expectedID, expectedError := scn.expected()
+
+			// repository without expectations fails on any call
+			usrStorage := &mockrepositories.MockUserRepository{}
+			if scn.storage != nil {
+				usrStorage = scn.storage()
+			}
+
+			svc := stduserservice.NewService(usrStorage, usrStorage, newPhones(t), stduserservice.EmailVerification{}, slog.Default())
+
+			id, err := svc.Register(context.TODO(), scn.req)
+			diff := cmp.Diff(expectedError, err, ignoreTranslation)
+			if diff != "" {
+				t.Errorf("err mismatch (-want +got):\n%s", diff)
+			}
+
+			if id != expectedID {
+				t.Errorf("id mismatch: want %d, got %d", expectedID, id)
+			}
+
+			usrStorage.AssertExpectations(t)
+		})
+	}
+}
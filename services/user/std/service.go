@@ -1,24 +1,52 @@
 package stduserservice
 
 import (
+	"log/slog"
+	"time"
+
+	commonmail "waizlytest/common/mail"
+	commonphone "waizlytest/common/phone"
+	commonsignedlink "waizlytest/common/signedlink"
 	"waizlytest/repositories"
 	userservice "waizlytest/services/user"
 )
 
 var _ userservice.Registrator = (*StdService)(nil)
 var _ userservice.Me = (*StdService)(nil)
+var _ userservice.EmailVerifier = (*StdService)(nil)
+
+// EmailVerification configures links sent to prove the user owns the email
+type EmailVerification struct {
+	Mailer commonmail.Mailer
+	Signer *commonsignedlink.Signer
+	// URL of the verification endpoint, token is added as `token` query
+	URL string
+	// TTL is only told to the user, Signer enforces it
+	TTL time.Duration
+}
 
 type StdService struct {
 	userReader repositories.UserReader
 	userWriter repositories.UserWriter
+
+	phones       *commonphone.Normalizer
+	verification EmailVerification
+
+	logger *slog.Logger
 }
 
 func NewService(
 	userWriter repositories.UserWriter,
 	userReader repositories.UserReader,
+	phones *commonphone.Normalizer,
+	verification EmailVerification,
+	logger *slog.Logger,
 ) *StdService {
 	return &StdService{
-		userReader: userReader,
-		userWriter: userWriter,
+		userReader:   userReader,
+		userWriter:   userWriter,
+		phones:       phones,
+		verification: verification,
+		logger:       logger,
 	}
 }
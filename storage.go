@@ -0,0 +1,241 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"expvar"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+
+	"github.com/jackc/pgx/v5/stdlib"
+	_ "modernc.org/sqlite"
+
+	"waizlytest/config"
+	"waizlytest/migration"
+
+	"waizlytest/repositories"
+	pgrepositories "waizlytest/repositories/pg"
+	promrepositories "waizlytest/repositories/prom"
+	sqliterepositories "waizlytest/repositories/sqlite"
+)
+
+// storage holds repositories of the configured DB driver along with its handles.
+type storage struct {
+	userWriter repositories.UserWriter
+	userReader repositories.UserReader
+
+	challengeWriter repositories.LoginChallengeWriter
+	challengeReader repositories.LoginChallengeReader
+
+	passkeyWriter repositories.PasskeyWriter
+	passkeyReader repositories.PasskeyReader
+
+	sessionWriter repositories.SessionWriter
+	sessionReader repositories.SessionReader
+
+	apiKeyWriter repositories.APIKeyWriter
+	apiKeyReader repositories.APIKeyReader
+
+	ping func(ctx context.Context) error
+
+	// sqlDB & dialect are used to run migrations
+	sqlDB   *sql.DB
+	dialect migration.Dialect
+
+	// collectors expose pool stats
+	collectors []prometheus.Collector
+
+	// workers should run in background for as long as storage is used
+	workers []func(ctx context.Context)
+	closers []func()
+}
+
+func (s *storage) Close() {
+	for i := len(s.closers) - 1; i >= 0; i-- {
+		s.closers[i]()
+	}
+}
+
+func openStorage(ctx context.Context, cfg config.DBConfig) (*storage, error) {
+	switch cfg.Driver {
+	case "", config.DriverPostgres:
+		if cfg.PgxPool {
+			return openPgxPoolStorage(ctx, cfg)
+		}
+
+		return openPgStorage(ctx, cfg)
+	case config.DriverSQLite:
+		if len(cfg.ReplicaDSNs) > 0 {
+			return nil, fmt.Errorf("DB replicas are only supported by postgres driver")
+		}
+
+		return openSQLiteStorage(ctx, cfg)
+	default:
+		return nil, fmt.Errorf("unsupported DB driver: %s", cfg.Driver)
+	}
+}
+
+func openSQL(driverName, dsn string, cfg config.DBConfig) (*sql.DB, error) {
+	db, err := sql.Open(driverName, dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	db.SetMaxOpenConns(cfg.MaxConn)
+	db.SetMaxIdleConns(cfg.MaxIdleConn)
+	return db, nil
+}
+
+func openSQLiteStorage(ctx context.Context, cfg config.DBConfig) (*storage, error) {
+	db, err := openSQL("sqlite", cfg.DSN, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	expvar.Publish("db_pool", expvar.Func(func() any { return db.Stats() }))
+
+	userStorage := sqliterepositories.NewUserRepository(db)
+	challengeStorage := sqliterepositories.NewLoginChallengeRepository(db)
+	passkeyStorage := sqliterepositories.NewPasskeyRepository(db)
+	sessionStorage := sqliterepositories.NewSessionRepository(db)
+	apiKeyStorage := sqliterepositories.NewAPIKeyRepository(db)
+	return &storage{
+		userWriter:      userStorage,
+		userReader:      userStorage,
+		challengeWriter: challengeStorage,
+		challengeReader: challengeStorage,
+		passkeyWriter:   passkeyStorage,
+		passkeyReader:   passkeyStorage,
+		sessionWriter:   sessionStorage,
+		sessionReader:   sessionStorage,
+		apiKeyWriter:    apiKeyStorage,
+		apiKeyReader:    apiKeyStorage,
+		ping:            db.PingContext,
+		sqlDB:           db,
+		dialect:         migration.DialectSQLite,
+		collectors:      []prometheus.Collector{collectors.NewDBStatsCollector(db, "primary")},
+		closers:         []func(){func() { db.Close() }},
+	}, nil
+}
+
+func openPgStorage(ctx context.Context, cfg config.DBConfig) (*storage, error) {
+	st := &storage{}
+
+	db, err := openSQL("pgx", cfg.DSN, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	st.closers = append(st.closers, func() { db.Close() })
+	st.collectors = append(st.collectors, collectors.NewDBStatsCollector(db, "primary"))
+	expvar.Publish("db_pool", expvar.Func(func() any { return db.Stats() }))
+
+	replicas := make([]*sql.DB, 0, len(cfg.ReplicaDSNs))
+	for i, dsn := range cfg.ReplicaDSNs {
+		replica, err := openSQL("pgx", dsn, cfg)
+		if err != nil {
+			st.Close()
+			return nil, fmt.Errorf("failed open DB replica: %w", err)
+		}
+
+		st.closers = append(st.closers, func() { replica.Close() })
+		st.collectors = append(st.collectors, collectors.NewDBStatsCollector(replica, fmt.Sprintf("replica_%d", i)))
+		replicas = append(replicas, replica)
+	}
+
+	pgDB := pgrepositories.NewDB(db, replicas...)
+	st.workers = append(st.workers, func(ctx context.Context) {
+		pgDB.HealthCheck(ctx, time.Second*time.Duration(cfg.ReplicaHealthCheckInterval))
+	})
+
+	userStorage := pgrepositories.NewUserRepository(pgDB)
+	st.userWriter, st.userReader = userStorage, userStorage
+
+	challengeStorage := pgrepositories.NewLoginChallengeRepository(pgDB)
+	st.challengeWriter, st.challengeReader = challengeStorage, challengeStorage
+
+	passkeyStorage := pgrepositories.NewPasskeyRepository(pgDB)
+	st.passkeyWriter, st.passkeyReader = passkeyStorage, passkeyStorage
+
+	sessionStorage := pgrepositories.NewSessionRepository(pgDB)
+	st.sessionWriter, st.sessionReader = sessionStorage, sessionStorage
+
+	apiKeyStorage := pgrepositories.NewAPIKeyRepository(pgDB)
+	st.apiKeyWriter, st.apiKeyReader = apiKeyStorage, apiKeyStorage
+
+	st.ping = pgDB.Ping
+	st.sqlDB = db
+	st.dialect = migration.DialectPostgres
+	return st, nil
+}
+
+func openPgxPoolStorage(ctx context.Context, cfg config.DBConfig) (*storage, error) {
+	st := &storage{}
+
+	tracer := pgrepositories.NewQueryTracer()
+	expvar.Publish("db_queries", expvar.Func(func() any { return tracer.Stats() }))
+
+	pcfg := pgrepositories.PoolConfig{
+		MaxConns:               int32(cfg.MaxConn),
+		StatementCacheCapacity: cfg.StatementCacheCapacity,
+		Tracer:                 tracer,
+	}
+
+	pool, err := pgrepositories.NewPool(ctx, cfg.DSN, pcfg)
+	if err != nil {
+		return nil, err
+	}
+
+	st.closers = append(st.closers, pool.Close)
+	st.collectors = append(st.collectors, promrepositories.NewPoolCollector("primary", func() pgrepositories.PoolStat {
+		return pgrepositories.PoolStats(pool)
+	}))
+	expvar.Publish("db_pool", expvar.Func(func() any { return pgrepositories.PoolStats(pool) }))
+
+	replicas := make([]*pgxpool.Pool, 0, len(cfg.ReplicaDSNs))
+	for i, dsn := range cfg.ReplicaDSNs {
+		replica, err := pgrepositories.NewPool(ctx, dsn, pcfg)
+		if err != nil {
+			st.Close()
+			return nil, fmt.Errorf("failed open DB replica: %w", err)
+		}
+
+		st.closers = append(st.closers, replica.Close)
+		st.collectors = append(st.collectors, promrepositories.NewPoolCollector(fmt.Sprintf("replica_%d", i), func() pgrepositories.PoolStat {
+			return pgrepositories.PoolStats(replica)
+		}))
+		expvar.Publish(fmt.Sprintf("db_replica_pool_%d", i), expvar.Func(func() any { return pgrepositories.PoolStats(replica) }))
+		replicas = append(replicas, replica)
+	}
+
+	pgDB := pgrepositories.NewPoolDB(pool, replicas...)
+	st.workers = append(st.workers, func(ctx context.Context) {
+		pgDB.HealthCheck(ctx, time.Second*time.Duration(cfg.ReplicaHealthCheckInterval))
+	})
+
+	userStorage := pgrepositories.NewUserRepository(pgDB)
+	st.userWriter, st.userReader = userStorage, userStorage
+
+	challengeStorage := pgrepositories.NewLoginChallengeRepository(pgDB)
+	st.challengeWriter, st.challengeReader = challengeStorage, challengeStorage
+
+	passkeyStorage := pgrepositories.NewPasskeyRepository(pgDB)
+	st.passkeyWriter, st.passkeyReader = passkeyStorage, passkeyStorage
+
+	sessionStorage := pgrepositories.NewSessionRepository(pgDB)
+	st.sessionWriter, st.sessionReader = sessionStorage, sessionStorage
+
+	apiKeyStorage := pgrepositories.NewAPIKeyRepository(pgDB)
+	st.apiKeyWriter, st.apiKeyReader = apiKeyStorage, apiKeyStorage
+
+	st.ping = pgDB.Ping
+	st.sqlDB = stdlib.OpenDBFromPool(pool)
+	// closers run in reverse, so it is released before the pool it borrows from
+	st.closers = append(st.closers, func() { st.sqlDB.Close() })
+	st.dialect = migration.DialectPostgres
+	return st, nil
+}